@@ -1,6 +1,7 @@
 package main
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/routes"
 	"api/internal/types"
@@ -26,14 +27,26 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func main() {
+// newRouter creates the gin engine, only trusting the client IP forwarded by the given proxies
+func newRouter(trustedProxies []string) (*gin.Engine, error) {
 	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
 
+func main() {
 	apiConfig, err := config.GetAPIConfig()
 	if err != nil {
 		log.Fatalf("Error getting API config: %v", err)
 	}
 
+	r, err := newRouter(apiConfig.TRUSTED_PROXIES)
+	if err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// If we're on a Codespace add that
 	if os.Getenv("CODESPACES") == "true" {
 		// Lets run command to set the port visibility to public
@@ -80,6 +93,17 @@ func main() {
 		log.Fatalf("Failed to seed plans: %v", err)
 	}
 
+	if err := mongoService.EnsureEventIndexes(context.TODO()); err != nil {
+		log.Fatalf("Failed to create event indexes: %v", err)
+	}
+
+	if _, err := mongoService.MarkLegacyUsersEmailVerified(context.TODO()); err != nil {
+		log.Fatalf("Failed to backfill email verification: %v", err)
+	}
+
+	// Lambda functions don't run between requests, so the trash is also purged whenever one starts
+	helpers.PurgeTrash(context.TODO(), mongoService, apiConfig.TRASH_RETENTION)
+
 	producer, err := producer.NewMessageProducer()
 	if err != nil {
 		log.Fatalf("Failed to create Kafka producer: %v", err)
@@ -115,6 +139,10 @@ func main() {
 			Handler: r,
 		}
 
+		purgeCtx, stopPurging := context.WithCancel(context.Background())
+		defer stopPurging()
+		go helpers.PurgeTrashPeriodically(purgeCtx, mongoService, apiConfig.TRASH_RETENTION)
+
 		go func() {
 			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 				log.Fatalf("listen: %s\n", err)
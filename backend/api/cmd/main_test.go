@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouterClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   string
+		expectedIP     string
+	}{
+		{"Spoofed X-Forwarded-For is ignored by default", nil, "203.0.113.7:1234", "198.51.100.1", "203.0.113.7"},
+		{"Untrusted proxy's X-Forwarded-For is ignored", []string{"10.0.0.0/8"}, "203.0.113.7:1234", "198.51.100.1", "203.0.113.7"},
+		{"Trusted proxy's X-Forwarded-For is used", []string{"10.0.0.0/8"}, "10.0.0.2:1234", "198.51.100.1", "198.51.100.1"},
+		{"No X-Forwarded-For", []string{"10.0.0.0/8"}, "10.0.0.2:1234", "", "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newRouter(tt.trustedProxies)
+			assert.NoError(t, err)
+			r.GET("/ip", func(c *gin.Context) {
+				c.String(http.StatusOK, c.ClientIP())
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedIP, w.Body.String())
+		})
+	}
+
+	t.Run("Invalid proxy", func(t *testing.T) {
+		_, err := newRouter([]string{"not an ip"})
+		assert.Error(t, err)
+	})
+}
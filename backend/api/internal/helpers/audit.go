@@ -0,0 +1,182 @@
+package helpers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Audit records a change the authenticated user just made. The change has already happened by the time this is called,
+// so a failure to write the entry is logged rather than failing the request.
+func Audit(c *gin.Context, m mongodb.MongoService, entry models.AuditEntry) {
+	if _, err := m.CreateAuditEntry(c, withRequestDetails(c, entry)); err != nil {
+		logger.Error("Failed to write audit log entry for "+string(entry.Action), err)
+	}
+}
+
+// AuditImpersonatedRequest records a request support is making as the user they're impersonating.
+// Unlike Audit this is called before the request is authenticated, so it can be refused if the entry can't be written.
+func AuditImpersonatedRequest(c *gin.Context, m mongodb.MongoService, userID, impersonatorID primitive.ObjectID) error {
+	entry := withRequestDetails(c, models.AuditEntry{
+		Action:     models.AuditImpersonatedRequest,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.Hex(),
+		Request:    c.Request.Method + " " + c.Request.URL.Path,
+	})
+	entry.ActorID = userID
+	entry.ImpersonatedByID = impersonatorID
+
+	_, err := m.CreateAuditEntry(c, entry)
+	return err
+}
+
+// withRequestDetails fills in who made the request and where from
+func withRequestDetails(c *gin.Context, entry models.AuditEntry) models.AuditEntry {
+	if user, ok := utils.GetUserFromContext(c, false); ok {
+		entry.ActorID = user.ID
+	}
+	if apiKey, ok := utils.GetAPIKeyFromContext(c); ok {
+		entry.APIKeyID = apiKey.ID
+	}
+	if impersonatorID, ok := utils.GetImpersonatorIDFromContext(c); ok {
+		entry.ImpersonatedByID = impersonatorID
+	}
+	entry.IP = c.ClientIP()
+	entry.UserAgent = c.Request.UserAgent()
+	entry.CreatedAt = time.Now()
+	return entry
+}
+
+// auditIgnoredFields are already recorded on the entry itself
+var auditIgnoredFields = []string{"id", "eventID"}
+
+// AuditDiff returns the top level fields that differ between before and after, as they'd be shown by the API.
+// Either can be nil for something that was just created or deleted. Fields hidden from JSON are never included.
+func AuditDiff(before, after interface{}) map[string]models.AuditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+	for _, key := range auditIgnoredFields {
+		delete(beforeFields, key)
+		delete(afterFields, key)
+	}
+
+	diff := make(map[string]models.AuditChange)
+	for key, value := range beforeFields {
+		if !reflect.DeepEqual(value, afterFields[key]) {
+			diff[key] = models.AuditChange{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, exists := beforeFields[key]; !exists {
+			diff[key] = models.AuditChange{After: value}
+		}
+	}
+	return diff
+}
+
+// AuditRedactedDiff is AuditDiff with the values replaced, for when the log should only say which fields changed
+func AuditRedactedDiff(before, after interface{}) map[string]models.AuditChange {
+	diff := AuditDiff(before, after)
+	for key, change := range diff {
+		redacted := models.AuditChange{}
+		if change.Before != nil {
+			redacted.Before = models.AuditRedacted
+		}
+		if change.After != nil {
+			redacted.After = models.AuditRedacted
+		}
+		diff[key] = redacted
+	}
+	return diff
+}
+
+func auditFields(value interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		logger.Error("Failed to marshal value for audit log", err)
+		return fields
+	}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		logger.Error("Failed to unmarshal value for audit log", err)
+	}
+	return fields
+}
+
+// AuditLogQuery is the page of the audit log a request asked for
+type AuditLogQuery struct {
+	Filter   bson.M
+	Options  *options.FindOptions
+	Page     int
+	PageSize int
+}
+
+// ParseAuditLogQuery adds the action, targetType, targetID, actorID and RFC3339 from/to filters in the query string
+// to filter and reads the page, newest entries first. The response is written if the query string is invalid.
+func ParseAuditLogQuery(c *gin.Context, filter bson.M) (*AuditLogQuery, bool) {
+	for _, key := range []string{"action", "targetType", "targetID"} {
+		if value := c.Query(key); value != "" {
+			filter[key] = value
+		}
+	}
+
+	if actorID := c.Query("actorID"); actorID != "" {
+		objID, err := primitive.ObjectIDFromHex(actorID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid actor ID"})
+			return nil, false
+		}
+		filter["actorID"] = objID
+	}
+
+	createdAt := bson.M{}
+	for param, operator := range map[string]string{"from": "$gte", "to": "$lte"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " time, it must be RFC3339"})
+			return nil, false
+		}
+		createdAt[operator] = t
+	}
+	if len(createdAt) > 0 {
+		filter["createdAt"] = createdAt
+	}
+
+	// Pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+
+	// Validate page and pageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	opts := options.Find().
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+
+	return &AuditLogQuery{Filter: filter, Options: opts, Page: page, PageSize: pageSize}, true
+}
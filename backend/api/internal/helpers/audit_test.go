@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"net/http/httptest"
+	"shared/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type auditedThing struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Limit  int    `json:"limit,omitempty"`
+	Secret string `json:"-"`
+}
+
+func TestAuditDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   interface{}
+		after    interface{}
+		expected map[string]models.AuditChange
+	}{
+		{
+			"Changed",
+			auditedThing{ID: "1", Name: "Old", Limit: 5},
+			auditedThing{ID: "1", Name: "New", Limit: 5},
+			map[string]models.AuditChange{"name": {Before: "Old", After: "New"}},
+		},
+		{
+			"Unchanged",
+			auditedThing{ID: "1", Name: "Same"},
+			auditedThing{ID: "1", Name: "Same"},
+			map[string]models.AuditChange{},
+		},
+		{
+			"Created",
+			nil,
+			auditedThing{ID: "1", Name: "New"},
+			map[string]models.AuditChange{"name": {After: "New"}},
+		},
+		{
+			"Deleted",
+			&auditedThing{ID: "1", Name: "Old", Limit: 5},
+			(*auditedThing)(nil),
+			map[string]models.AuditChange{"name": {Before: "Old"}, "limit": {Before: float64(5)}},
+		},
+		{
+			"Field left out",
+			auditedThing{Name: "Same", Limit: 5},
+			auditedThing{Name: "Same"},
+			map[string]models.AuditChange{"limit": {Before: float64(5)}},
+		},
+		{
+			"Hidden fields aren't included",
+			auditedThing{Name: "Same", Secret: "old"},
+			auditedThing{Name: "Same", Secret: "new"},
+			map[string]models.AuditChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AuditDiff(tt.before, tt.after))
+		})
+	}
+}
+
+func TestAuditRedactedDiff(t *testing.T) {
+	diff := AuditRedactedDiff(
+		map[string]string{"password": "old", "unchanged": "same", "removed": "gone"},
+		map[string]string{"password": "new", "unchanged": "same", "token": "added"},
+	)
+
+	assert.Equal(t, map[string]models.AuditChange{
+		"password": {Before: models.AuditRedacted, After: models.AuditRedacted},
+		"token":    {After: models.AuditRedacted},
+		"removed":  {Before: models.AuditRedacted},
+	}, diff)
+}
+
+func TestWithRequestDetails(t *testing.T) {
+	user := &models.User{ID: primitive.NewObjectID()}
+	apiKey := &models.APIKey{ID: primitive.NewObjectID()}
+	impersonatorID := primitive.NewObjectID()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/events", nil)
+	c.Request.RemoteAddr = "203.0.113.7:1234"
+	c.Request.Header.Set("User-Agent", "test-agent")
+	c.Set("user", user)
+	c.Set("apiKey", apiKey)
+	c.Set("impersonatedByID", impersonatorID)
+
+	entry := withRequestDetails(c, models.AuditEntry{Action: models.AuditEventUpdate})
+	assert.Equal(t, models.AuditEventUpdate, entry.Action)
+	assert.Equal(t, user.ID, entry.ActorID)
+	assert.Equal(t, apiKey.ID, entry.APIKeyID)
+	assert.Equal(t, impersonatorID, entry.ImpersonatedByID)
+	assert.Equal(t, "203.0.113.7", entry.IP)
+	assert.Equal(t, "test-agent", entry.UserAgent)
+	assert.False(t, entry.CreatedAt.IsZero())
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"shared/models"
+	"shared/mongodb"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateDefaultSubscription puts a user or an organization on the default free plan, only one of the IDs should be set
+func CreateDefaultSubscription(c context.Context, m mongodb.MongoService, userID primitive.ObjectID, organizationID primitive.ObjectID) (primitive.ObjectID, error) {
+	listPlans, err := m.ListPlans(c, bson.M{"default": true})
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("failed to retrieve plans: %w", err)
+	}
+	if len(listPlans) == 0 {
+		return primitive.NilObjectID, errors.New("no default plan configured")
+	}
+
+	newSubscription := models.Subscription{
+		PlanID:         listPlans[0].ID,
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Status:         models.SubscriptionStatusActive,
+		StartDate:      time.Now(),
+		EndDate:        time.Now().AddDate(0, 1, 0),
+		Limits:         listPlans[0].Limits,
+		Utilization: models.Utilization{
+			EventsCreated: 0,
+			Responses:     0,
+			PipelineRuns:  0,
+		},
+		BillingCycle:             "monthly",
+		NextUtilizationResetDate: time.Now().AddDate(0, 1, 0),
+	}
+
+	subscriptionId, err := m.CreateNewSubscription(c, newSubscription)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	return subscriptionId.InsertedID.(primitive.ObjectID), nil
+}
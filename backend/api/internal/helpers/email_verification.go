@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"time"
+)
+
+const (
+	emailVerificationTokenBytes = 32
+	emailVerificationTokenTTL   = 48 * time.Hour
+)
+
+// SendEmailVerification emails the user a single-use link to verify they own their current email address
+func SendEmailVerification(c context.Context, mongo mongodb.MongoService, user *models.User) error {
+	token, err := utils.GenerateSecureToken(emailVerificationTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	_, err = mongo.CreateUserToken(c, models.UserToken{
+		UserID:    user.ID,
+		Type:      models.UserTokenEmailVerification,
+		TokenHash: utils.HashToken(token),
+		Email:     user.Email,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(emailVerificationTokenTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	verifyLink := utils.WebsiteURL("/verify-email?token=" + url.QueryEscape(token))
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm that this is your email address by opening the link below. "+
+		"This link expires in %d hours.\n\n%s\n\n"+
+		"If you didn't create an ApplicantAtlas account or change your email to this address you can ignore this email.",
+		user.FirstName, int(emailVerificationTokenTTL.Hours()), verifyLink)
+
+	return utils.SendPlatformEmail(user.Email, "Verify your ApplicantAtlas email address", body)
+}
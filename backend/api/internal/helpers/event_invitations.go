@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	eventInvitationTokenBytes = 32
+	eventInvitationTTL        = 14 * 24 * time.Hour
+)
+
+// InviteEventOrganizer invites an email to organize an event with the organizer's role and emails them a link to accept it.
+// The organizer's UserID is ignored since the invitee might not have an account yet.
+func InviteEventOrganizer(c context.Context, m mongodb.MongoService, event *models.Event, inviter *models.User, email string, organizer models.EventOrganizer) (*models.EventInvitation, error) {
+	token, err := utils.GenerateSecureToken(eventInvitationTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := models.EventInvitation{
+		EventID:      event.ID,
+		Email:        strings.ToLower(email),
+		Role:         organizer.Role,
+		Capabilities: organizer.Capabilities,
+		InvitedByID:  inviter.ID,
+		TokenHash:    utils.HashToken(token),
+		Status:       models.EventInvitationPending,
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(eventInvitationTTL),
+	}
+
+	result, err := m.CreateEventInvitation(c, invitation)
+	if err != nil {
+		return nil, err
+	}
+	invitation.ID = result.InsertedID.(primitive.ObjectID)
+
+	acceptLink := utils.WebsiteURL("/invitations/accept?token=" + url.QueryEscape(token))
+	body := fmt.Sprintf("Hi,\n\n%s has invited you to help organize %s on ApplicantAtlas as a %s. "+
+		"Open the link below to accept, you'll be asked to log in or create an account with this email address. "+
+		"This invitation expires in %d days.\n\n%s\n\n"+
+		"If you weren't expecting this invitation you can ignore this email.",
+		inviter.Email, event.Metadata.Name, organizer.Role, int(eventInvitationTTL.Hours()/24), acceptLink)
+
+	if err := utils.SendPlatformEmail(invitation.Email, "You've been invited to organize "+event.Metadata.Name, body); err != nil {
+		return &invitation, err
+	}
+
+	return &invitation, nil
+}
+
+// AcceptEventInvitation makes the user an organizer of the invitation's event. Returns mongo.ErrNoDocuments if the
+// invitation can no longer be accepted, accepting an invitation for an event the user already organizes is a no-op.
+func AcceptEventInvitation(c context.Context, m mongodb.MongoService, invitationID primitive.ObjectID, userID primitive.ObjectID) (*models.EventInvitation, error) {
+	// Accepting claims the invitation so it can't be used twice, it's reopened if the user can't be added
+	invitation, err := m.AcceptEventInvitation(c, invitationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.AddOrganizerToEvent(c, invitation.EventID, invitation.Organizer(userID)); err != nil {
+		if _, reopenErr := m.ReopenEventInvitation(c, invitation.ID); reopenErr != nil {
+			logger.Error("Failed to reopen event invitation", reopenErr)
+		}
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// ClaimEventInvitations accepts every pending invitation sent to the user's email. Only verified emails are used
+// since anyone can register with an address they don't own.
+func ClaimEventInvitations(c context.Context, m mongodb.MongoService, user *models.User) {
+	if !user.EmailVerified || user.Email == "" {
+		return
+	}
+
+	invitations, err := m.ListPendingEventInvitationsByEmail(c, strings.ToLower(user.Email))
+	if err != nil {
+		logger.Error("Failed to list event invitations", err)
+		return
+	}
+
+	for _, invitation := range invitations {
+		if _, err := AcceptEventInvitation(c, m, invitation.ID, user.ID); err != nil && err != mongo.ErrNoDocuments {
+			logger.Error("Failed to accept event invitation", err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"shared/models"
+	"shared/mongodb"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockInvitationService keeps a single invitation's status and the organizers added to its event
+type mockInvitationService struct {
+	mongodb.MongoService
+	invitation   models.EventInvitation
+	addOrganizer error
+	added        []models.EventOrganizer
+}
+
+func (m *mockInvitationService) AcceptEventInvitation(ctx context.Context, invitationID primitive.ObjectID, userID primitive.ObjectID) (*models.EventInvitation, error) {
+	if m.invitation.ID != invitationID || m.invitation.Status != models.EventInvitationPending {
+		return nil, mongo.ErrNoDocuments
+	}
+	m.invitation.Status = models.EventInvitationAccepted
+	m.invitation.AcceptedByID = userID
+	invitation := m.invitation
+	return &invitation, nil
+}
+
+func (m *mockInvitationService) ReopenEventInvitation(ctx context.Context, invitationID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	if m.invitation.ID != invitationID || m.invitation.Status != models.EventInvitationAccepted {
+		return &mongo.UpdateResult{}, nil
+	}
+	m.invitation.Status = models.EventInvitationPending
+	m.invitation.AcceptedByID = primitive.NilObjectID
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (m *mockInvitationService) AddOrganizerToEvent(ctx context.Context, eventID primitive.ObjectID, organizer models.EventOrganizer) (*mongo.UpdateResult, error) {
+	if m.addOrganizer != nil {
+		return nil, m.addOrganizer
+	}
+	m.added = append(m.added, organizer)
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func TestAcceptEventInvitation(t *testing.T) {
+	userID := primitive.NewObjectID()
+	newInvitation := func() models.EventInvitation {
+		return models.EventInvitation{
+			ID:      primitive.NewObjectID(),
+			EventID: primitive.NewObjectID(),
+			Role:    models.EventRoleReviewer,
+			Status:  models.EventInvitationPending,
+		}
+	}
+
+	t.Run("Accepted", func(t *testing.T) {
+		m := &mockInvitationService{invitation: newInvitation()}
+		invitation, err := AcceptEventInvitation(context.Background(), m, m.invitation.ID, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.EventInvitationAccepted, invitation.Status)
+		if assert.Len(t, m.added, 1) {
+			assert.Equal(t, userID, m.added[0].UserID)
+			assert.Equal(t, models.EventRoleReviewer, m.added[0].Role)
+		}
+	})
+
+	t.Run("Reopened when the organizer can't be added", func(t *testing.T) {
+		m := &mockInvitationService{invitation: newInvitation(), addOrganizer: errors.New("write failed")}
+		_, err := AcceptEventInvitation(context.Background(), m, m.invitation.ID, userID)
+
+		assert.Error(t, err)
+		assert.Equal(t, models.EventInvitationPending, m.invitation.Status)
+		assert.True(t, m.invitation.AcceptedByID.IsZero())
+
+		// It can be accepted again once the event can be written to
+		m.addOrganizer = nil
+		_, err = AcceptEventInvitation(context.Background(), m, m.invitation.ID, userID)
+		assert.NoError(t, err)
+		assert.Len(t, m.added, 1)
+	})
+
+	t.Run("Already accepted", func(t *testing.T) {
+		invitation := newInvitation()
+		invitation.Status = models.EventInvitationAccepted
+		m := &mockInvitationService{invitation: invitation}
+		_, err := AcceptEventInvitation(context.Background(), m, m.invitation.ID, userID)
+
+		assert.Equal(t, mongo.ErrNoDocuments, err)
+		assert.Empty(t, m.added)
+	})
+}
@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"shared/logger"
+	"shared/mongodb"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IsEventParticipant checks if the authenticated user has been accepted to the event, meaning they can access or
+// have submitted its participant form. Events without a participant form have no participants.
+func IsEventParticipant(c *gin.Context, m mongodb.MongoService, eventID primitive.ObjectID) bool {
+	user, ok := utils.GetUserFromContext(c, false)
+	if !ok {
+		return false
+	}
+
+	settings, err := m.GetEventSettings(c, eventID)
+	if err != nil || settings.ParticipantFormID.IsZero() {
+		return false
+	}
+
+	form, err := m.GetForm(c, settings.ParticipantFormID, false)
+	if err != nil || form.EventID != eventID {
+		return false
+	}
+
+	// Their access may have expired since they submitted it
+	responses, err := m.ListResponses(c, bson.M{"formID": form.ID, "userID": user.ID}, options.Find().SetLimit(1))
+	if err != nil {
+		logger.Error("Failed to check participant form responses", err)
+		return false
+	}
+	if len(responses) > 0 {
+		return true
+	}
+
+	allowed, _ := mongodb.IsUserEmailInWhitelist(c, m, form.AllowedSubmitters)
+	return allowed
+}
@@ -0,0 +1,139 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// loginThrottlePolicy decides how long logins have to wait after repeated failures
+type loginThrottlePolicy struct {
+	freeFailures    int           // Failures allowed before logins are slowed down
+	lockoutFailures int           // Failures that lock logins out entirely
+	maxDelay        time.Duration // The delay doubles with each failure up to this
+	lockout         time.Duration
+}
+
+// loginFailureWindow is how long a failure counts for, unless logins are locked
+const loginFailureWindow = time.Hour
+
+// Shared networks can send a lot of logins from one IP so it gets more room than a single account
+var loginThrottlePolicies = map[models.LoginThrottleKind]loginThrottlePolicy{
+	models.LoginThrottleAccount: {freeFailures: 3, lockoutFailures: 10, maxDelay: 30 * time.Second, lockout: 15 * time.Minute},
+	models.LoginThrottleIP:      {freeFailures: 20, lockoutFailures: 100, maxDelay: 30 * time.Second, lockout: 15 * time.Minute},
+}
+
+// retryAfter returns how long until another login can be tried
+func (p loginThrottlePolicy) retryAfter(throttle *models.LoginThrottle, now time.Time) time.Duration {
+	if throttle.IsLocked(now) {
+		return throttle.LockedUntil.Sub(now)
+	}
+
+	extraFailures := throttle.Failures - p.freeFailures
+	if extraFailures <= 0 {
+		return 0
+	}
+
+	delay := p.maxDelay
+	if extraFailures < 16 {
+		delay = time.Second << (extraFailures - 1)
+		if delay > p.maxDelay {
+			delay = p.maxDelay
+		}
+	}
+
+	wait := throttle.LastFailureAt.Add(delay).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// loginThrottleKeys returns what failures are counted under. The IP is left out when it's unknown, otherwise every
+// request without one would share a single throttle.
+func loginThrottleKeys(email, ip string) map[models.LoginThrottleKind]string {
+	keys := map[models.LoginThrottleKind]string{
+		models.LoginThrottleAccount: NormalizeLoginEmail(email),
+	}
+	if ip != "" {
+		keys[models.LoginThrottleIP] = ip
+	}
+	return keys
+}
+
+// NormalizeLoginEmail returns the key failed logins for an email are counted under
+func NormalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// LoginRetryAfter returns how long the email and IP address have to wait before trying to log in again, 0 if they can try now
+func LoginRetryAfter(c context.Context, m mongodb.MongoService, email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for kind, key := range loginThrottleKeys(email, ip) {
+		throttle, err := m.GetLoginThrottle(c, kind, key)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return 0, err
+		}
+
+		if w := loginThrottlePolicies[kind].retryAfter(throttle, now); w > wait {
+			wait = w
+		}
+	}
+	return wait, nil
+}
+
+// RecordFailedLogin counts a failed login against the email and IP address, locking them out once there have been too many.
+// user is the account the email belongs to, or nil if there isn't one, and is emailed when their account gets locked.
+func RecordFailedLogin(c context.Context, m mongodb.MongoService, user *models.User, email, ip string) error {
+	for kind, key := range loginThrottleKeys(email, ip) {
+		policy := loginThrottlePolicies[kind]
+		throttle, err := m.RecordFailedLogin(c, kind, key, loginFailureWindow)
+		if err != nil {
+			return err
+		}
+
+		if throttle.Failures < policy.lockoutFailures {
+			continue
+		}
+
+		lockedUntil := time.Now().Add(policy.lockout)
+		result, err := m.LockLogins(c, kind, key, lockedUntil)
+		if err != nil {
+			return err
+		}
+
+		// Only the request that started the lockout sends the email
+		if kind == models.LoginThrottleAccount && user != nil && result.ModifiedCount > 0 {
+			if err := sendLoginLockoutEmail(user, throttle.Failures, policy.lockout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClearAccountLoginFailures forgets the failed logins for an email, call it once the account owner has logged in
+func ClearAccountLoginFailures(c context.Context, m mongodb.MongoService, email string) error {
+	_, err := m.ClearLoginThrottle(c, models.LoginThrottleAccount, NormalizeLoginEmail(email))
+	return err
+}
+
+func sendLoginLockoutEmail(user *models.User, failures int, lockout time.Duration) error {
+	resetLink := utils.WebsiteURL("/forgot-password")
+	body := fmt.Sprintf("Hi %s,\n\nThere have been %d failed attempts to log in to your ApplicantAtlas account, "+
+		"so logging in with your password has been locked for %d minutes.\n\n"+
+		"If this wasn't you, someone may be trying to guess your password. We recommend choosing a new one and turning on two-factor authentication:\n\n%s",
+		user.FirstName, failures, int(lockout.Minutes()), resetLink)
+
+	return utils.SendPlatformEmail(user.Email, "Your ApplicantAtlas account has been temporarily locked", body)
+}
@@ -0,0 +1,48 @@
+package helpers
+
+import (
+	"shared/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginThrottleRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	policy := loginThrottlePolicies[models.LoginThrottleAccount]
+
+	tests := []struct {
+		name     string
+		throttle models.LoginThrottle
+		expected time.Duration
+	}{
+		{"Free failures", models.LoginThrottle{Failures: 3, LastFailureAt: now}, 0},
+		{"First slowed failure", models.LoginThrottle{Failures: 4, LastFailureAt: now}, time.Second},
+		{"Delay doubles", models.LoginThrottle{Failures: 6, LastFailureAt: now}, 4 * time.Second},
+		{"Delay is capped", models.LoginThrottle{Failures: 9, LastFailureAt: now}, 30 * time.Second},
+		{"Capped without overflowing", models.LoginThrottle{Failures: 80, LastFailureAt: now}, 30 * time.Second},
+		{"Delay already waited out", models.LoginThrottle{Failures: 6, LastFailureAt: now.Add(-time.Minute)}, 0},
+		{"Part of the delay waited", models.LoginThrottle{Failures: 6, LastFailureAt: now.Add(-time.Second)}, 3 * time.Second},
+		{"Locked", models.LoginThrottle{Failures: 10, LastFailureAt: now, LockedUntil: now.Add(10 * time.Minute)}, 10 * time.Minute},
+		{"Lock expired", models.LoginThrottle{Failures: 2, LastFailureAt: now.Add(-time.Hour), LockedUntil: now.Add(-time.Minute)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.retryAfter(&tt.throttle, now))
+		})
+	}
+}
+
+func TestLoginThrottleKeys(t *testing.T) {
+	assert.Equal(t, map[models.LoginThrottleKind]string{
+		models.LoginThrottleAccount: "user@example.com",
+		models.LoginThrottleIP:      "203.0.113.7",
+	}, loginThrottleKeys("  User@Example.com ", "203.0.113.7"))
+
+	// Requests without an IP would otherwise all share one throttle
+	assert.Equal(t, map[models.LoginThrottleKind]string{
+		models.LoginThrottleAccount: "user@example.com",
+	}, loginThrottleKeys("user@example.com", ""))
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"shared/logger"
+	"shared/mongodb"
+	"time"
+)
+
+// trashPurgeInterval is how often a long running server purges the trash
+const trashPurgeInterval = time.Hour
+
+// PurgeTrash permanently deletes everything that has been in the trash for longer than the retention window
+func PurgeTrash(ctx context.Context, m mongodb.MongoService, retention time.Duration) {
+	report, err := m.PurgeTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		logger.Error("Failed to purge trash", err)
+		return
+	}
+
+	if report.FormsPurged+report.EmailTemplatesPurged+report.PipelinesPurged > 0 {
+		logger.LogInfo(fmt.Sprintf("Purged trash: %d forms with %d responses, %d email templates, %d pipelines with %d runs",
+			report.FormsPurged, report.ResponsesPurged, report.EmailTemplatesPurged, report.PipelinesPurged, report.PipelineRunsPurged))
+	}
+}
+
+// PurgeTrashPeriodically purges the trash every trashPurgeInterval until the context is cancelled
+func PurgeTrashPeriodically(ctx context.Context, m mongodb.MongoService, retention time.Duration) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			PurgeTrash(ctx, m, retention)
+		}
+	}
+}
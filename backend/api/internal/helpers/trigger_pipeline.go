@@ -47,7 +47,7 @@ func TriggerPipeline(c context.Context, producer producer.MessageProducer, mongo
 		var actionMessage kafka.PipelineActionMessage
 		switch action.Type {
 		case "SendEmail":
-			actionMessage = kafka.NewSendEmailMessage("email-action", action.ID, pipeline.ID, runID, action.SendEmail.EmailTemplateID, pipeline.EventID, actionData, action.SendEmail.EmailFieldID)
+			actionMessage = kafka.NewSendEmailMessage("email-action", action.ID, pipeline.ID, runID, action.SendEmail.EmailTemplateID, pipeline.EventID, actionData, action.SendEmail.EmailFieldID, action.SendEmail.AttachCalendarInvite)
 		case "AllowFormAccess":
 			actionMessage = kafka.NewAllowFormAccessMessage("allow-form-access-action", action.ID, pipeline.ID, runID, action.AllowFormAccess.ToFormID, action.AllowFormAccess.Options, actionData, action.AllowFormAccess.EmailFieldID)
 		case "Webhook":
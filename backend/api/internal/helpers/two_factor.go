@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 5 // Encodes to 8 base32 characters
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes returns new 2FA recovery codes to show the user once, and the hashes of them to store
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		code := encoded[:4] + "-" + encoded[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// VerifyTwoFactorCode checks a TOTP code or a recovery code for a user with 2FA enabled.
+// Either kind of code is used up by a successful check so it can't be replayed.
+func VerifyTwoFactorCode(c context.Context, mongo mongodb.MongoService, user *models.User, code string) (bool, error) {
+	if !user.TwoFactor.Enabled {
+		return false, nil
+	}
+
+	// TOTP codes are always 6 digits, anything else is treated as a recovery code
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != 6 {
+		result, err := mongo.ConsumeUserTwoFactorRecoveryCode(c, user.ID, hashRecoveryCode(code))
+		if err != nil {
+			return false, err
+		}
+		return result.ModifiedCount > 0, nil
+	}
+
+	step, ok := utils.ValidateTOTPCode(user.TwoFactor.Secret, code, time.Now(), user.TwoFactor.LastUsedStep)
+	if !ok {
+		return false, nil
+	}
+
+	result, err := mongo.UseUserTwoFactorStep(c, user.ID, step)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// hashRecoveryCode normalises a recovery code before hashing so case and the dash don't matter
+func hashRecoveryCode(code string) string {
+	normalised := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return utils.HashToken(normalised)
+}
@@ -1,15 +1,20 @@
 package middlewares
 
 import (
+	"api/internal/helpers"
 	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
 	"shared/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// JWTAuthMiddleware is a middleware that checks for a valid JWT token, and sets the user info in the context
-func JWTAuthMiddleware() gin.HandlerFunc {
+// JWTAuthMiddleware is a middleware that checks for a valid JWT token belonging to an active session, or a personal API key,
+// and sets the user info in the context
+func JWTAuthMiddleware(m mongodb.MongoService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 
@@ -19,16 +24,145 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Verify the JWT token
-		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-		user, err := utils.VerifyJWT(tokenString)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		if status, message := authenticateRequest(c, m, tokenString); status != http.StatusOK {
+			c.AbortWithStatusJSON(status, gin.H{"error": message})
 			return
 		}
 
-		// Token is valid, set user info in context and proceed
-		c.Set("user", user)
 		c.Next()
 	}
 }
+
+// OptionalAuthMiddleware sets the user info in the context if the request has valid credentials, but lets the request through either way.
+// Use this for routes that show more to authenticated users so revoked sessions and API keys are handled the same as on other routes.
+func OptionalAuthMiddleware(m mongodb.MongoService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authorization")
+		if tokenString != "" {
+			// The user is only set in the context if the credentials are valid
+			authenticateRequest(c, m, tokenString)
+		}
+
+		c.Next()
+	}
+}
+
+// SessionOnlyMiddleware rejects requests authenticated with an API key, use it after JWTAuthMiddleware on account management routes
+func SessionOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := utils.GetAPIKeyFromContext(c); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys can't be used for this request, please log in"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// NoImpersonationMiddleware rejects requests made by support impersonating the user, use it after JWTAuthMiddleware on routes
+// that return more than support should see, like the user's data export
+func NoImpersonationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := utils.GetImpersonatorIDFromContext(c); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This can't be done while impersonating a user"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// PlatformAdminMiddleware only lets platform admins through, use it after JWTAuthMiddleware.
+// The flag is read from the database rather than the token so removing it takes effect straight away.
+func PlatformAdminMiddleware(m mongodb.MongoService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := utils.GetAPIKeyFromContext(c); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API keys can't be used for this request, please log in"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			c.Abort()
+			return // Error is handled in GetUserFromContext
+		}
+
+		user, err := m.FindUserByID(c, authenticatedUser.ID)
+		if err != nil || !user.IsPlatformAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You do not have permission to do this"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateRequest verifies the Authorization header and sets the user info in the context, returning http.StatusOK on success
+func authenticateRequest(c *gin.Context, m mongodb.MongoService, tokenString string) (int, string) {
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+	if utils.IsAPIKey(tokenString) {
+		return authenticateAPIKey(c, m, tokenString)
+	}
+
+	// Verify the JWT token
+	user, sessionID, err := utils.VerifyJWTWithSession(tokenString)
+	if err != nil || sessionID.IsZero() {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	// Make sure the session the token was issued for hasn't been revoked
+	session, err := m.GetUserSession(c, sessionID)
+	if err != nil || session.UserID != user.ID || !session.IsActive() {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+
+	// Support staff impersonating a user can look but not change anything, and everything they look at is audited
+	if !session.ImpersonatedByID.IsZero() {
+		if err := helpers.AuditImpersonatedRequest(c, m, user.ID, session.ImpersonatedByID); err != nil {
+			logger.Error("Failed to audit impersonated request", err)
+			return http.StatusInternalServerError, "Failed to record impersonated request"
+		}
+		if !utils.StringInSlice(c.Request.Method, readOnlyMethods) {
+			return http.StatusForbidden, "Impersonated sessions are read-only"
+		}
+		c.Set("impersonatedByID", session.ImpersonatedByID)
+	}
+
+	// Token is valid, set user info in context and proceed
+	c.Set("user", user)
+	c.Set("sessionID", sessionID)
+	return http.StatusOK, ""
+}
+
+// readOnlyMethods are the methods a read-only API key or impersonated session can use
+var readOnlyMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+func authenticateAPIKey(c *gin.Context, m mongodb.MongoService, key string) (int, string) {
+	apiKey, err := m.GetAPIKeyByHash(c, utils.HashToken(key))
+	if err != nil || !apiKey.IsActive() {
+		return http.StatusUnauthorized, "Invalid or expired API key"
+	}
+
+	if apiKey.ReadOnly && !utils.StringInSlice(c.Request.Method, readOnlyMethods) {
+		return http.StatusForbidden, "This API key is read-only"
+	}
+
+	user, err := m.FindUserByID(c, apiKey.UserID)
+	if err != nil || user.Disabled {
+		return http.StatusUnauthorized, "Invalid or expired API key"
+	}
+
+	if err := m.MarkAPIKeyUsed(c, apiKey.ID); err != nil {
+		logger.Error("Failed to update API key last used", err)
+	}
+
+	// Only set what a JWT would carry so handlers behave the same for both
+	c.Set("user", &models.User{
+		ID:        user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+	})
+	c.Set("apiKey", apiKey)
+	return http.StatusOK, ""
+}
@@ -1,39 +1,102 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"shared/models"
+	"shared/mongodb"
 	"shared/utils"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func setupRouter() *gin.Engine {
+// mockMongoService only implements the session and API key lookups the middleware needs
+type mockMongoService struct {
+	mongodb.MongoService
+	sessions map[primitive.ObjectID]models.UserSession
+	apiKeys  map[string]models.APIKey
+	users    map[primitive.ObjectID]models.User
+	audit    []models.AuditEntry
+	auditErr error
+}
+
+func (m *mockMongoService) CreateAuditEntry(ctx context.Context, entry models.AuditEntry) (*mongo.InsertOneResult, error) {
+	if m.auditErr != nil {
+		return nil, m.auditErr
+	}
+	m.audit = append(m.audit, entry)
+	return &mongo.InsertOneResult{InsertedID: primitive.NewObjectID()}, nil
+}
+
+func (m *mockMongoService) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	key, ok := m.apiKeys[keyHash]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &key, nil
+}
+
+func (m *mockMongoService) FindUserByID(ctx context.Context, userId primitive.ObjectID) (*models.User, error) {
+	user, ok := m.users[userId]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &user, nil
+}
+
+func (m *mockMongoService) MarkAPIKeyUsed(ctx context.Context, keyID primitive.ObjectID) error {
+	return nil
+}
+
+func (m *mockMongoService) GetUserSession(ctx context.Context, sessionID primitive.ObjectID) (*models.UserSession, error) {
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &session, nil
+}
+
+func setupRouter(m mongodb.MongoService) *gin.Engine {
 	r := gin.Default()
-	r.Use(JWTAuthMiddleware())
+	r.Use(JWTAuthMiddleware(m))
 	return r
 }
 
 func TestJWTAuthMiddleware(t *testing.T) {
-	r := setupRouter()
-
-	// Mock handler to check if middleware passes control
-	r.GET("/test", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"message": "passed"})
-	})
-
 	// Create a test user
 	testUser := models.User{
+		ID:        primitive.NewObjectID(),
 		Email:     "test@example.com",
 		FirstName: "John",
 		LastName:  "Doe",
 	}
 
-	// Generate a valid token
-	validToken, _ := utils.GenerateJWT(&testUser)
+	activeSessionID := primitive.NewObjectID()
+	revokedSessionID := primitive.NewObjectID()
+	unknownSessionID := primitive.NewObjectID()
+	m := &mockMongoService{sessions: map[primitive.ObjectID]models.UserSession{
+		activeSessionID:  {ID: activeSessionID, UserID: testUser.ID, ExpiresAt: time.Now().Add(time.Hour)},
+		revokedSessionID: {ID: revokedSessionID, UserID: testUser.ID, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()},
+	}}
+
+	r := setupRouter(m)
+
+	// Mock handler to check if middleware passes control
+	r.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "passed"})
+	})
+
+	// Generate tokens for each session
+	validToken, _ := utils.GenerateJWT(&testUser, activeSessionID)
+	revokedToken, _ := utils.GenerateJWT(&testUser, revokedSessionID)
+	unknownSessionToken, _ := utils.GenerateJWT(&testUser, unknownSessionID)
+	noSessionToken, _ := utils.GenerateJWT(&testUser, primitive.NilObjectID)
 
 	// Test cases
 	tests := []struct {
@@ -42,6 +105,9 @@ func TestJWTAuthMiddleware(t *testing.T) {
 		expectedStatus int
 	}{
 		{"Valid Token", "Bearer " + validToken, http.StatusOK},
+		{"Revoked Session", "Bearer " + revokedToken, http.StatusUnauthorized},
+		{"Unknown Session", "Bearer " + unknownSessionToken, http.StatusUnauthorized},
+		{"No Session", "Bearer " + noSessionToken, http.StatusUnauthorized},
 		{"Invalid Token", "Bearer invalidtoken", http.StatusUnauthorized},
 		{"No Token", "", http.StatusUnauthorized},
 	}
@@ -57,3 +123,128 @@ func TestJWTAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTAuthMiddlewareAPIKeys(t *testing.T) {
+	testUser := models.User{
+		ID:        primitive.NewObjectID(),
+		Email:     "test@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+	}
+
+	validKey, _ := utils.GenerateAPIKey()
+	readOnlyKey, _ := utils.GenerateAPIKey()
+	expiredKey, _ := utils.GenerateAPIKey()
+	revokedKey, _ := utils.GenerateAPIKey()
+	unknownKey, _ := utils.GenerateAPIKey()
+
+	m := &mockMongoService{
+		users: map[primitive.ObjectID]models.User{testUser.ID: testUser},
+		apiKeys: map[string]models.APIKey{
+			utils.HashToken(validKey):    {ID: primitive.NewObjectID(), UserID: testUser.ID},
+			utils.HashToken(readOnlyKey): {ID: primitive.NewObjectID(), UserID: testUser.ID, ReadOnly: true},
+			utils.HashToken(expiredKey):  {ID: primitive.NewObjectID(), UserID: testUser.ID, ExpiresAt: time.Now().Add(-time.Hour)},
+			utils.HashToken(revokedKey):  {ID: primitive.NewObjectID(), UserID: testUser.ID, RevokedAt: time.Now()},
+		},
+	}
+
+	r := setupRouter(m)
+	handler := func(c *gin.Context) {
+		user, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+		_, hasKey := utils.GetAPIKeyFromContext(c)
+		assert.True(t, hasKey)
+		assert.Equal(t, testUser.ID, user.ID)
+		c.JSON(http.StatusOK, gin.H{"message": "passed"})
+	}
+	r.GET("/test", handler)
+	r.POST("/test", handler)
+	r.POST("/session-only", SessionOnlyMiddleware(), handler)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		token          string
+		expectedStatus int
+	}{
+		{"Valid Key", "GET", "/test", "Bearer " + validKey, http.StatusOK},
+		{"Valid Key Without Bearer", "GET", "/test", validKey, http.StatusOK},
+		{"Valid Key Write", "POST", "/test", "Bearer " + validKey, http.StatusOK},
+		{"Read-only Key Read", "GET", "/test", "Bearer " + readOnlyKey, http.StatusOK},
+		{"Read-only Key Write", "POST", "/test", "Bearer " + readOnlyKey, http.StatusForbidden},
+		{"Expired Key", "GET", "/test", "Bearer " + expiredKey, http.StatusUnauthorized},
+		{"Revoked Key", "GET", "/test", "Bearer " + revokedKey, http.StatusUnauthorized},
+		{"Unknown Key", "GET", "/test", "Bearer " + unknownKey, http.StatusUnauthorized},
+		{"Session Only Route", "POST", "/session-only", "Bearer " + validKey, http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", tc.token)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+		})
+	}
+}
+
+func TestJWTAuthMiddlewareImpersonation(t *testing.T) {
+	testUser := models.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+	adminID := primitive.NewObjectID()
+	sessionID := primitive.NewObjectID()
+	m := &mockMongoService{sessions: map[primitive.ObjectID]models.UserSession{
+		sessionID: {ID: sessionID, UserID: testUser.ID, ExpiresAt: time.Now().Add(time.Hour), ImpersonatedByID: adminID},
+	}}
+	token, _ := utils.GenerateJWT(&testUser, sessionID)
+
+	r := setupRouter(m)
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "passed"})
+	}
+	r.GET("/test", handler)
+	r.POST("/test", handler)
+	r.GET("/export", NoImpersonationMiddleware(), handler)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		auditErr       error
+		expectedStatus int
+		expectedAudit  string
+	}{
+		{"Read", "GET", "/test", nil, http.StatusOK, "GET /test"},
+		{"Write", "POST", "/test", nil, http.StatusForbidden, "POST /test"},
+		{"Export", "GET", "/export", nil, http.StatusForbidden, "GET /export"},
+		{"Audit Fails", "GET", "/test", assert.AnError, http.StatusInternalServerError, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m.audit = nil
+			m.auditErr = tc.auditErr
+
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+
+			assert.Equal(t, tc.expectedStatus, resp.Code)
+			if tc.expectedAudit == "" {
+				assert.Empty(t, m.audit)
+				return
+			}
+			if assert.Len(t, m.audit, 1) {
+				assert.Equal(t, models.AuditImpersonatedRequest, m.audit[0].Action)
+				assert.Equal(t, tc.expectedAudit, m.audit[0].Request)
+				assert.Equal(t, testUser.ID, m.audit[0].ActorID)
+				assert.Equal(t, adminID, m.audit[0].ImpersonatedByID)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID     = "applicantatlas"
+	testClientSecret = "secret"
+	testRedirectURL  = "http://localhost:3000/oidc/callback"
+	testKeyID        = "test-key"
+)
+
+// mockIdP is a minimal OpenID Connect provider that issues ID tokens for a single authorization code
+type mockIdP struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	codeChallenge string
+	nonce         string
+	claims        jwt.MapClaims
+	jwksRequests  atomic.Int32
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &mockIdP{key: key}
+	mux := http.NewServeMux()
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idp.jwksRequests.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": testKeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != testClientID || clientSecret != testClientSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+
+		if r.FormValue("code") != "valid-code" || CodeChallengeS256(r.FormValue("code_verifier")) != idp.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     idp.signIDToken(t, idp.claims),
+		})
+	})
+
+	return idp
+}
+
+func (idp *mockIdP) signIDToken(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(idp.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (idp *mockIdP) defaultClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "user-123",
+		"aud":            testClientID,
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          idp.nonce,
+		"email":          "student@university.edu",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	}
+}
+
+func newTestProvider(t *testing.T, idp *mockIdP) *Provider {
+	provider, err := NewProvider(Config{
+		IssuerURL:    idp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}, idp.server.Client())
+	require.NoError(t, err)
+	return provider
+}
+
+func TestNewProviderNotConfigured(t *testing.T) {
+	_, err := NewProvider(Config{}, nil)
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(t, idp)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, idp.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "state", query.Get("state"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, CodeChallengeS256("verifier"), query.Get("code_challenge"))
+
+	// The IdP remembers what it was asked for, as a real one would
+	idp.codeChallenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+	idp.claims = idp.defaultClaims()
+
+	tokens, err := provider.Exchange(context.Background(), "valid-code", "verifier")
+	require.NoError(t, err)
+
+	claims, err := provider.VerifyIDToken(context.Background(), tokens.IDToken, "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.Subject)
+	assert.Equal(t, "student@university.edu", claims.Email)
+	assert.True(t, bool(claims.EmailVerified))
+	assert.Equal(t, "Jane", claims.GivenName)
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := newTestProvider(t, idp)
+	idp.codeChallenge = CodeChallengeS256("verifier")
+	idp.claims = idp.defaultClaims()
+
+	_, err := provider.Exchange(context.Background(), "valid-code", "some-other-verifier")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.nonce = "nonce"
+	provider := newTestProvider(t, idp)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	withClaim := func(key string, value interface{}) string {
+		claims := idp.defaultClaims()
+		claims[key] = value
+		return idp.signIDToken(t, claims)
+	}
+
+	forgedToken := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.defaultClaims())
+	forgedToken.Header["kid"] = testKeyID
+	forged, err := forgedToken.SignedString(otherKey)
+	require.NoError(t, err)
+
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.defaultClaims()).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		token   string
+		isValid bool
+	}{
+		{"Valid Token", idp.signIDToken(t, idp.defaultClaims()), true},
+		{"String Email Verified", withClaim("email_verified", "true"), true},
+		{"Wrong Nonce", withClaim("nonce", "other"), false},
+		{"Wrong Audience", withClaim("aud", "someone-else"), false},
+		{"Multiple Audiences Without Authorized Party", withClaim("aud", []string{testClientID, "someone-else"}), false},
+		{"Wrong Issuer", withClaim("iss", "https://evil.example.com"), false},
+		{"Expired", withClaim("exp", time.Now().Add(-time.Hour).Unix()), false},
+		{"Missing Subject", withClaim("sub", ""), false},
+		{"Invalid Signature", forged, false},
+		{"HMAC Signed", hmacToken, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(context.Background(), tc.token, "nonce")
+			if tc.isValid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestUnknownKeyIDRefetchesKeysAtMostOncePerInterval(t *testing.T) {
+	idp := newMockIdP(t)
+	idp.nonce = "nonce"
+	provider := newTestProvider(t, idp)
+
+	_, err := provider.VerifyIDToken(context.Background(), idp.signIDToken(t, idp.defaultClaims()), "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), idp.jwksRequests.Load())
+
+	unknownKey := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.defaultClaims())
+	unknownKey.Header["kid"] = "unknown-key"
+	token, err := unknownKey.SignedString(idp.key)
+	require.NoError(t, err)
+
+	// The keys were only just fetched, so unknown kids wait for the interval
+	for i := 0; i < 5; i++ {
+		_, err := provider.VerifyIDToken(context.Background(), token, "nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	}
+	assert.Equal(t, int32(1), idp.jwksRequests.Load())
+
+	provider.mu.Lock()
+	provider.keysRefreshedAt = time.Now().Add(-keyRefreshInterval)
+	provider.mu.Unlock()
+
+	// Once it has passed an unknown kid refetches in case the provider rotated its keys, but only once
+	for i := 0; i < 5; i++ {
+		_, err := provider.VerifyIDToken(context.Background(), token, "nonce")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	}
+	assert.Equal(t, int32(2), idp.jwksRequests.Load())
+
+	// Known keys never wait on a refetch
+	_, err = provider.VerifyIDToken(context.Background(), idp.signIDToken(t, idp.defaultClaims()), "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), idp.jwksRequests.Load())
+}
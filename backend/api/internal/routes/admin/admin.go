@@ -0,0 +1,30 @@
+package admin
+
+import (
+	"api/internal/middlewares"
+	"api/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes sets up the routes for platform admins
+func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
+	r.DELETE("/login-lockouts", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), clearLoginLockoutHandler(params))
+
+	// Users
+	r.GET("/users", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), listUsersHandler(params))
+	r.GET("/users/:user_id", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), getUserHandler(params))
+	r.POST("/users/:user_id/disable", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), disableUserHandler(params))
+	r.POST("/users/:user_id/enable", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), enableUserHandler(params))
+	r.POST("/users/:user_id/impersonate", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), impersonateUserHandler(params))
+
+	// Billing
+	r.GET("/subscriptions/:subscription_id", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), getSubscriptionHandler(params))
+	r.PUT("/subscriptions/:subscription_id", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), updateSubscriptionHandler(params))
+	r.GET("/plans", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), listPlansHandler(params))
+	r.PUT("/plans/:plan_id", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), updatePlanHandler(params))
+
+	// Events
+	r.GET("/events", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), listEventsHandler(params))
+	r.GET("/audit", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.PlatformAdminMiddleware(params.MongoService), listAuditLogHandler(params))
+}
@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// getSubscriptionHandler returns a subscription, including its utilization
+func getSubscriptionHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscription, ok := getSubscriptionFromParam(c, params)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"subscription": subscription})
+	}
+}
+
+type updateSubscriptionRequest struct {
+	Status string             `json:"status" validate:"omitempty,oneof=active cancelled paused"`
+	PlanID primitive.ObjectID `json:"planID"`
+	Limits *models.PlanLimits `json:"limits"`
+}
+
+// updateSubscriptionHandler changes a subscription's status, plan or limits. Moving to another plan copies
+// its limits unless limits are given too, so a customer can be moved to a plan with a custom allowance.
+func updateSubscriptionHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req updateSubscriptionRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		subscription, ok := getSubscriptionFromParam(c, params)
+		if !ok {
+			return
+		}
+
+		updated := *subscription
+		if req.Status != "" {
+			updated.Status = req.Status
+		}
+
+		if !req.PlanID.IsZero() && req.PlanID != subscription.PlanID {
+			plans, err := params.MongoService.ListPlans(c, bson.M{"_id": req.PlanID})
+			if err != nil {
+				logger.Error("Failed to get plan", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subscription"})
+				return
+			}
+			if len(plans) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Plan not found"})
+				return
+			}
+			updated.PlanID = plans[0].ID
+			updated.Limits = plans[0].Limits
+		}
+
+		if req.Limits != nil {
+			if req.Limits.MaxEvents < 0 || req.Limits.MaxMonthlyResponses < 0 || req.Limits.MaxMonthlyPipelineRuns < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Limits can't be negative"})
+				return
+			}
+			updated.Limits = *req.Limits
+		}
+
+		if _, err := params.MongoService.UpdateSubscription(c, subscription.ID, updated); err != nil {
+			logger.Error("Failed to update subscription", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subscription"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			Action:     models.AuditSubscriptionUpdate,
+			TargetType: models.AuditTargetSubscription,
+			TargetID:   subscription.ID.Hex(),
+			Diff:       helpers.AuditDiff(subscription, updated),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"subscription": updated})
+	}
+}
+
+// getSubscriptionFromParam loads the subscription in the URL, writing the response if it can't be found
+func getSubscriptionFromParam(c *gin.Context, params *types.RouteParams) (*models.Subscription, bool) {
+	subscriptionID, err := primitive.ObjectIDFromHex(c.Param("subscription_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subscription ID"})
+		return nil, false
+	}
+
+	subscription, err := params.MongoService.GetSubscription(c, subscriptionID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subscription not found"})
+			return nil, false
+		}
+		logger.Error("Failed to get subscription", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription"})
+		return nil, false
+	}
+
+	return subscription, true
+}
+
+// listPlansHandler returns every plan
+func listPlansHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plans, err := params.MongoService.ListPlans(c, bson.M{})
+		if err != nil {
+			logger.Error("Failed to list plans", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list plans"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"plans": plans})
+	}
+}
+
+type updatePlanRequest struct {
+	Limits models.PlanLimits `json:"limits"`
+}
+
+// updatePlanHandler changes the limits new subscriptions to a plan start with, existing subscriptions keep theirs
+func updatePlanHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		planID, err := primitive.ObjectIDFromHex(c.Param("plan_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid plan ID"})
+			return
+		}
+
+		var req updatePlanRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if req.Limits.MaxEvents < 0 || req.Limits.MaxMonthlyResponses < 0 || req.Limits.MaxMonthlyPipelineRuns < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limits can't be negative"})
+			return
+		}
+
+		plans, err := params.MongoService.ListPlans(c, bson.M{"_id": planID})
+		if err != nil {
+			logger.Error("Failed to get plan", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plan"})
+			return
+		}
+		if len(plans) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found"})
+			return
+		}
+		plan := plans[0]
+
+		if _, err := params.MongoService.UpdatePlanLimits(c, planID, req.Limits); err != nil {
+			logger.Error("Failed to update plan limits", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update plan"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			Action:     models.AuditPlanUpdate,
+			TargetType: models.AuditTargetPlan,
+			TargetID:   planID.Hex(),
+			Diff:       map[string]models.AuditChange{"limits": {Before: plan.Limits, After: req.Limits}},
+		})
+
+		plan.Limits = req.Limits
+		c.JSON(http.StatusOK, gin.H{"plan": plan})
+	}
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"regexp"
+	"shared/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// listEventsHandler lists events across the platform, searching by name with ?q= and by organizer with ?userID=
+func listEventsHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := bson.M{}
+		if q := c.Query("q"); q != "" {
+			filter["metadata.name"] = primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+		}
+		if userID := c.Query("userID"); userID != "" {
+			objID, err := primitive.ObjectIDFromHex(userID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+				return
+			}
+			filter["organizerIDs"] = objID
+		}
+
+		page, pageSize := parsePage(c)
+		opts := options.Find().
+			SetSkip(int64((page - 1) * pageSize)).
+			SetLimit(int64(pageSize)).
+			SetSort(bson.D{{Key: "_id", Value: -1}})
+
+		events, total, err := params.MongoService.ListEvents(c, filter, opts)
+		if err != nil {
+			logger.Error("Failed to list events", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list events"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"events": events, "page": page, "pageSize": pageSize, "total": total})
+	}
+}
+
+// listAuditLogHandler returns the audit log across the platform, including admin actions that aren't tied to an event.
+// It takes the same filters as an event's audit log along with ?eventID=.
+func listAuditLogHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := bson.M{}
+		if eventID := c.Query("eventID"); eventID != "" {
+			objID, err := primitive.ObjectIDFromHex(eventID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+				return
+			}
+			filter["eventID"] = objID
+		}
+
+		query, ok := helpers.ParseAuditLogQuery(c, filter)
+		if !ok {
+			return
+		}
+
+		entries, total, err := params.MongoService.ListAuditEntries(c, query.Filter, query.Options)
+		if err != nil {
+			logger.Error("Failed to list audit log entries", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit log"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"entries": entries, "page": query.Page, "pageSize": query.PageSize, "total": total})
+	}
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// clearLoginLockoutHandler forgets the failed logins of an email and/or IP address, lifting any lockout on them
+func clearLoginLockoutHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := helpers.NormalizeLoginEmail(c.Query("email"))
+		ip := c.Query("ip")
+		if email == "" && ip == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An email or IP address is required"})
+			return
+		}
+
+		cleared := gin.H{}
+		if email != "" {
+			result, err := params.MongoService.ClearLoginThrottle(c, models.LoginThrottleAccount, email)
+			if err != nil {
+				logger.Error("Failed to clear account login throttle", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lockout"})
+				return
+			}
+			cleared["email"] = result.DeletedCount > 0
+		}
+
+		if ip != "" {
+			result, err := params.MongoService.ClearLoginThrottle(c, models.LoginThrottleIP, ip)
+			if err != nil {
+				logger.Error("Failed to clear IP login throttle", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear lockout"})
+				return
+			}
+			cleared["ip"] = result.DeletedCount > 0
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Failed logins cleared", "cleared": cleared})
+	}
+}
@@ -0,0 +1,237 @@
+package admin
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"regexp"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// impersonationTTL is how long support can view a user's account before starting again, the session can't be refreshed
+const impersonationTTL = 15 * time.Minute
+
+// parsePage reads the page and pageSize query parameters, pageSize defaults to 50 and is at most 100
+func parsePage(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+	return page, pageSize
+}
+
+// listUsersHandler searches every user by email or name with ?q=, newest first
+func listUsersHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := bson.M{}
+		if q := c.Query("q"); q != "" {
+			pattern := primitive.Regex{Pattern: regexp.QuoteMeta(q), Options: "i"}
+			filter["$or"] = []bson.M{{"email": pattern}, {"firstName": pattern}, {"lastName": pattern}}
+		}
+		if c.Query("disabled") == "true" {
+			filter["disabled"] = true
+		}
+
+		page, pageSize := parsePage(c)
+		opts := options.Find().
+			SetSkip(int64((page - 1) * pageSize)).
+			SetLimit(int64(pageSize)).
+			SetSort(bson.D{{Key: "_id", Value: -1}})
+
+		users, total, err := params.MongoService.ListUsers(c, filter, opts)
+		if err != nil {
+			logger.Error("Failed to list users", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"users": users, "page": page, "pageSize": pageSize, "total": total})
+	}
+}
+
+// getUserHandler returns a user along with their subscription
+func getUserHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := getUserFromParam(c, params)
+		if !ok {
+			return
+		}
+
+		var subscription *models.Subscription
+		if !user.CurrentSubscriptionID.IsZero() {
+			var err error
+			subscription, err = params.MongoService.GetSubscription(c, user.CurrentSubscriptionID)
+			if err != nil && err != mongo.ErrNoDocuments {
+				logger.Error("Failed to get user's subscription", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"user": user, "subscription": subscription})
+	}
+}
+
+// disableUserHandler stops a user logging in, signing them out everywhere and revoking their API keys
+func disableUserHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := getUserFromParam(c, params)
+		if !ok {
+			return
+		}
+
+		if user.IsPlatformAdmin {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Platform admins can't be disabled"})
+			return
+		}
+
+		if _, err := params.MongoService.SetUserDisabled(c, user.ID, true); err != nil {
+			logger.Error("Failed to disable user", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable user"})
+			return
+		}
+
+		if _, err := params.MongoService.RevokeAllUserSessions(c, user.ID); err != nil {
+			logger.Error("Failed to revoke sessions of disabled user", err)
+		}
+
+		if _, err := params.MongoService.RevokeAllUserAPIKeys(c, user.ID); err != nil {
+			logger.Error("Failed to revoke API keys of disabled user", err)
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			Action:     models.AuditUserDisable,
+			TargetType: models.AuditTargetUser,
+			TargetID:   user.ID.Hex(),
+			Diff:       map[string]models.AuditChange{"disabled": {Before: user.Disabled, After: true}},
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "User disabled"})
+	}
+}
+
+// enableUserHandler lets a disabled user log in again, their API keys stay revoked
+func enableUserHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := getUserFromParam(c, params)
+		if !ok {
+			return
+		}
+
+		if _, err := params.MongoService.SetUserDisabled(c, user.ID, false); err != nil {
+			logger.Error("Failed to enable user", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable user"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			Action:     models.AuditUserEnable,
+			TargetType: models.AuditTargetUser,
+			TargetID:   user.ID.Hex(),
+			Diff:       map[string]models.AuditChange{"disabled": {Before: user.Disabled, After: false}},
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "User enabled"})
+	}
+}
+
+// impersonateUserHandler gives support a short, read-only session as the user so they can see what the user sees.
+// There's no refresh token, once it expires a new impersonation has to be started and audited.
+func impersonateUserHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		admin, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return // Error is handled in GetUserFromContext
+		}
+
+		user, ok := getUserFromParam(c, params)
+		if !ok {
+			return
+		}
+
+		if user.IsPlatformAdmin {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Platform admins can't be impersonated"})
+			return
+		}
+
+		if user.Disabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Disabled users can't be impersonated"})
+			return
+		}
+
+		now := time.Now()
+		session := models.UserSession{
+			UserID:           user.ID,
+			UserAgent:        c.Request.UserAgent(),
+			IPAddress:        c.ClientIP(),
+			CreatedAt:        now,
+			LastRefreshedAt:  now,
+			ExpiresAt:        now.Add(impersonationTTL),
+			ImpersonatedByID: admin.ID,
+		}
+
+		res, err := params.MongoService.CreateUserSession(c, session)
+		if err != nil {
+			logger.Error("Failed to create impersonation session", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to impersonate user"})
+			return
+		}
+		sessionID := res.InsertedID.(primitive.ObjectID)
+
+		token, err := utils.GenerateJWT(user, sessionID)
+		if err != nil {
+			logger.Error("Failed to generate impersonation token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to impersonate user"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			Action:     models.AuditUserImpersonate,
+			TargetType: models.AuditTargetUser,
+			TargetID:   user.ID.Hex(),
+			Diff: map[string]models.AuditChange{
+				"sessionID": {After: sessionID.Hex()},
+				"expiresAt": {After: session.ExpiresAt},
+			},
+		})
+
+		c.JSON(http.StatusOK, gin.H{"token": token, "expiresAt": session.ExpiresAt})
+	}
+}
+
+// getUserFromParam loads the user in the URL, writing the response if they can't be found
+func getUserFromParam(c *gin.Context, params *types.RouteParams) (*models.User, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+
+	user, err := params.MongoService.FindUserByID(c, userID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return nil, false
+		}
+		logger.Error("Failed to find user", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		return nil, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"api/internal/types"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"shared/kafka"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const accountDeletionStatusTokenBytes = 32
+
+// deleteUser deletes the account straight away and queues a job to clean up the rest of the user's data.
+// The response includes a status token since the user can't log in to check on the job anymore.
+func deleteUser(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return // Error is handled in GetUserFromContext
+		}
+
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+
+		// Nobody would be left to run or pay for an organization the user is the only member of
+		organizations, err := params.MongoService.ListUserOrganizations(c, user.ID)
+		if err != nil {
+			logger.Error("Failed to list organizations of user being deleted", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+		soleMemberOf := []string{}
+		for _, organization := range organizations {
+			if len(organization.Members) == 1 {
+				soleMemberOf = append(soleMemberOf, organization.Name)
+			}
+		}
+		if len(soleMemberOf) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "You're the only member of " + strings.Join(soleMemberOf, ", ") + ", add another owner or delete it before deleting your account"})
+			return
+		}
+
+		statusToken, err := utils.GenerateSecureToken(accountDeletionStatusTokenBytes)
+		if err != nil {
+			logger.Error("Failed to generate account deletion status token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+
+		deletion := models.AccountDeletion{
+			UserID:          user.ID,
+			Email:           user.Email,
+			StatusTokenHash: utils.HashToken(statusToken),
+			Status:          models.AccountDeletionPending,
+			RequestedAt:     time.Now(),
+		}
+
+		result, err := params.MongoService.CreateAccountDeletion(c, deletion)
+		if err != nil {
+			logger.Error("Failed to create account deletion", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+		deletion.ID = result.InsertedID.(primitive.ObjectID)
+
+		// Queue the job before removing the account so the user's data is never left without a way to clean it up
+		if err := queueAccountDeletion(params, deletion.ID); err != nil {
+			logger.Error("Failed to queue account deletion", err)
+			if err := params.MongoService.FinishAccountDeletion(c, deletion.ID, models.AccountDeletionReport{}, err); err != nil {
+				logger.Error("Failed to mark account deletion as failed", err)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+			return
+		}
+
+		if _, err := params.MongoService.RevokeAllUserSessions(c, user.ID); err != nil {
+			logger.Error("Failed to revoke sessions of deleted user", err)
+		}
+
+		if _, err := params.MongoService.RevokeAllUserAPIKeys(c, user.ID); err != nil {
+			logger.Error("Failed to revoke API keys of deleted user", err)
+		}
+
+		// Removing the user now stops them logging back in before the job gets to it
+		if _, err := params.MongoService.DeleteUserByEmail(c, user.Email); err != nil {
+			logger.Error("Failed to delete user", err)
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":     "Account deleted, the rest of your data is being removed",
+			"deletion":    deletion,
+			"statusToken": statusToken,
+		})
+	}
+}
+
+func queueAccountDeletion(params *types.RouteParams, deletionID primitive.ObjectID) error {
+	messageBytes, err := json.Marshal(kafka.NewDeleteAccountMessage("delete-account", deletionID))
+	if err != nil {
+		return err
+	}
+
+	if err := params.MessageProducer.ProduceMessage(string(messageBytes)); err != nil {
+		return fmt.Errorf("failed to write message to %s: %w", params.MessageProducer.GetType(), err)
+	}
+
+	return nil
+}
+
+type accountDeletionStatusRequest struct {
+	StatusToken string `json:"statusToken" validate:"required"`
+}
+
+// getAccountDeletionStatus returns the status and report of an account deletion job using the token deleteUser returned
+func getAccountDeletionStatus(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req accountDeletionStatusRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		deletion, err := params.MongoService.GetAccountDeletionByStatusToken(c, utils.HashToken(req.StatusToken))
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Account deletion not found"})
+				return
+			}
+			logger.Error("Failed to get account deletion", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get account deletion status"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deletion": deletion})
+	}
+}
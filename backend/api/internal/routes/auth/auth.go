@@ -1,19 +1,21 @@
 package auth
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/types"
 	"fmt"
+	"math"
 	"net/http"
 	"shared/logger"
 	"shared/models"
 	"shared/mongodb"
 	"shared/utils"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
@@ -22,8 +24,20 @@ import (
 // RegisterRoutes sets up the routes for authentication
 func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
 	r.POST("/login", loginUser(params))
+	r.POST("/login/2fa", loginTwoFactor(params))
+	r.POST("/magic-link", requestMagicLink(params))
+	r.POST("/magic-link/verify", verifyMagicLink(params))
 	r.POST("/register", registerUser(params))
-	r.DELETE("/delete", middlewares.JWTAuthMiddleware(), deleteUser(params))
+	r.POST("/refresh", refreshSession(params))
+	r.POST("/logout", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware(), logoutUser(params))
+	r.POST("/forgot-password", forgotPassword(params))
+	r.POST("/reset-password", resetPassword(params))
+	r.POST("/verify-email", verifyEmail(params))
+	r.POST("/verify-email/resend", middlewares.JWTAuthMiddleware(params.MongoService), resendEmailVerification(params))
+	r.GET("/oidc/login", startOIDCLogin(params))
+	r.POST("/oidc/callback", finishOIDCLogin(params))
+	r.DELETE("/delete", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware(), deleteUser(params))
+	r.POST("/delete/status", getAccountDeletionStatus(params))
 }
 
 type loginRequest struct {
@@ -39,10 +53,23 @@ func loginUser(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		// Checked before the password so a locked out account can't be used to test guesses
+		wait, err := helpers.LoginRetryAfter(c, params.MongoService, req.Email, c.ClientIP())
+		if err != nil {
+			logger.Error("Failed to check login throttle", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+		if wait > 0 {
+			respondLoginThrottled(c, wait)
+			return
+		}
+
 		user, err := params.MongoService.FindUserByEmail(c, req.Email)
 		if err != nil {
 			if err == mongo.ErrNoDocuments {
-				// User not found
+				// User not found, still counted so guessing emails is slowed down too
+				recordFailedLogin(c, params, nil, req.Email)
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Email and password do not match"})
 				return
 			}
@@ -53,21 +80,36 @@ func loginUser(params *types.RouteParams) gin.HandlerFunc {
 
 		// Check password
 		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+			recordFailedLogin(c, params, user, req.Email)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Email and password do not match"})
 			return
 		}
 
-		// Generate JWT token
-		token, err := utils.GenerateJWT(user)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-			return
+		if err := helpers.ClearAccountLoginFailures(c, params.MongoService, user.Email); err != nil {
+			logger.Error("Failed to clear failed logins", err)
 		}
 
-		c.JSON(http.StatusOK, gin.H{"token": token})
+		// Start a new session, or ask for a 2FA code first
+		respondWithLogin(c, params, user)
 	}
 }
 
+func recordFailedLogin(c *gin.Context, params *types.RouteParams, user *models.User, email string) {
+	if err := helpers.RecordFailedLogin(c, params.MongoService, user, email, c.ClientIP()); err != nil {
+		logger.Error("Failed to record failed login", err)
+	}
+}
+
+// respondLoginThrottled tells the client how many seconds to wait before logging in again
+func respondLoginThrottled(c *gin.Context, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":      fmt.Sprintf("Too many failed login attempts, please try again in %d seconds", seconds),
+		"retryAfter": seconds,
+	})
+}
+
 type registerRequest struct {
 	Email     string `json:"email" validate:"required,email"`
 	Password  string `json:"password" validate:"required,securepwd"`
@@ -114,86 +156,53 @@ func registerUser(params *types.RouteParams) gin.HandlerFunc {
 			PasswordHash: string(hash),
 		}
 
-		// Insert the new user into the database
-		r, err := params.MongoService.InsertUser(c, newUser)
-		if err != nil {
+		if err := createUserWithDefaultPlan(c, params, &newUser); err != nil {
 			if err == mongodb.ErrUserAlreadyExists {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "An account with that email already exists"})
 				return
 			}
+			logger.Error("Failed to register user", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 			return
 		}
-		newUser.ID = r.InsertedID.(primitive.ObjectID)
 
-		// Generate JWT token
-		token, err := utils.GenerateJWT(&newUser)
+		// Start a new session
+		token, refreshToken, err := startSession(c, params, &newUser)
 		if err != nil {
+			logger.Error("Failed to start session", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 			return
 		}
 
-		listPlans, err := params.MongoService.ListPlans(c, bson.M{"default": true})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve plans"})
-			return
-		}
-
-		// Create a new free plan for the user
-		newSubscription := models.Subscription{
-			PlanID:    listPlans[0].ID,
-			UserID:    newUser.ID,
-			Status:    models.SubscriptionStatusActive,
-			StartDate: time.Now(),
-			EndDate:   time.Now().AddDate(0, 1, 0),
-			Limits:    listPlans[0].Limits,
-			Utilization: models.Utilization{
-				EventsCreated: 0,
-				Responses:     0,
-				PipelineRuns:  0,
-			},
-			BillingCycle:             "monthly",
-			NextUtilizationResetDate: time.Now().AddDate(0, 1, 0),
-		}
-
-		subscriptionId, err := params.MongoService.CreateNewSubscription(c, newSubscription)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subscription"})
-			return
-		}
-
-		newUser.CurrentSubscriptionID = subscriptionId.InsertedID.(primitive.ObjectID)
-
-		// Update the user with the new subscription
-		_, err = params.MongoService.UpdateUser(c, newUser.ID, newUser)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user details"})
-			return
+		if err := helpers.SendEmailVerification(c, params.MongoService, &newUser); err != nil {
+			logger.Error("Failed to send verification email", err)
 		}
 
 		utils.SendSlackMessage(fmt.Sprintf("New User: %s %s (%s)", newUser.FirstName, newUser.LastName, newUser.Email))
-		c.JSON(http.StatusOK, gin.H{"token": token})
+		c.JSON(http.StatusOK, gin.H{"token": token, "refreshToken": refreshToken})
 	}
 }
 
-// TODO: We should base this on the user's id instead of email, and handle deleting all data like responses
-func deleteUser(params *types.RouteParams) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authenticatedUser, ok := utils.GetUserFromContext(c, true)
-		if !ok {
-			return // Error is handled in GetUserFromContext
-		}
+// createUserWithDefaultPlan inserts a new user and puts them on the default free plan, user.ID is set on success
+func createUserWithDefaultPlan(c *gin.Context, params *types.RouteParams, user *models.User) error {
+	r, err := params.MongoService.InsertUser(c, *user)
+	if err != nil {
+		return err
+	}
+	user.ID = r.InsertedID.(primitive.ObjectID)
 
-		_, err := params.MongoService.DeleteUserByEmail(c, authenticatedUser.Email)
-		if err != nil {
-			if err == mongo.ErrNoDocuments {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
-			return
-		}
+	subscriptionID, err := helpers.CreateDefaultSubscription(c, params.MongoService, user.ID, primitive.NilObjectID)
+	if err != nil {
+		return err
+	}
 
-		c.JSON(http.StatusOK, gin.H{"message": "Account deleted successfully"})
+	user.CurrentSubscriptionID = subscriptionID
+
+	// Update the user with the new subscription
+	_, err = params.MongoService.UpdateUser(c, user.ID, *user)
+	if err != nil {
+		return fmt.Errorf("failed to update user details: %w", err)
 	}
+
+	return nil
 }
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"fmt"
+	"net/http"
+	"net/url"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	magicLinkTokenBytes = 32
+	magicLinkTokenTTL   = 15 * time.Minute
+	// magicLinkCooldown is how long an email has to wait for another link, so the endpoint can't be used to flood inboxes
+	magicLinkCooldown = time.Minute
+)
+
+type magicLinkRequest struct {
+	Email  string             `json:"email" validate:"required,email"`
+	FormID primitive.ObjectID `json:"formID,omitempty"` // The form to send the applicant back to once they're signed in
+}
+
+// requestMagicLink emails the user a single-use link that logs them in without their password.
+// This always responds with the same message so it can't be used to check if an email has an account, or if a link was
+// already sent during the cooldown.
+func requestMagicLink(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req magicLinkRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		successMessage := gin.H{"message": "If an account with that email exists, a sign in link has been sent to it"}
+
+		user, err := params.MongoService.FindUserByEmail(c, req.Email)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				logger.Error("Failed to find user by email", err)
+			}
+			c.JSON(http.StatusOK, successMessage)
+			return
+		}
+
+		recentLinks, err := params.MongoService.CountRecentUserTokens(c, user.ID, models.UserTokenMagicLink, time.Now().Add(-magicLinkCooldown))
+		if err != nil {
+			logger.Error("Failed to count recent magic links", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sign in link"})
+			return
+		}
+		if recentLinks > 0 {
+			c.JSON(http.StatusOK, successMessage)
+			return
+		}
+
+		token, err := utils.GenerateSecureToken(magicLinkTokenBytes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sign in link"})
+			return
+		}
+
+		// Only the newest link works so an old email sitting in an inbox can't be used
+		if _, err := params.MongoService.InvalidateUserTokens(c, user.ID, models.UserTokenMagicLink); err != nil {
+			logger.Error("Failed to invalidate old magic links", err)
+		}
+
+		_, err = params.MongoService.CreateUserToken(c, models.UserToken{
+			UserID:    user.ID,
+			Type:      models.UserTokenMagicLink,
+			TokenHash: utils.HashToken(token),
+			Email:     user.Email,
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(magicLinkTokenTTL),
+		})
+		if err != nil {
+			logger.Error("Failed to store magic link token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate sign in link"})
+			return
+		}
+
+		path := "/magic-link?token=" + url.QueryEscape(token)
+		if !req.FormID.IsZero() {
+			path += "&formID=" + req.FormID.Hex()
+		}
+
+		body := fmt.Sprintf("Hi %s,\n\nUse the link below to sign in to ApplicantAtlas. "+
+			"This link expires in %d minutes and can only be used once.\n\n%s\n\n"+
+			"If you didn't ask to sign in you can ignore this email.",
+			user.FirstName, int(magicLinkTokenTTL.Minutes()), utils.WebsiteURL(path))
+
+		if err := utils.SendPlatformEmail(user.Email, "Your ApplicantAtlas sign in link", body); err != nil {
+			logger.Error("Failed to send magic link email", err)
+		}
+
+		c.JSON(http.StatusOK, successMessage)
+	}
+}
+
+type verifyMagicLinkRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// verifyMagicLink uses up a token from requestMagicLink and logs the user in, users with 2FA still have to enter a code
+func verifyMagicLink(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req verifyMagicLinkRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		token, err := params.MongoService.ConsumeUserToken(c, utils.HashToken(req.Token), models.UserTokenMagicLink)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This sign in link is invalid or has expired"})
+				return
+			}
+			logger.Error("Failed to consume magic link token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		user, err := params.MongoService.FindUserByID(c, token.UserID)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This sign in link is invalid or has expired"})
+				return
+			}
+			logger.Error("Failed to find user for magic link", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		// Whoever has the old inbox shouldn't be able to get into the account after the email changes
+		if user.Email != token.Email {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This sign in link is for an email address that is no longer on your account"})
+			return
+		}
+
+		// Opening the link proves they own the address
+		if !user.EmailVerified {
+			if _, err := params.MongoService.MarkUserEmailVerified(c, user.ID, token.Email); err != nil {
+				logger.Error("Failed to mark email as verified", err)
+			} else {
+				user.EmailVerified = true
+			}
+		}
+
+		if err := helpers.ClearAccountLoginFailures(c, params.MongoService, user.Email); err != nil {
+			logger.Error("Failed to clear failed logins", err)
+		}
+
+		// Start a new session, or ask for a 2FA code first
+		respondWithLogin(c, params, user)
+	}
+}
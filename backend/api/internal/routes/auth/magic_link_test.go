@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"api/internal/types"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockMagicLinkService only implements the user and token lookups requesting a magic link needs
+type mockMagicLinkService struct {
+	mongodb.MongoService
+	user        *models.User
+	recentLinks int64
+	countErr    error
+	since       time.Time
+	created     []models.UserToken
+}
+
+func (m *mockMagicLinkService) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if m.user == nil || m.user.Email != email {
+		return nil, mongo.ErrNoDocuments
+	}
+	return m.user, nil
+}
+
+func (m *mockMagicLinkService) CountRecentUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType, since time.Time) (int64, error) {
+	m.since = since
+	return m.recentLinks, m.countErr
+}
+
+func (m *mockMagicLinkService) InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType) (*mongo.UpdateResult, error) {
+	return &mongo.UpdateResult{}, nil
+}
+
+func (m *mockMagicLinkService) CreateUserToken(ctx context.Context, token models.UserToken) (*mongo.InsertOneResult, error) {
+	m.created = append(m.created, token)
+	return &mongo.InsertOneResult{InsertedID: primitive.NewObjectID()}, nil
+}
+
+func TestRequestMagicLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: primitive.NewObjectID(), Email: "applicant@example.com", FirstName: "Ada"}
+
+	tests := []struct {
+		name        string
+		email       string
+		recentLinks int64
+		countErr    error
+		expected    int
+		sent        bool
+	}{
+		{"Sends a link", user.Email, 0, nil, http.StatusOK, true},
+		{"Link already sent in the last minute", user.Email, 1, nil, http.StatusOK, false},
+		{"No account", "nobody@example.com", 0, nil, http.StatusOK, false},
+		{"Counting links fails", user.Email, 0, errors.New("connection reset"), http.StatusInternalServerError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockMagicLinkService{user: user, recentLinks: tt.recentLinks, countErr: tt.countErr}
+			router := gin.New()
+			router.POST("/magic-link", requestMagicLink(&types.RouteParams{MongoService: m}))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/magic-link", strings.NewReader(`{"email":"`+tt.email+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.sent {
+				assert.Len(t, m.created, 1)
+				assert.Equal(t, models.UserTokenMagicLink, m.created[0].Type)
+			} else {
+				assert.Empty(t, m.created)
+			}
+			if tt.email == user.Email {
+				assert.WithinDuration(t, time.Now().Add(-magicLinkCooldown), m.since, time.Second)
+			}
+		})
+	}
+
+	t.Run("Same response during the cooldown", func(t *testing.T) {
+		responses := []string{}
+		for _, recentLinks := range []int64{0, 1} {
+			router := gin.New()
+			router.POST("/magic-link", requestMagicLink(&types.RouteParams{MongoService: &mockMagicLinkService{user: user, recentLinks: recentLinks}}))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/magic-link", strings.NewReader(`{"email":"`+user.Email+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			responses = append(responses, w.Body.String())
+		}
+		assert.Equal(t, responses[0], responses[1], "the cooldown can't be used to tell if an email has an account")
+	})
+}
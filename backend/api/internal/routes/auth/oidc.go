@@ -0,0 +1,335 @@
+package auth
+
+import (
+	"api/internal/oidc"
+	"api/internal/types"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"shared/config"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	oidcStateBytes    = 32
+	oidcVerifierBytes = 48 // Encodes to 64 characters, within the 43-128 PKCE allows
+	oidcLoginStateTTL = 10 * time.Minute
+
+	// oidcLoginCookie ties a login to the browser that started it, so nobody can finish their own login in someone else's browser
+	oidcLoginCookie = "oidc_login"
+)
+
+var (
+	oidcProvider   *oidc.Provider
+	oidcProviderMu sync.Mutex
+)
+
+// getOIDCProvider returns the configured OIDC provider, or oidc.ErrNotConfigured if OIDC login isn't enabled.
+// Discovery is retried on the next login if it fails, so a provider that was down at startup doesn't disable login.
+func getOIDCProvider() (*oidc.Provider, error) {
+	oidcProviderMu.Lock()
+	defer oidcProviderMu.Unlock()
+	if oidcProvider != nil {
+		return oidcProvider, nil
+	}
+
+	apiConfig, err := config.GetAPIConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURL := apiConfig.OIDC_REDIRECT_URL
+	if redirectURL == "" {
+		redirectURL = utils.WebsiteURL("/oidc/callback")
+	}
+
+	provider, err := oidc.NewProvider(oidc.Config{
+		IssuerURL:    apiConfig.OIDC_ISSUER_URL,
+		ClientID:     apiConfig.OIDC_CLIENT_ID,
+		ClientSecret: apiConfig.OIDC_CLIENT_SECRET,
+		RedirectURL:  redirectURL,
+		Scopes:       apiConfig.OIDC_SCOPES,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	oidcProvider = provider
+	return oidcProvider, nil
+}
+
+// respondOIDCProviderError responds to getOIDCProvider failing
+func respondOIDCProviderError(c *gin.Context, err error) {
+	if errors.Is(err, oidc.ErrNotConfigured) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not enabled"})
+		return
+	}
+	logger.Error("Failed to set up OIDC provider", err)
+	c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach the identity provider"})
+}
+
+// setOIDCLoginCookie stores the login's state and nonce in an HttpOnly cookie, or clears it when both are empty
+func setOIDCLoginCookie(c *gin.Context, state string, nonce string) {
+	maxAge := int(oidcLoginStateTTL.Seconds())
+	value := state + "." + nonce
+	if state == "" && nonce == "" {
+		maxAge = -1
+		value = ""
+	}
+
+	// The website and API can be on different sites, cookies are only sent cross-site with SameSite=None which needs HTTPS
+	secure := strings.HasPrefix(utils.WebsiteURL(""), "https://")
+	sameSite := http.SameSiteLaxMode
+	if secure {
+		sameSite = http.SameSiteNoneMode
+	}
+
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcLoginCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	})
+}
+
+// oidcLoginCookieMatches checks the login cookie was set for this state and nonce
+func oidcLoginCookieMatches(c *gin.Context, state string, nonce string) bool {
+	cookie, err := c.Cookie(oidcLoginCookie)
+	if err != nil {
+		return false
+	}
+
+	cookieState, cookieNonce, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(cookieNonce), []byte(nonce)) == 1
+}
+
+// startOIDCLogin starts an authorization code + PKCE login and returns the provider URL the website should send the user to
+func startOIDCLogin(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, err := getOIDCProvider()
+		if err != nil {
+			respondOIDCProviderError(c, err)
+			return
+		}
+
+		state, stateErr := utils.GenerateSecureToken(oidcStateBytes)
+		nonce, nonceErr := utils.GenerateSecureToken(oidcStateBytes)
+		codeVerifier, verifierErr := utils.GenerateSecureToken(oidcVerifierBytes)
+		if err := errors.Join(stateErr, nonceErr, verifierErr); err != nil {
+			logger.Error("Failed to generate OIDC login state", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+
+		authURL, err := provider.AuthCodeURL(c, state, nonce, codeVerifier)
+		if err != nil {
+			logger.Error("Failed to build OIDC authorization URL", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach the identity provider"})
+			return
+		}
+
+		_, err = params.MongoService.CreateOIDCLoginState(c, models.OIDCLoginState{
+			StateHash:    utils.HashToken(state),
+			Nonce:        nonce,
+			CodeVerifier: codeVerifier,
+			CreatedAt:    time.Now(),
+			ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+		})
+		if err != nil {
+			logger.Error("Failed to store OIDC login state", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+			return
+		}
+
+		setOIDCLoginCookie(c, state, nonce)
+		c.JSON(http.StatusOK, gin.H{"url": authURL})
+	}
+}
+
+type oidcCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// finishOIDCLogin exchanges the authorization code, links or creates the user by their verified email, and starts a session
+func finishOIDCLogin(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, err := getOIDCProvider()
+		if err != nil {
+			respondOIDCProviderError(c, err)
+			return
+		}
+
+		var req oidcCallbackRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		loginState, err := params.MongoService.ConsumeOIDCLoginState(c, utils.HashToken(req.State))
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This login attempt is invalid or has expired, please try again"})
+				return
+			}
+			logger.Error("Failed to consume OIDC login state", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		// The login state is used up either way, so a login that was started in another browser can't be retried here
+		matches := oidcLoginCookieMatches(c, req.State, loginState.Nonce)
+		setOIDCLoginCookie(c, "", "")
+		if !matches {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This login was started in a different browser, please try again"})
+			return
+		}
+
+		tokens, err := provider.Exchange(c, req.Code, loginState.CodeVerifier)
+		if err != nil {
+			logger.Error("Failed to exchange OIDC authorization code", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to login with the identity provider"})
+			return
+		}
+
+		claims, err := provider.VerifyIDToken(c, tokens.IDToken, loginState.Nonce)
+		if err != nil {
+			logger.Error("Failed to verify OIDC ID token", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to login with the identity provider"})
+			return
+		}
+
+		user, err := findOrCreateOIDCUser(c, params, provider.Issuer(), claims)
+		if err != nil {
+			if err == errOIDCEmailNotVerified {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Your identity provider did not share a verified email address"})
+				return
+			}
+			logger.Error("Failed to find or create OIDC user", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		// Users with 2FA enabled still need to provide a code after signing in with their provider
+		respondWithLogin(c, params, user)
+	}
+}
+
+var errOIDCEmailNotVerified = errors.New("OIDC provider did not return a verified email")
+
+// findOrCreateOIDCUser returns the user already linked to the identity, otherwise links the user with the same
+// verified email or creates a new one. Accounts are only ever matched by email when the provider has verified it.
+func findOrCreateOIDCUser(c *gin.Context, params *types.RouteParams, issuer string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	user, err := params.MongoService.FindUserByOIDCIdentity(c, issuer, claims.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	if claims.Email == "" || !bool(claims.EmailVerified) {
+		return nil, errOIDCEmailNotVerified
+	}
+
+	identity := models.OIDCIdentity{
+		Issuer:   issuer,
+		Subject:  claims.Subject,
+		LinkedAt: time.Now(),
+	}
+
+	user, err = params.MongoService.FindUserByEmail(c, claims.Email)
+	if err == nil {
+		// Anyone could have registered the address before its owner, so nothing set up on it before it was verified
+		// survives the owner signing in
+		if !user.EmailVerified {
+			if err := revokeUnverifiedAccountAccess(c, params, user); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := params.MongoService.LinkUserOIDCIdentity(c, user.ID, identity); err != nil {
+			return nil, err
+		}
+
+		// The provider vouches for the address so there's no need to send our own verification email
+		if !user.EmailVerified {
+			if _, err := params.MongoService.MarkUserEmailVerified(c, user.ID, user.Email); err != nil {
+				return nil, err
+			}
+			user.EmailVerified = true
+		}
+		return user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(claims.Name, " ")
+	}
+	if firstName == "" {
+		firstName = strings.Split(claims.Email, "@")[0]
+	}
+
+	newUser := models.User{
+		FirstName:      firstName,
+		LastName:       lastName,
+		Email:          claims.Email,
+		EmailVerified:  true,
+		OIDCIdentities: []models.OIDCIdentity{identity},
+	}
+
+	if err := createUserWithDefaultPlan(c, params, &newUser); err != nil {
+		return nil, err
+	}
+
+	utils.SendSlackMessage(fmt.Sprintf("New User (OIDC): %s %s (%s)", newUser.FirstName, newUser.LastName, newUser.Email))
+	return &newUser, nil
+}
+
+// revokeUnverifiedAccountAccess removes every way into an account other than the identity about to be linked:
+// its password, 2FA, sessions, API keys and any outstanding links. The owner can set a password again with a reset link.
+func revokeUnverifiedAccountAccess(c *gin.Context, params *types.RouteParams, user *models.User) error {
+	if err := params.MongoService.UpdateUserPassword(c, user.ID, ""); err != nil {
+		return err
+	}
+	if err := params.MongoService.DisableUserTwoFactor(c, user.ID); err != nil {
+		return err
+	}
+	if _, err := params.MongoService.RevokeAllUserSessions(c, user.ID); err != nil {
+		return err
+	}
+	if _, err := params.MongoService.RevokeAllUserAPIKeys(c, user.ID); err != nil {
+		return err
+	}
+	if _, err := params.MongoService.InvalidateAllUserTokens(c, user.ID); err != nil {
+		return err
+	}
+
+	user.PasswordHash = ""
+	user.TwoFactor = models.UserTwoFactor{}
+	return nil
+}
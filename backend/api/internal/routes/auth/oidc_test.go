@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"api/internal/oidc"
+	"api/internal/types"
+	"context"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockOIDCUserService only implements the user lookups and updates linking an identity provider needs,
+// it records which ways into the account were revoked
+type mockOIDCUserService struct {
+	mongodb.MongoService
+	user    models.User
+	linked  []models.OIDCIdentity
+	revoked []string
+}
+
+func (m *mockOIDCUserService) FindUserByOIDCIdentity(ctx context.Context, issuer string, subject string) (*models.User, error) {
+	for _, identity := range m.user.OIDCIdentities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			user := m.user
+			return &user, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (m *mockOIDCUserService) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if email != m.user.Email {
+		return nil, mongo.ErrNoDocuments
+	}
+	user := m.user
+	return &user, nil
+}
+
+func (m *mockOIDCUserService) LinkUserOIDCIdentity(ctx context.Context, userID primitive.ObjectID, identity models.OIDCIdentity) (*mongo.UpdateResult, error) {
+	m.linked = append(m.linked, identity)
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (m *mockOIDCUserService) MarkUserEmailVerified(ctx context.Context, userId primitive.ObjectID, email string) (*mongo.UpdateResult, error) {
+	m.user.EmailVerified = true
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (m *mockOIDCUserService) UpdateUserPassword(ctx context.Context, userId primitive.ObjectID, passwordHash string) error {
+	m.revoked = append(m.revoked, "password")
+	m.user.PasswordHash = passwordHash
+	return nil
+}
+
+func (m *mockOIDCUserService) DisableUserTwoFactor(ctx context.Context, userId primitive.ObjectID) error {
+	m.revoked = append(m.revoked, "twoFactor")
+	return nil
+}
+
+func (m *mockOIDCUserService) RevokeAllUserSessions(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.revoked = append(m.revoked, "sessions")
+	return &mongo.UpdateResult{}, nil
+}
+
+func (m *mockOIDCUserService) RevokeAllUserAPIKeys(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.revoked = append(m.revoked, "apiKeys")
+	return &mongo.UpdateResult{}, nil
+}
+
+func (m *mockOIDCUserService) InvalidateAllUserTokens(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.revoked = append(m.revoked, "tokens")
+	return &mongo.UpdateResult{}, nil
+}
+
+func TestFindOrCreateOIDCUserLinksByEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	issuer := "https://idp.example.com"
+
+	tests := []struct {
+		name          string
+		emailVerified bool
+		revoked       []string
+	}{
+		{"Verified account keeps its password", true, nil},
+		// Someone may have registered the address with their own password before its owner signed in
+		{"Unverified account loses everything set up before", false, []string{"password", "twoFactor", "sessions", "apiKeys", "tokens"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockOIDCUserService{user: models.User{
+				ID:            primitive.NewObjectID(),
+				Email:         "user@example.com",
+				EmailVerified: tt.emailVerified,
+				PasswordHash:  "hash",
+				TwoFactor:     models.UserTwoFactor{Enabled: true},
+			}}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			claims := &oidc.IDTokenClaims{
+				RegisteredClaims: jwt.RegisteredClaims{Subject: "subject"},
+				Email:            "user@example.com",
+				EmailVerified:    true,
+			}
+
+			user, err := findOrCreateOIDCUser(c, &types.RouteParams{MongoService: m}, issuer, claims)
+			assert.NoError(t, err)
+			assert.True(t, user.EmailVerified)
+			assert.Equal(t, tt.revoked, m.revoked)
+			if assert.Len(t, m.linked, 1) {
+				assert.Equal(t, "subject", m.linked[0].Subject)
+			}
+			if tt.emailVerified {
+				assert.Equal(t, "hash", user.PasswordHash)
+			} else {
+				assert.Empty(t, user.PasswordHash)
+				assert.Empty(t, m.user.PasswordHash)
+				assert.False(t, user.TwoFactor.Enabled)
+			}
+		})
+	}
+
+	t.Run("Provider hasn't verified the email", func(t *testing.T) {
+		m := &mockOIDCUserService{user: models.User{ID: primitive.NewObjectID(), Email: "user@example.com"}}
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		claims := &oidc.IDTokenClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "subject"}, Email: "user@example.com"}
+
+		_, err := findOrCreateOIDCUser(c, &types.RouteParams{MongoService: m}, issuer, claims)
+		assert.Equal(t, errOIDCEmailNotVerified, err)
+		assert.Empty(t, m.linked)
+		assert.Empty(t, m.revoked)
+	})
+}
@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"fmt"
+	"net/http"
+	"net/url"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTokenBytes = 32
+	passwordResetTokenTTL   = time.Hour
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// forgotPassword emails the user a single-use link to reset their password.
+// This always responds with the same message so it can't be used to check if an email has an account.
+func forgotPassword(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req forgotPasswordRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		successMessage := gin.H{"message": "If an account with that email exists, a password reset link has been sent to it"}
+
+		user, err := params.MongoService.FindUserByEmail(c, req.Email)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				logger.Error("Failed to find user by email", err)
+			}
+			c.JSON(http.StatusOK, successMessage)
+			return
+		}
+
+		token, err := utils.GenerateSecureToken(passwordResetTokenBytes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate reset token"})
+			return
+		}
+
+		_, err = params.MongoService.CreateUserToken(c, models.UserToken{
+			UserID:    user.ID,
+			Type:      models.UserTokenPasswordReset,
+			TokenHash: utils.HashToken(token),
+			Email:     user.Email,
+			CreatedAt: time.Now(),
+			ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+		})
+		if err != nil {
+			logger.Error("Failed to store password reset token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate reset token"})
+			return
+		}
+
+		resetLink := utils.WebsiteURL("/reset-password?token=" + url.QueryEscape(token))
+		body := fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your ApplicantAtlas account. "+
+			"If this was you, use the link below to choose a new password. This link expires in %d minutes and can only be used once.\n\n%s\n\n"+
+			"If you didn't request this you can ignore this email, your password will not change.",
+			user.FirstName, int(passwordResetTokenTTL.Minutes()), resetLink)
+
+		if err := utils.SendPlatformEmail(user.Email, "Reset your ApplicantAtlas password", body); err != nil {
+			logger.Error("Failed to send password reset email", err)
+		}
+
+		c.JSON(http.StatusOK, successMessage)
+	}
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,securepwd"`
+}
+
+// resetPassword sets a new password using a token from forgotPassword, every existing session is logged out
+func resetPassword(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req resetPasswordRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		// Hash before consuming the token so a hashing failure doesn't burn the link
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+
+		token, err := params.MongoService.ConsumeUserToken(c, utils.HashToken(req.Token), models.UserTokenPasswordReset)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This password reset link is invalid or has expired"})
+				return
+			}
+			logger.Error("Failed to consume password reset token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+			return
+		}
+
+		if err := params.MongoService.UpdateUserPassword(c, token.UserID, string(hash)); err != nil {
+			logger.Error("Failed to update password", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+			return
+		}
+
+		if _, err := params.MongoService.RevokeAllUserSessions(c, token.UserID); err != nil {
+			logger.Error("Failed to revoke sessions after password reset", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Password was reset but we failed to log out existing sessions"})
+			return
+		}
+
+		// The owner proved they have the account so a lockout from someone else's guesses shouldn't stop them logging in
+		if err := helpers.ClearAccountLoginFailures(c, params.MongoService, token.Email); err != nil {
+			logger.Error("Failed to clear failed logins after password reset", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully, please log in with your new password"})
+	}
+}
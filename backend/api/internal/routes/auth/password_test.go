@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"api/internal/types"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mockPasswordResetService only implements the token, password and session updates resetting a password needs.
+// Tokens are removed once consumed like the real ConsumeUserToken marks them used.
+type mockPasswordResetService struct {
+	mongodb.MongoService
+	tokens          map[string]models.UserToken
+	passwords       map[primitive.ObjectID]string
+	revokedFor      []primitive.ObjectID
+	revokeErr       error
+	clearedThrottle []string
+}
+
+func (m *mockPasswordResetService) ConsumeUserToken(ctx context.Context, tokenHash string, tokenType models.UserTokenType) (*models.UserToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok || token.Type != tokenType {
+		return nil, mongo.ErrNoDocuments
+	}
+	delete(m.tokens, tokenHash)
+	return &token, nil
+}
+
+func (m *mockPasswordResetService) UpdateUserPassword(ctx context.Context, userId primitive.ObjectID, passwordHash string) error {
+	m.passwords[userId] = passwordHash
+	return nil
+}
+
+func (m *mockPasswordResetService) RevokeAllUserSessions(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	if m.revokeErr != nil {
+		return nil, m.revokeErr
+	}
+	m.revokedFor = append(m.revokedFor, userID)
+	return &mongo.UpdateResult{ModifiedCount: 1}, nil
+}
+
+func (m *mockPasswordResetService) ClearLoginThrottle(ctx context.Context, kind models.LoginThrottleKind, key string) (*mongo.DeleteResult, error) {
+	m.clearedThrottle = append(m.clearedThrottle, key)
+	return &mongo.DeleteResult{}, nil
+}
+
+func TestResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+	newPassword := "Correct-horse-1!"
+
+	tests := []struct {
+		name      string
+		token     string
+		password  string
+		tokenType models.UserTokenType
+		revokeErr error
+		expected  int
+		reset     bool
+	}{
+		{"Resets the password", "reset-token", newPassword, models.UserTokenPasswordReset, nil, http.StatusOK, true},
+		{"Unknown, used or expired token", "other-token", newPassword, models.UserTokenPasswordReset, nil, http.StatusBadRequest, false},
+		{"Token of another type", "reset-token", newPassword, models.UserTokenMagicLink, nil, http.StatusBadRequest, false},
+		{"Weak password", "reset-token", "password", models.UserTokenPasswordReset, nil, http.StatusBadRequest, false},
+		{"Sessions can't be logged out", "reset-token", newPassword, models.UserTokenPasswordReset, errors.New("connection reset"), http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockPasswordResetService{
+				tokens: map[string]models.UserToken{
+					utils.HashToken("reset-token"): {UserID: userID, Type: tt.tokenType, Email: "User@Example.com", ExpiresAt: time.Now().Add(time.Hour)},
+				},
+				passwords: map[primitive.ObjectID]string{},
+				revokeErr: tt.revokeErr,
+			}
+			router := gin.New()
+			router.POST("/reset-password", resetPassword(&types.RouteParams{MongoService: m}))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/reset-password", strings.NewReader(`{"token":"`+tt.token+`","password":"`+tt.password+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			hash, reset := m.passwords[userID]
+			assert.Equal(t, tt.reset, reset)
+			if !reset {
+				return
+			}
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)))
+			if tt.expected == http.StatusOK {
+				assert.Equal(t, []primitive.ObjectID{userID}, m.revokedFor, "every session is logged out")
+				assert.Equal(t, []string{"user@example.com"}, m.clearedThrottle)
+			}
+		})
+	}
+
+	t.Run("A link only works once", func(t *testing.T) {
+		m := &mockPasswordResetService{
+			tokens: map[string]models.UserToken{
+				utils.HashToken("reset-token"): {UserID: userID, Type: models.UserTokenPasswordReset, ExpiresAt: time.Now().Add(time.Hour)},
+			},
+			passwords: map[primitive.ObjectID]string{},
+		}
+		router := gin.New()
+		router.POST("/reset-password", resetPassword(&types.RouteParams{MongoService: m}))
+
+		codes := []int{}
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/reset-password", strings.NewReader(`{"token":"reset-token","password":"`+newPassword+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			codes = append(codes, w.Code)
+		}
+		assert.Equal(t, []int{http.StatusOK, http.StatusBadRequest}, codes)
+	})
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/config"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const refreshTokenBytes = 32
+
+// startSession creates a new login session for the user and returns an access token and refresh token for it
+func startSession(c *gin.Context, params *types.RouteParams, user *models.User) (string, string, error) {
+	apiConfig, err := config.GetAPIConfig()
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := utils.GenerateSecureToken(refreshTokenBytes)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	session := models.UserSession{
+		UserID:           user.ID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		UserAgent:        c.Request.UserAgent(),
+		IPAddress:        c.ClientIP(),
+		CreatedAt:        now,
+		LastRefreshedAt:  now,
+		ExpiresAt:        now.Add(apiConfig.REFRESH_TOKEN_TTL),
+	}
+
+	res, err := params.MongoService.CreateUserSession(c, session)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := utils.GenerateJWT(user, res.InsertedID.(primitive.ObjectID))
+	if err != nil {
+		return "", "", err
+	}
+
+	// Every way of logging in ends up here, so this is where invitations to organize events get picked up
+	helpers.ClaimEventInvitations(c, params.MongoService, user)
+
+	return token, refreshToken, nil
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// refreshSession exchanges a refresh token for a new access token, the refresh token is rotated on every use
+func refreshSession(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req refreshRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Refresh token is required"})
+			return
+		}
+
+		apiConfig, err := config.GetAPIConfig()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+			return
+		}
+
+		tokenHash := utils.HashToken(req.RefreshToken)
+		session, err := params.MongoService.GetUserSessionByRefreshToken(c, tokenHash)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+				return
+			}
+			logger.Error("Failed to find session by refresh token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+			return
+		}
+
+		// A rotated refresh token being presented again means it has leaked, kill the whole session to be safe
+		if session.RefreshTokenHash != tokenHash {
+			if _, err := params.MongoService.RevokeUserSession(c, session.ID); err != nil {
+				logger.Error("Failed to revoke session after refresh token reuse", err)
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		if !session.IsActive() {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		user, err := params.MongoService.GetUserDetails(c, session.UserID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		newRefreshToken, err := utils.GenerateSecureToken(refreshTokenBytes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+			return
+		}
+
+		_, err = params.MongoService.RotateUserSessionRefreshToken(c, session.ID, tokenHash, utils.HashToken(newRefreshToken), time.Now().Add(apiConfig.REFRESH_TOKEN_TTL))
+		if err != nil {
+			if err == mongodb.ErrSessionNotActive {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+				return
+			}
+			logger.Error("Failed to rotate refresh token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+			return
+		}
+
+		token, err := utils.GenerateJWT(user, session.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"token": token, "refreshToken": newRefreshToken})
+	}
+}
+
+// logoutUser revokes the session the request was made with, or every session of the user if ?all=true
+func logoutUser(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if c.DefaultQuery("all", "false") == "true" {
+			if _, err := params.MongoService.RevokeAllUserSessions(c, authenticatedUser.ID); err != nil {
+				logger.Error("Failed to revoke all sessions", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions successfully"})
+			return
+		}
+
+		sessionID, ok := utils.GetSessionIDFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			return
+		}
+
+		if _, err := params.MongoService.RevokeUserSession(c, sessionID); err != nil {
+			logger.Error("Failed to revoke session", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to logout"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+	}
+}
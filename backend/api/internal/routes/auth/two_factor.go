@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	twoFactorLoginTokenBytes  = 32
+	twoFactorLoginTokenTTL    = 5 * time.Minute
+	twoFactorLoginMaxAttempts = 5
+)
+
+// respondWithLogin finishes the first step of a login. Users without 2FA get a session straight away,
+// users with 2FA get a short lived token to send back to /auth/login/2fa along with their code.
+func respondWithLogin(c *gin.Context, params *types.RouteParams, user *models.User) {
+	if user.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This account has been disabled, please contact support"})
+		return
+	}
+
+	if !user.TwoFactor.Enabled {
+		token, refreshToken, err := startSession(c, params, user)
+		if err != nil {
+			logger.Error("Failed to start session", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"token": token, "refreshToken": refreshToken})
+		return
+	}
+
+	twoFactorToken, err := utils.GenerateSecureToken(twoFactorLoginTokenBytes)
+	if err != nil {
+		logger.Error("Failed to generate two-factor login token", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+		return
+	}
+
+	_, err = params.MongoService.CreateUserToken(c, models.UserToken{
+		UserID:    user.ID,
+		Type:      models.UserTokenTwoFactorLogin,
+		TokenHash: utils.HashToken(twoFactorToken),
+		Email:     user.Email,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(twoFactorLoginTokenTTL),
+	})
+	if err != nil {
+		logger.Error("Failed to store two-factor login token", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"twoFactorRequired": true, "twoFactorToken": twoFactorToken})
+}
+
+type loginTwoFactorRequest struct {
+	TwoFactorToken string `json:"twoFactorToken" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// loginTwoFactor is the second step of logging in for users with 2FA enabled, it accepts a TOTP code or a recovery code
+func loginTwoFactor(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req loginTwoFactorRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		tokenHash := utils.HashToken(req.TwoFactorToken)
+		loginToken, err := params.MongoService.GetUserToken(c, tokenHash, models.UserTokenTwoFactorLogin)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Your login has expired, please log in again"})
+				return
+			}
+			logger.Error("Failed to find two-factor login token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		user, err := params.MongoService.FindUserByID(c, loginToken.UserID)
+		if err != nil {
+			logger.Error("Failed to find user for two-factor login", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		if user.Disabled {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This account has been disabled, please contact support"})
+			return
+		}
+
+		valid, err := helpers.VerifyTwoFactorCode(c, params.MongoService, user, req.Code)
+		if err != nil {
+			logger.Error("Failed to verify two-factor code", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to login"})
+			return
+		}
+
+		if !valid {
+			if err := params.MongoService.RecordFailedUserTokenAttempt(c, loginToken.ID, twoFactorLoginMaxAttempts); err != nil {
+				logger.Error("Failed to record failed two-factor attempt", err)
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor authentication code"})
+			return
+		}
+
+		// Consuming makes sure the same login token can't start two sessions
+		if _, err := params.MongoService.ConsumeUserToken(c, tokenHash, models.UserTokenTwoFactorLogin); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Your login has expired, please log in again"})
+			return
+		}
+
+		token, refreshToken, err := startSession(c, params, user)
+		if err != nil {
+			logger.Error("Failed to start session", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"token": token, "refreshToken": refreshToken})
+	}
+}
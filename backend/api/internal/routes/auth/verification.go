@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type verifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// verifyEmail marks the user's email as verified using a token from SendEmailVerification
+func verifyEmail(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req verifyEmailRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		token, err := params.MongoService.ConsumeUserToken(c, utils.HashToken(req.Token), models.UserTokenEmailVerification)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This verification link is invalid or has expired"})
+				return
+			}
+			logger.Error("Failed to consume email verification token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+
+		// The email only gets verified if the user hasn't changed it since the link was sent
+		result, err := params.MongoService.MarkUserEmailVerified(c, token.UserID, token.Email)
+		if err != nil {
+			logger.Error("Failed to mark email as verified", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This verification link is for an email address that is no longer on your account"})
+			return
+		}
+
+		// Invitations sent to the address couldn't be accepted automatically until now
+		if user, err := params.MongoService.FindUserByID(c, token.UserID); err == nil {
+			helpers.ClaimEventInvitations(c, params.MongoService, user)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+	}
+}
+
+// resendEmailVerification sends a new verification link to the authenticated user's current email
+func resendEmailVerification(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+			return
+		}
+
+		if user.EmailVerified {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Your email is already verified"})
+			return
+		}
+
+		if err := helpers.SendEmailVerification(c, params.MongoService, user); err != nil {
+			logger.Error("Failed to send verification email", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+	}
+}
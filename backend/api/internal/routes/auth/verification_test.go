@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"api/internal/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockVerificationService only implements the token and user updates verifying an email needs
+type mockVerificationService struct {
+	mongodb.MongoService
+	user           models.User
+	tokens         map[string]models.UserToken
+	created        []models.UserToken
+	invitationsFor []string
+}
+
+func (m *mockVerificationService) ConsumeUserToken(ctx context.Context, tokenHash string, tokenType models.UserTokenType) (*models.UserToken, error) {
+	token, ok := m.tokens[tokenHash]
+	if !ok || token.Type != tokenType {
+		return nil, mongo.ErrNoDocuments
+	}
+	delete(m.tokens, tokenHash)
+	return &token, nil
+}
+
+func (m *mockVerificationService) MarkUserEmailVerified(ctx context.Context, userId primitive.ObjectID, email string) (*mongo.UpdateResult, error) {
+	if userId != m.user.ID || email != m.user.Email {
+		return &mongo.UpdateResult{}, nil
+	}
+	m.user.EmailVerified = true
+	return &mongo.UpdateResult{MatchedCount: 1, ModifiedCount: 1}, nil
+}
+
+func (m *mockVerificationService) FindUserByID(ctx context.Context, userId primitive.ObjectID) (*models.User, error) {
+	if userId != m.user.ID {
+		return nil, mongo.ErrNoDocuments
+	}
+	user := m.user
+	return &user, nil
+}
+
+func (m *mockVerificationService) ListPendingEventInvitationsByEmail(ctx context.Context, email string) ([]models.EventInvitation, error) {
+	m.invitationsFor = append(m.invitationsFor, email)
+	return []models.EventInvitation{}, nil
+}
+
+func (m *mockVerificationService) CreateUserToken(ctx context.Context, token models.UserToken) (*mongo.InsertOneResult, error) {
+	m.created = append(m.created, token)
+	return &mongo.InsertOneResult{InsertedID: primitive.NewObjectID()}, nil
+}
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name       string
+		token      string
+		tokenEmail string
+		tokenType  models.UserTokenType
+		expected   int
+	}{
+		{"Verifies the email", "verify-token", "user@example.com", models.UserTokenEmailVerification, http.StatusOK},
+		{"Unknown, used or expired token", "other-token", "user@example.com", models.UserTokenEmailVerification, http.StatusBadRequest},
+		{"Token of another type", "verify-token", "user@example.com", models.UserTokenPasswordReset, http.StatusBadRequest},
+		{"Email changed since the link was sent", "verify-token", "old@example.com", models.UserTokenEmailVerification, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockVerificationService{
+				user: models.User{ID: userID, Email: "user@example.com"},
+				tokens: map[string]models.UserToken{
+					utils.HashToken("verify-token"): {UserID: userID, Type: tt.tokenType, Email: tt.tokenEmail},
+				},
+			}
+			router := gin.New()
+			router.POST("/verify-email", verifyEmail(&types.RouteParams{MongoService: m}))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/verify-email", strings.NewReader(`{"token":"`+tt.token+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			verified := tt.expected == http.StatusOK
+			assert.Equal(t, verified, m.user.EmailVerified)
+			if verified {
+				assert.Equal(t, []string{"user@example.com"}, m.invitationsFor, "invitations to the address are claimed once it's verified")
+			} else {
+				assert.Empty(t, m.invitationsFor)
+			}
+		})
+	}
+}
+
+func TestResendEmailVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name     string
+		verified bool
+		expected int
+	}{
+		// Sending the email fails without an email client, the link is still created first
+		{"Not verified", false, http.StatusInternalServerError},
+		{"Already verified", true, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockVerificationService{user: models.User{ID: userID, Email: "user@example.com", EmailVerified: tt.verified}}
+			router := gin.New()
+			router.POST("/resend", func(c *gin.Context) {
+				c.Set("user", &models.User{ID: userID})
+			}, resendEmailVerification(&types.RouteParams{MongoService: m}))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/resend", nil))
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.verified {
+				assert.Empty(t, m.created)
+				return
+			}
+			if assert.Len(t, m.created, 1) {
+				assert.Equal(t, models.UserTokenEmailVerification, m.created[0].Type)
+				assert.Equal(t, "user@example.com", m.created[0].Email, "the link is tied to the address it was sent to")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package emails
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/types"
 	"net/http"
@@ -15,10 +16,10 @@ import (
 )
 
 func RegisterEmailTemplateRoutes(r *gin.RouterGroup, params *types.RouteParams) {
-	r.GET(":template_id", middlewares.JWTAuthMiddleware(), getEmailTemplate(params))
-	r.POST("", middlewares.JWTAuthMiddleware(), createNewTemplate(params))
-	r.PUT(":template_id", middlewares.JWTAuthMiddleware(), updateTemplate(params))
-	r.DELETE(":template_id", middlewares.JWTAuthMiddleware(), deleteTemplate(params))
+	r.GET(":template_id", middlewares.JWTAuthMiddleware(params.MongoService), getEmailTemplate(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), createNewTemplate(params))
+	r.PUT(":template_id", middlewares.JWTAuthMiddleware(params.MongoService), updateTemplate(params))
+	r.DELETE(":template_id", middlewares.JWTAuthMiddleware(params.MongoService), deleteTemplate(params))
 }
 
 func getEmailTemplate(params *types.RouteParams) gin.HandlerFunc {
@@ -44,7 +45,7 @@ func getEmailTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEmailTemplate(c, params.MongoService, authenticatedUser, templateID, template) {
+		if !mongodb.UserHasEmailTemplateCapability(c, params.MongoService, authenticatedUser, templateID, template, models.CapabilityEmailTemplatesRead) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Unauthorized",
 			})
@@ -70,7 +71,7 @@ func createNewTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, template.EventID, nil) {
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, template.EventID, nil, models.CapabilityEmailTemplatesWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Unauthorized",
 			})
@@ -84,6 +85,14 @@ func createNewTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    template.EventID,
+			Action:     models.AuditEmailTemplateCreate,
+			TargetType: models.AuditTargetEmailTemplate,
+			TargetID:   templateID.InsertedID.(primitive.ObjectID).Hex(),
+			Diff:       helpers.AuditDiff(nil, template),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"id": templateID})
 	}
 }
@@ -113,7 +122,7 @@ func updateTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEmailTemplate(c, params.MongoService, authenticatedUser, templateID, emailTemplate) {
+		if !mongodb.UserHasEmailTemplateCapability(c, params.MongoService, authenticatedUser, templateID, emailTemplate, models.CapabilityEmailTemplatesWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to update this pipeline"})
 			return
 		}
@@ -131,6 +140,14 @@ func updateTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    emailTemplate.EventID,
+			Action:     models.AuditEmailTemplateUpdate,
+			TargetType: models.AuditTargetEmailTemplate,
+			TargetID:   templateID.Hex(),
+			Diff:       helpers.AuditDiff(emailTemplate, req),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Pipeline configuration updated successfully", "lastUpdatedAt": newUpdatedAt})
 	}
 }
@@ -148,7 +165,13 @@ func deleteTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEmailTemplate(c, params.MongoService, authenticatedUser, templateID, nil) {
+		emailTemplate, err := params.MongoService.GetEmailTemplate(c, templateID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Email template not found"})
+			return
+		}
+
+		if !mongodb.UserHasEmailTemplateCapability(c, params.MongoService, authenticatedUser, templateID, emailTemplate, models.CapabilityEmailTemplatesWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to delete this pipeline"})
 			return
 		}
@@ -159,6 +182,14 @@ func deleteTemplate(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    emailTemplate.EventID,
+			Action:     models.AuditEmailTemplateDelete,
+			TargetType: models.AuditTargetEmailTemplate,
+			TargetID:   templateID.Hex(),
+			Diff:       helpers.AuditDiff(emailTemplate, nil),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Pipeline deleted successfully"})
 	}
 }
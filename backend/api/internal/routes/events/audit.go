@@ -0,0 +1,51 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// List the event's audit log, newest first. It can be filtered by action, targetType, targetID, actorID
+// and an RFC3339 from/to range on when the change was made.
+func listAuditLogHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityAuditRead) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to view this event's audit log"})
+			return
+		}
+
+		query, ok := helpers.ParseAuditLogQuery(c, bson.M{"eventID": eventID})
+		if !ok {
+			return
+		}
+
+		entries, total, err := params.MongoService.ListAuditEntries(c, query.Filter, query.Options)
+		if err != nil {
+			logger.Error("Failed to list audit log entries", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get audit log"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"entries": entries, "page": query.Page, "pageSize": query.PageSize, "total": total})
+	}
+}
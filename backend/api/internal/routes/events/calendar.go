@@ -0,0 +1,43 @@
+package events
+
+import (
+	"api/internal/types"
+	"net/http"
+	"shared/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// getEventCalendarHandler returns the event as an iCalendar file so it can be added to a calendar app.
+// Anyone who can see the event can download it, the meeting URL is never included.
+func getEventCalendarHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		event, ok := getVisibleEvent(c, params, eventID)
+		if !ok {
+			return
+		}
+
+		calendarEvent, ok := utils.NewEventCalendarEvent(event, utils.WebsiteURL("/events/"+event.ID.Hex()+"/participant"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This event doesn't have a start time yet"})
+			return
+		}
+
+		calendar := utils.Calendar{
+			Name:     event.Metadata.Name,
+			Timezone: event.Metadata.Timezone,
+			Events:   []utils.CalendarEvent{calendarEvent},
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=event.ics")
+		c.Data(http.StatusOK, utils.CalendarContentType, calendar.Encode(time.Now()))
+	}
+}
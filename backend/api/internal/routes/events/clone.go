@@ -0,0 +1,267 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type cloneEventRequest struct {
+	Name      string `json:"name" validate:"omitempty,max=50"` // Defaults to the original event's name
+	ShiftDays int    `json:"shiftDays"`                        // Moves every date by this many days, 364 keeps the same weekday next year
+}
+
+// cloneEventHandler copies an event along with its forms, email templates and pipelines into a new draft event.
+// Responses, secrets, organizers and who was given access to forms are never copied.
+func cloneEventHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sourceID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		var req cloneEventRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if apiKey, ok := utils.GetAPIKeyFromContext(c); ok && apiKey.IsEventScoped() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This API key is limited to a single event and can't create events"})
+			return
+		}
+
+		source, err := params.MongoService.FindEventByID(c, sourceID)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
+			return
+		}
+
+		// Everything that gets copied has to be readable by whoever is copying it
+		for _, capability := range []models.EventCapability{models.CapabilityEventRead, models.CapabilityFormsRead, models.CapabilityEmailTemplatesRead, models.CapabilityPipelinesRead} {
+			if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, sourceID, source, capability) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to clone this event"})
+				return
+			}
+		}
+
+		forms, err := params.MongoService.ListForms(c, bson.M{"eventID": sourceID})
+		if err != nil {
+			logger.Error("Failed to list forms to clone", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone event"})
+			return
+		}
+
+		emailTemplates, err := params.MongoService.ListEmailTemplates(c, bson.M{"eventID": sourceID})
+		if err != nil {
+			logger.Error("Failed to list email templates to clone", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone event"})
+			return
+		}
+
+		pipelines, err := params.MongoService.ListPipelines(c, bson.M{"eventID": sourceID})
+		if err != nil {
+			logger.Error("Failed to list pipelines to clone", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone event"})
+			return
+		}
+
+		now := time.Now()
+		shift := time.Duration(req.ShiftDays) * 24 * time.Hour
+		eventCopy := copyEvent(source, forms, emailTemplates, pipelines, shift, now)
+		eventCopy.Event.OrganizerIDs = []primitive.ObjectID{authenticatedUser.ID}
+		eventCopy.Event.Organizers = []models.EventOrganizer{{UserID: authenticatedUser.ID, Role: models.EventRoleOwner, AddedAt: now}}
+		eventCopy.Event.CreatedByID = authenticatedUser.ID
+		if req.Name != "" {
+			eventCopy.Event.Metadata.Name = req.Name
+		}
+
+		// The copy belongs to the same organization as the original, and counts towards its subscription
+		subscriptionID, ok := reserveEventQuota(c, params, &eventCopy.Event, source.OrganizationID)
+		if !ok {
+			return
+		}
+
+		if err := params.MongoService.InsertEventCopy(c, eventCopy); err != nil {
+			logger.Error("Failed to insert cloned event", err)
+			if _, err := params.MongoService.DecrementSubscriptionEventUtilization(c, subscriptionID, eventCopy.Event.ID); err != nil {
+				logger.Error("Failed to give back the event quota of a failed clone", err)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone event"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    eventCopy.Event.ID,
+			Action:     models.AuditEventClone,
+			TargetType: models.AuditTargetEvent,
+			TargetID:   eventCopy.Event.ID.Hex(),
+			Diff:       helpers.AuditDiff(nil, eventCopy.Event.Metadata),
+		})
+
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Event cloned successfully",
+			"id":             eventCopy.Event.ID,
+			"forms":          len(eventCopy.Forms),
+			"emailTemplates": len(eventCopy.EmailTemplates),
+			"pipelines":      len(eventCopy.Pipelines),
+		})
+	}
+}
+
+// copyEvent copies an event's metadata, settings, forms, email templates and pipelines with new IDs, pointing every
+// reference between them at the copies and moving every date by shift. Who organizes the copy is left to the caller.
+func copyEvent(source *models.Event, forms []models.FormStructure, emailTemplates []models.EmailTemplate, pipelines []models.PipelineConfiguration, shift time.Duration, now time.Time) mongodb.EventCopy {
+	eventCopy := mongodb.EventCopy{
+		Event: models.Event{
+			ID:           primitive.NewObjectID(),
+			Metadata:     source.Metadata,
+			Settings:     source.Settings,
+			Status:       models.EventStatusDraft,
+			ClonedFromID: source.ID,
+		},
+		Forms:          []models.FormStructure{},
+		EmailTemplates: []models.EmailTemplate{},
+		Pipelines:      []models.PipelineConfiguration{},
+	}
+
+	metadata := &eventCopy.Event.Metadata
+	metadata.StartTime = shiftTime(metadata.StartTime, shift)
+	metadata.EndTime = shiftTime(metadata.EndTime, shift)
+	metadata.LastUpdatedAt = now
+
+	formIDs := make(map[primitive.ObjectID]primitive.ObjectID)
+	for _, form := range forms {
+		formIDs[form.ID] = primitive.NewObjectID()
+	}
+
+	eventCopy.Event.Settings.ParticipantFormID, _ = remapID(formIDs, source.Settings.ParticipantFormID)
+
+	emailTemplateIDs := make(map[primitive.ObjectID]primitive.ObjectID)
+	for _, emailTemplate := range emailTemplates {
+		emailTemplateIDs[emailTemplate.ID] = primitive.NewObjectID()
+	}
+
+	for _, form := range forms {
+		form.ID = formIDs[form.ID]
+		form.EventID = eventCopy.Event.ID
+		form.Status = "draft"
+		form.AllowedSubmitters = nil // These were given to last time's applicants
+		form.OpenSubmissionsAt = shiftTime(form.OpenSubmissionsAt, shift)
+		form.CloseSubmissionsAt = shiftTime(form.CloseSubmissionsAt, shift)
+		form.CreatedAt = now
+		form.LastUpdatedAt = now
+
+		attrs := make([]models.FormField, len(form.Attrs))
+		for i, attr := range form.Attrs {
+			attr.AdditionalValidation.DateAndTimestampFromTimeField = shiftTime(attr.AdditionalValidation.DateAndTimestampFromTimeField, shift)
+			attrs[i] = attr
+		}
+		form.Attrs = attrs
+
+		eventCopy.Forms = append(eventCopy.Forms, form)
+	}
+
+	for _, emailTemplate := range emailTemplates {
+		emailTemplate.ID = emailTemplateIDs[emailTemplate.ID]
+		emailTemplate.EventID = eventCopy.Event.ID
+		emailTemplate.DataFromFormID, _ = remapID(formIDs, emailTemplate.DataFromFormID)
+		emailTemplate.LastUpdatedAt = now
+
+		eventCopy.EmailTemplates = append(eventCopy.EmailTemplates, emailTemplate)
+	}
+
+	for _, pipeline := range pipelines {
+		pipeline.ID = primitive.NewObjectID()
+		pipeline.EventID = eventCopy.Event.ID
+		pipeline.LastUpdatedAt = now
+
+		// A reference to something that wasn't copied would point back at the original event, so it's
+		// cleared and the pipeline is turned off until an organizer fixes it
+		complete := true
+		remap := func(ids map[primitive.ObjectID]primitive.ObjectID, id primitive.ObjectID) primitive.ObjectID {
+			newID, ok := remapID(ids, id)
+			complete = complete && ok
+			return newID
+		}
+
+		if pipeline.Event.FormSubmission != nil {
+			formSubmission := *pipeline.Event.FormSubmission
+			formSubmission.OnFormID = remap(formIDs, formSubmission.OnFormID)
+			pipeline.Event.FormSubmission = &formSubmission
+		}
+		if pipeline.Event.FieldChange != nil {
+			fieldChange := *pipeline.Event.FieldChange
+			fieldChange.OnFormID = remap(formIDs, fieldChange.OnFormID)
+			pipeline.Event.FieldChange = &fieldChange
+		}
+
+		actions := make([]models.PipelineAction, len(pipeline.Actions))
+		for i, action := range pipeline.Actions {
+			action.ID = primitive.NewObjectID()
+			if action.SendEmail != nil {
+				sendEmail := *action.SendEmail
+				sendEmail.EmailTemplateID = remap(emailTemplateIDs, sendEmail.EmailTemplateID)
+				action.SendEmail = &sendEmail
+			}
+			if action.AllowFormAccess != nil {
+				allowFormAccess := *action.AllowFormAccess
+				allowFormAccess.ToFormID = remap(formIDs, allowFormAccess.ToFormID)
+				action.AllowFormAccess = &allowFormAccess
+			}
+			actions[i] = action
+		}
+		pipeline.Actions = actions
+
+		if !complete {
+			pipeline.Enabled = false
+		}
+
+		eventCopy.Pipelines = append(eventCopy.Pipelines, pipeline)
+	}
+
+	return eventCopy
+}
+
+// remapID returns the copy's ID for id, or NilObjectID and false if it wasn't copied. A NilObjectID stays as it is.
+func remapID(ids map[primitive.ObjectID]primitive.ObjectID, id primitive.ObjectID) (primitive.ObjectID, bool) {
+	if id.IsZero() {
+		return id, true
+	}
+	newID, ok := ids[id]
+	return newID, ok
+}
+
+// shiftTime moves t by shift, leaving unset times alone
+func shiftTime(t time.Time, shift time.Duration) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(shift)
+}
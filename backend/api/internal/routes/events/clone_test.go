@@ -0,0 +1,127 @@
+package events
+
+import (
+	"shared/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRemapID(t *testing.T) {
+	copied := primitive.NewObjectID()
+	copyOfCopied := primitive.NewObjectID()
+	ids := map[primitive.ObjectID]primitive.ObjectID{copied: copyOfCopied}
+
+	tests := []struct {
+		name       string
+		id         primitive.ObjectID
+		expectedID primitive.ObjectID
+		expectedOK bool
+	}{
+		{"Copied", copied, copyOfCopied, true},
+		{"Not copied", primitive.NewObjectID(), primitive.NilObjectID, false},
+		{"Unset", primitive.NilObjectID, primitive.NilObjectID, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := remapID(ids, tt.id)
+			assert.Equal(t, tt.expectedID, id)
+			assert.Equal(t, tt.expectedOK, ok)
+		})
+	}
+}
+
+func TestCopyEvent(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	shift := 364 * 24 * time.Hour
+
+	form := models.FormStructure{
+		ID:                primitive.NewObjectID(),
+		Status:            "published",
+		OpenSubmissionsAt: start,
+		AllowedSubmitters: []models.FormAllowedSubmitter{{Email: "applicant@example.com"}},
+	}
+	emailTemplate := models.EmailTemplate{ID: primitive.NewObjectID(), DataFromFormID: form.ID}
+	source := &models.Event{
+		ID:       primitive.NewObjectID(),
+		Status:   models.EventStatusPublished,
+		Metadata: models.EventMetadata{Name: "Hackathon", StartTime: start},
+		Settings: models.EventSettings{ParticipantFormID: form.ID},
+	}
+
+	complete := models.PipelineConfiguration{
+		ID:      primitive.NewObjectID(),
+		Enabled: true,
+		Event:   models.PipelineEvent{FormSubmission: &models.FormSubmission{OnFormID: form.ID}},
+		Actions: []models.PipelineAction{
+			{SendEmail: &models.SendEmail{EmailTemplateID: emailTemplate.ID}},
+			{AllowFormAccess: &models.AllowFormAccess{ToFormID: form.ID}},
+		},
+	}
+	// Sends an email template that belongs to another event, so it can't be pointed at a copy
+	incomplete := models.PipelineConfiguration{
+		ID:      primitive.NewObjectID(),
+		Enabled: true,
+		Event:   models.PipelineEvent{FormSubmission: &models.FormSubmission{OnFormID: form.ID}},
+		Actions: []models.PipelineAction{{SendEmail: &models.SendEmail{EmailTemplateID: primitive.NewObjectID()}}},
+	}
+
+	eventCopy := copyEvent(source, []models.FormStructure{form}, []models.EmailTemplate{emailTemplate}, []models.PipelineConfiguration{complete, incomplete}, shift, now)
+
+	t.Run("Event", func(t *testing.T) {
+		event := eventCopy.Event
+		assert.NotEqual(t, source.ID, event.ID)
+		assert.Equal(t, source.ID, event.ClonedFromID)
+		assert.Equal(t, models.EventStatusDraft, event.Status)
+		assert.Equal(t, start.Add(shift), event.Metadata.StartTime)
+		assert.True(t, event.Metadata.EndTime.IsZero(), "unset times stay unset")
+		assert.Equal(t, eventCopy.Forms[0].ID, event.Settings.ParticipantFormID)
+		assert.Empty(t, event.Organizers, "organizers are left to the caller")
+	})
+
+	t.Run("Forms", func(t *testing.T) {
+		if !assert.Len(t, eventCopy.Forms, 1) {
+			return
+		}
+		copied := eventCopy.Forms[0]
+		assert.NotEqual(t, form.ID, copied.ID)
+		assert.Equal(t, eventCopy.Event.ID, copied.EventID)
+		assert.Equal(t, "draft", copied.Status)
+		assert.Nil(t, copied.AllowedSubmitters)
+		assert.Equal(t, start.Add(shift), copied.OpenSubmissionsAt)
+		assert.Len(t, form.AllowedSubmitters, 1, "the source form isn't changed")
+	})
+
+	t.Run("Email templates", func(t *testing.T) {
+		if !assert.Len(t, eventCopy.EmailTemplates, 1) {
+			return
+		}
+		copied := eventCopy.EmailTemplates[0]
+		assert.NotEqual(t, emailTemplate.ID, copied.ID)
+		assert.Equal(t, eventCopy.Event.ID, copied.EventID)
+		assert.Equal(t, eventCopy.Forms[0].ID, copied.DataFromFormID)
+	})
+
+	t.Run("Pipelines", func(t *testing.T) {
+		if !assert.Len(t, eventCopy.Pipelines, 2) {
+			return
+		}
+
+		copied := eventCopy.Pipelines[0]
+		assert.NotEqual(t, complete.ID, copied.ID)
+		assert.True(t, copied.Enabled)
+		assert.Equal(t, eventCopy.Forms[0].ID, copied.Event.FormSubmission.OnFormID)
+		assert.Equal(t, eventCopy.EmailTemplates[0].ID, copied.Actions[0].SendEmail.EmailTemplateID)
+		assert.Equal(t, eventCopy.Forms[0].ID, copied.Actions[1].AllowFormAccess.ToFormID)
+		assert.Equal(t, form.ID, complete.Event.FormSubmission.OnFormID, "the source pipeline isn't changed")
+
+		copied = eventCopy.Pipelines[1]
+		assert.False(t, copied.Enabled, "pipelines pointing outside the copy are turned off")
+		assert.True(t, copied.Actions[0].SendEmail.EmailTemplateID.IsZero())
+		assert.Equal(t, eventCopy.Forms[0].ID, copied.Event.FormSubmission.OnFormID)
+	})
+}
@@ -1,65 +1,180 @@
 package events
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/routes/events/secrets"
 	"api/internal/types"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"shared/logger"
 	"shared/messages"
 	"shared/models"
 	"shared/mongodb"
 	"shared/utils"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // RegisterRoutes sets up the routes for event management
 func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
 	r.GET("", listEventsHandler(params))
-	r.POST("", middlewares.JWTAuthMiddleware(), createEventHandler(params))
-	r.GET("my-events", middlewares.JWTAuthMiddleware(), listMyEventsHandler(params))
-	r.PUT(":event_id", middlewares.JWTAuthMiddleware(), updateEventHandler(params))
-	r.DELETE(":event_id", middlewares.JWTAuthMiddleware(), deleteEventHandler(params))
-	r.GET(":event_id", getEventHandler(params))
-	r.GET(":event_id/forms", middlewares.JWTAuthMiddleware(), getEventFormsHandler(params))
-	r.GET(":event_id/pipelines", middlewares.JWTAuthMiddleware(), getEventPipelinesHandler(params))
-	r.GET(":event_id/email_templates", middlewares.JWTAuthMiddleware(), getEventEmailTemplatesHandler(params))
-	r.POST(":event_id/organizers/:user_email", middlewares.JWTAuthMiddleware(), addOrganizerHandler(params))
-	r.DELETE(":event_id/organizers/:user_id", middlewares.JWTAuthMiddleware(), removeOrganizerHandler(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), createEventHandler(params))
+	r.GET("my-events", middlewares.JWTAuthMiddleware(params.MongoService), listMyEventsHandler(params))
+	r.PUT(":event_id", middlewares.JWTAuthMiddleware(params.MongoService), updateEventHandler(params))
+	r.PUT(":event_id/settings", middlewares.JWTAuthMiddleware(params.MongoService), updateEventSettingsHandler(params))
+	r.DELETE(":event_id", middlewares.JWTAuthMiddleware(params.MongoService), deleteEventHandler(params))
+	r.GET(":event_id", middlewares.OptionalAuthMiddleware(params.MongoService), getEventHandler(params))
+	r.GET(":event_id/calendar.ics", middlewares.OptionalAuthMiddleware(params.MongoService), getEventCalendarHandler(params))
+	r.GET(":event_id/forms", middlewares.JWTAuthMiddleware(params.MongoService), getEventFormsHandler(params))
+	r.GET(":event_id/pipelines", middlewares.JWTAuthMiddleware(params.MongoService), getEventPipelinesHandler(params))
+	r.GET(":event_id/email_templates", middlewares.JWTAuthMiddleware(params.MongoService), getEventEmailTemplatesHandler(params))
+	r.GET(":event_id/trash", middlewares.JWTAuthMiddleware(params.MongoService), listTrashHandler(params))
+	r.POST(":event_id/trash/:kind/:item_id/restore", middlewares.JWTAuthMiddleware(params.MongoService), restoreFromTrashHandler(params))
+	r.POST(":event_id/organizers/:user_email", middlewares.JWTAuthMiddleware(params.MongoService), addOrganizerHandler(params))
+	r.PUT(":event_id/organizers/:user_id", middlewares.JWTAuthMiddleware(params.MongoService), updateOrganizerHandler(params))
+	r.DELETE(":event_id/organizers/:user_id", middlewares.JWTAuthMiddleware(params.MongoService), removeOrganizerHandler(params))
+	r.GET(":event_id/invitations", middlewares.JWTAuthMiddleware(params.MongoService), listInvitationsHandler(params))
+	r.DELETE(":event_id/invitations/:invitation_id", middlewares.JWTAuthMiddleware(params.MongoService), revokeInvitationHandler(params))
+	r.POST("invitations/accept", middlewares.JWTAuthMiddleware(params.MongoService), acceptInvitationHandler(params))
+	r.GET(":event_id/transfers", middlewares.JWTAuthMiddleware(params.MongoService), listOwnershipTransfersHandler(params))
+	r.POST(":event_id/transfer", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware(), requestOwnershipTransferHandler(params))
+	r.DELETE(":event_id/transfer", middlewares.JWTAuthMiddleware(params.MongoService), cancelOwnershipTransferHandler(params))
+	r.POST(":event_id/transfer/accept", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware(), acceptOwnershipTransferHandler(params))
+	r.POST(":event_id/transfer/decline", middlewares.JWTAuthMiddleware(params.MongoService), declineOwnershipTransferHandler(params))
+	r.GET(":event_id/audit", middlewares.JWTAuthMiddleware(params.MongoService), listAuditLogHandler(params))
+	r.POST(":event_id/publish", middlewares.JWTAuthMiddleware(params.MongoService), publishEventHandler(params))
+	r.POST(":event_id/archive", middlewares.JWTAuthMiddleware(params.MongoService), archiveEventHandler(params))
+	r.POST(":event_id/cancel", middlewares.JWTAuthMiddleware(params.MongoService), cancelEventHandler(params))
+	r.POST(":event_id/clone", middlewares.JWTAuthMiddleware(params.MongoService), cloneEventHandler(params))
+	r.GET(":event_id/schedule", middlewares.OptionalAuthMiddleware(params.MongoService), listScheduleHandler(params))
+	r.POST(":event_id/schedule", middlewares.JWTAuthMiddleware(params.MongoService), createScheduleItemHandler(params))
+	r.PUT(":event_id/schedule/:item_id", middlewares.JWTAuthMiddleware(params.MongoService), updateScheduleItemHandler(params))
+	r.DELETE(":event_id/schedule/:item_id", middlewares.JWTAuthMiddleware(params.MongoService), deleteScheduleItemHandler(params))
+	r.POST(":event_id/schedule/:item_id/register", middlewares.JWTAuthMiddleware(params.MongoService), registerForScheduleItemHandler(params, true))
+	r.DELETE(":event_id/schedule/:item_id/register", middlewares.JWTAuthMiddleware(params.MongoService), registerForScheduleItemHandler(params, false))
 
 	// Register the secrets routes
 	secrets.RegisterRoutes(r.Group(":event_id/secrets"), params)
 }
 
+const (
+	eventDirectoryDefaultLimit    = 20
+	eventDirectoryMaxLimit        = 100
+	eventDirectoryDefaultRadiusKm = 50
+	eventDirectoryMaxRadiusKm     = 1000
+)
+
+// List the public event directory, only published public events are listed. Events can be searched with ?q=, filtered by an RFC3339 from/to range on their
+// start time, ?timezone=, comma separated ?tags=, ?locationType=, ?country=, ?city= and ?near=latitude,longitude within ?radiusKm=,
+// and sorted with ?sort=. Pages are fetched with the nextCursor of the previous page, which is empty once there are no more events.
 func listEventsHandler(params *types.RouteParams) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		/*
-			TODO:
-			* Pagination
-			* Filtering by date
-			* Filtering by address
-			* Sort by date desc
-			* Only for visible events
-			* We need to ensure certain fields dropped if admin or not like on the get method
-		*/
-		events, err := params.MongoService.ListEventsMetadata(c, nil)
+		opts := mongodb.EventListOptions{
+			Status:       models.EventStatusPublished,
+			Visibility:   models.EventVisibilityPublic,
+			Timezone:     c.Query("timezone"),
+			LocationType: models.EventLocationType(c.Query("locationType")),
+			Country:      strings.TrimSpace(c.Query("country")),
+			City:         strings.TrimSpace(c.Query("city")),
+			Search:       strings.TrimSpace(c.Query("q")),
+			Sort:         mongodb.EventSort(c.DefaultQuery("sort", string(mongodb.EventSortStartTime))),
+			Cursor:       c.Query("cursor"),
+			Limit:        eventDirectoryDefaultLimit,
+		}
+
+		if !mongodb.IsValidEventSort(opts.Sort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+			return
+		}
+
+		for _, tag := range strings.Split(c.Query("tags"), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				opts.Tags = append(opts.Tags, tag)
+			}
+		}
+
+		if opts.LocationType != "" && !opts.LocationType.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location type"})
+			return
+		}
+
+		if near := c.Query("near"); near != "" {
+			coordinates, ok := parseCoordinates(near)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid near, it must be latitude,longitude"})
+				return
+			}
+			opts.Near = coordinates
+			opts.RadiusKm = eventDirectoryDefaultRadiusKm
+			if radius, err := strconv.ParseFloat(c.Query("radiusKm"), 64); err == nil && radius > 0 && radius <= eventDirectoryMaxRadiusKm {
+				opts.RadiusKm = radius
+			}
+		}
+
+		for param, t := range map[string]*time.Time{"from": &opts.StartsAfter, "to": &opts.StartsBefore} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " time, it must be RFC3339"})
+				return
+			}
+			*t = parsed
+		}
+
+		if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= eventDirectoryMaxLimit {
+			opts.Limit = int64(limit)
+		}
+
+		events, nextCursor, err := params.MongoService.ListEventsMetadata(c, opts)
 		if err != nil {
+			if errors.Is(err, mongodb.ErrInvalidEventCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+				return
+			}
+			logger.Error("Failed to list events", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list events"})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"events": events})
+		c.JSON(http.StatusOK, gin.H{"events": events, "nextCursor": nextCursor})
 	}
 }
 
+// parseCoordinates reads "latitude,longitude"
+func parseCoordinates(value string) (*models.Coordinates, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, false
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, false
+	}
+
+	return &models.Coordinates{Latitude: latitude, Longitude: longitude}, true
+}
+
 type createEventRequest struct {
-	Name string `json:"name" validate:"required"`
+	Name           string             `json:"name" validate:"required"`
+	OrganizationID primitive.ObjectID `json:"organizationID"` // Optional, the organization owns the event and pays for it instead of the user
 }
 
 func createEventHandler(params *types.RouteParams) gin.HandlerFunc {
@@ -86,54 +201,100 @@ func createEventHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		if apiKey, ok := utils.GetAPIKeyFromContext(c); ok && apiKey.IsEventScoped() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This API key is limited to a single event and can't create events"})
+			return
+		}
+
 		lastUpdatedAt := time.Now()
 		event := models.Event{
 			Metadata: models.EventMetadata{
 				Name:          req.Name,
+				Visibility:    models.EventVisibilityUnlisted,
 				LastUpdatedAt: lastUpdatedAt,
 			},
+			Status:       models.EventStatusDraft, // Hidden until it's set up and published
 			OrganizerIDs: []primitive.ObjectID{authenticatedUser.ID},
+			Organizers:   []models.EventOrganizer{{UserID: authenticatedUser.ID, Role: models.EventRoleOwner, AddedAt: lastUpdatedAt}},
 			CreatedByID:  authenticatedUser.ID,
 		}
 
-		// Check billing
-		u, err := params.MongoService.GetUserDetails(c, authenticatedUser.ID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		if _, ok := reserveEventQuota(c, params, &event, req.OrganizationID); !ok {
 			return
 		}
 
-		if u.CurrentSubscriptionID == primitive.NilObjectID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User does not have a subscription"})
+		// Insert the new event into the database
+		rec, err := params.MongoService.CreateEvent(c, event)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
 			return
 		}
 
-		sub, err := params.MongoService.GetSubscription(c, u.CurrentSubscriptionID)
+		eventID := rec.InsertedID.(primitive.ObjectID)
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    eventID,
+			Action:     models.AuditEventCreate,
+			TargetType: models.AuditTargetEvent,
+			TargetID:   eventID.Hex(),
+			Diff:       helpers.AuditDiff(nil, event.Metadata),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Event created successfully", "id": rec.InsertedID})
+	}
+}
+
+// reserveEventQuota counts a new event towards whoever pays for it, an organization if organizationID is set and
+// otherwise the event's creator. The event is given to the organization. The response is written if it can't be created.
+func reserveEventQuota(c *gin.Context, params *types.RouteParams, event *models.Event, organizationID primitive.ObjectID) (primitive.ObjectID, bool) {
+	var subscriptionID primitive.ObjectID
+	if !organizationID.IsZero() {
+		organization, err := params.MongoService.GetOrganization(c, organizationID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription"})
-			return
+			c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+			return primitive.NilObjectID, false
 		}
 
-		if sub.Status != models.SubscriptionStatusActive {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User subscription is not active"})
-			return
+		if _, ok := organization.GetMember(event.CreatedByID); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not a member of this organization"})
+			return primitive.NilObjectID, false
 		}
 
-		_, err = params.MongoService.IncrementSubscriptionUtilization(c, sub.ID, "eventsCreated", "maxEvents")
+		// The organization owns the event, so its creator is only an admin of it
+		event.OrganizationID = organization.ID
+		event.Organizers[0].Role = models.EventRoleAdmin
+		subscriptionID = organization.CurrentSubscriptionID
+	} else {
+		u, err := params.MongoService.GetUserDetails(c, event.CreatedByID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Event creation limit reached, please upgrade your subscription"})
-			return
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+			return primitive.NilObjectID, false
 		}
+		subscriptionID = u.CurrentSubscriptionID
+	}
 
-		// Insert the new event into the database
-		rec, err := params.MongoService.CreateEvent(c, event)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
-			return
-		}
+	if subscriptionID == primitive.NilObjectID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User does not have a subscription"})
+		return primitive.NilObjectID, false
+	}
 
-		c.JSON(http.StatusOK, gin.H{"message": "Event created successfully", "id": rec.InsertedID})
+	sub, err := params.MongoService.GetSubscription(c, subscriptionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription"})
+		return primitive.NilObjectID, false
+	}
+
+	if sub.Status != models.SubscriptionStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User subscription is not active"})
+		return primitive.NilObjectID, false
 	}
+
+	_, err = params.MongoService.IncrementSubscriptionUtilization(c, sub.ID, "eventsCreated", "maxEvents")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Event creation limit reached, please upgrade your subscription"})
+		return primitive.NilObjectID, false
+	}
+
+	return sub.ID, true
 }
 
 type updateEventMetadataRequest struct {
@@ -162,7 +323,7 @@ func updateEventHandler(params *types.RouteParams) gin.HandlerFunc {
 		}
 
 		// Pull the current event from the database to compare the last updated time
-		event, err := params.MongoService.GetEvent(c, objID)
+		event, err := params.MongoService.FindEventByID(c, objID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
 			return
@@ -173,6 +334,14 @@ func updateEventHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		// A published event has to keep what it needed to be published
+		if event.GetStatus() == models.EventStatusPublished {
+			if problems := req.Metadata.PublishProblems(); len(problems) > 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(problems, "\n")})
+				return
+			}
+		}
+
 		// Update the event
 		newLastUpdatedAt := time.Now()
 		req.Metadata.LastUpdatedAt = newLastUpdatedAt
@@ -186,10 +355,90 @@ func updateEventHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    objID,
+			Action:     models.AuditEventUpdate,
+			TargetType: models.AuditTargetEvent,
+			TargetID:   objID.Hex(),
+			Diff:       helpers.AuditDiff(event.Metadata, req.Metadata),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully", "lastUpdatedAt": newLastUpdatedAt})
 	}
 }
 
+// updateEventSettingsHandler updates an event's organizer-only settings, settings left out of the request are kept
+func updateEventSettingsHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, objID, nil, models.CapabilitySettingsWrite) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to change this event's settings"})
+			return
+		}
+
+		previousSettings, err := params.MongoService.GetEventSettings(c, objID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event settings"})
+			return
+		}
+
+		// Decoded over the current settings so clients that don't know about newer settings don't reset them
+		req := *previousSettings
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Don't let an organizer lock themselves out of the responses
+		if req.RequireOrganizerTwoFactor && !previousSettings.RequireOrganizerTwoFactor {
+			user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+				return
+			}
+
+			if !user.TwoFactor.Enabled {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "You must enable two-factor authentication on your account before requiring it for organizers"})
+				return
+			}
+		}
+
+		if !req.ParticipantFormID.IsZero() && req.ParticipantFormID != previousSettings.ParticipantFormID {
+			form, err := params.MongoService.GetForm(c, req.ParticipantFormID, false)
+			if err != nil || form.EventID != objID {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "The participant form must be one of this event's forms"})
+				return
+			}
+		}
+
+		_, err = params.MongoService.UpdateEventSettings(c, objID, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event settings"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    objID,
+			Action:     models.AuditEventSettingsUpdate,
+			TargetType: models.AuditTargetEvent,
+			TargetID:   objID.Hex(),
+			Diff:       helpers.AuditDiff(previousSettings, req),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Event settings updated successfully"})
+	}
+}
+
 func listMyEventsHandler(params *types.RouteParams) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authenticatedUser, ok := utils.GetUserFromContext(c, true)
@@ -204,7 +453,12 @@ func listMyEventsHandler(params *types.RouteParams) gin.HandlerFunc {
 		}
 
 		// List all events where the user is an organizer
-		events, err := params.MongoService.ListEventsMetadata(c, bson.M{"organizerIDs": authenticatedUser.ID})
+		opts := mongodb.EventListOptions{OrganizerID: authenticatedUser.ID}
+		if apiKey, ok := utils.GetAPIKeyFromContext(c); ok && apiKey.IsEventScoped() {
+			opts.IDs = []primitive.ObjectID{apiKey.EventID}
+		}
+
+		events, _, err := params.MongoService.ListEventsMetadata(c, opts)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list events"})
 			return
@@ -225,16 +479,30 @@ func deleteEventHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		_, err = params.MongoService.DeleteEvent(c, objID)
+		// Forms, responses, pipelines and everything else that belongs to the event go with it
+		event, err := params.MongoService.DeleteEvent(c, objID)
 		if err != nil {
-			if err == mongodb.ErrUserNotAuthenticated {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "User not an organizer of this event"})
+			if err == mongodb.ErrUserNotAuthenticated || err == mongodb.ErrUserNotAuthorized {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Only the owner of this event can delete it"})
+				return
+			}
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 				return
 			}
+			logger.Error("Failed to delete event", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    objID,
+			Action:     models.AuditEventDelete,
+			TargetType: models.AuditTargetEvent,
+			TargetID:   objID.Hex(),
+			Diff:       helpers.AuditDiff(event.Metadata, nil),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
 	}
 }
@@ -252,10 +520,33 @@ func getEventHandler(params *types.RouteParams) gin.HandlerFunc {
 
 		event, err := params.MongoService.GetEvent(c, objID)
 		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
 			return
 		}
 
+		// Only organizers see drafts and private events, everyone else can't tell they exist
+		user, _ := utils.GetUserFromContext(c, false)
+		isOrganizer := mongodb.UserHasEventCapability(c, params.MongoService, user, objID, nil, models.CapabilityEventRead)
+		if !isOrganizer && !event.IsVisibleToPublic() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+
+		// The meeting URL is kept from everyone but organizers and accepted participants
+		venue := event.Metadata.Venue
+		if !isOrganizer && venue != nil && venue.Type != models.EventLocationInPerson && helpers.IsEventParticipant(c, params.MongoService, objID) {
+			fullVenue, err := params.MongoService.GetEventVenue(c, objID)
+			if err != nil {
+				logger.Error("Failed to get event venue", err)
+			} else {
+				event.Metadata.Venue = fullVenue
+			}
+		}
+
 		c.JSON(http.StatusOK, gin.H{"event": event})
 	}
 }
@@ -274,8 +565,8 @@ func getEventFormsHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, eventID, nil) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to modify this event"})
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityFormsRead) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to view this event's forms"})
 			return
 		}
 
@@ -304,8 +595,8 @@ func getEventPipelinesHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, eventID, nil) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to modify this event"})
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityPipelinesRead) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to view this event's pipelines"})
 			return
 		}
 
@@ -334,8 +625,8 @@ func getEventEmailTemplatesHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, eventID, nil) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to modify this event"})
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityEmailTemplatesRead) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to view this event's email templates"})
 			return
 		}
 
@@ -350,7 +641,58 @@ func getEventEmailTemplatesHandler(params *types.RouteParams) gin.HandlerFunc {
 	}
 }
 
-// Add organizer to event
+type organizerRoleRequest struct {
+	Role         models.EventRole         `json:"role"`
+	Capabilities []models.EventCapability `json:"capabilities"` // Only for the custom role
+}
+
+// toOrganizer validates the requested role and builds the organizer, the role defaults to admin
+func (req organizerRoleRequest) toOrganizer(userID primitive.ObjectID) (models.EventOrganizer, error) {
+	if req.Role == "" {
+		req.Role = models.EventRoleAdmin
+	}
+
+	if !models.IsValidEventRole(req.Role) {
+		return models.EventOrganizer{}, fmt.Errorf("invalid role %q", req.Role)
+	}
+
+	organizer := models.EventOrganizer{UserID: userID, Role: req.Role, AddedAt: time.Now()}
+	if req.Role != models.EventRoleCustom {
+		if len(req.Capabilities) > 0 {
+			return models.EventOrganizer{}, errors.New("capabilities can only be set for the custom role")
+		}
+		return organizer, nil
+	}
+
+	if len(req.Capabilities) == 0 {
+		return models.EventOrganizer{}, errors.New("the custom role needs at least one capability")
+	}
+	for _, capability := range req.Capabilities {
+		if !models.IsValidEventCapability(capability) {
+			return models.EventOrganizer{}, fmt.Errorf("invalid capability %q", capability)
+		}
+	}
+	organizer.Capabilities = req.Capabilities
+	return organizer, nil
+}
+
+// canGrantOrganizer stops organizers from giving anyone, including themselves, more than they can already do.
+// Owners and admins of the event's organization can grant anything, like its owner.
+func canGrantOrganizer(c *gin.Context, m mongodb.MongoService, event *models.Event, user *models.User, organizer models.EventOrganizer) bool {
+	granter, ok := mongodb.GetEventOrganizer(c, m, user, event)
+	if !ok {
+		return false
+	}
+
+	for _, capability := range organizer.GetCapabilities() {
+		if !granter.HasCapability(capability) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add organizer to event, the body can optionally set their role
 func addOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		eventID := c.Param("event_id")
@@ -363,38 +705,162 @@ func addOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		var req organizerRoleRequest
+		if c.Request.ContentLength > 0 {
+			if err := utils.BindJSON(c, &req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		authenticatedUser, ok := utils.GetUserFromContext(c, true)
 		if !ok {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, objID, nil) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to modify this event"})
+		event, err := params.MongoService.FindEventByID(c, objID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, objID, event, models.CapabilityOrganizersWrite) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to manage this event's organizers"})
 			return
 		}
 
-		// Get user by email
-		user, err := params.MongoService.FindUserByEmail(c, userEmail)
+		organizer, err := req.toOrganizer(primitive.NilObjectID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find a user with that email"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
-		if user == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found, please ensure that user has an account"})
+		if !canGrantOrganizer(c, params.MongoService, event, authenticatedUser, organizer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can't give an organizer capabilities you don't have"})
+			return
+		}
+
+		// Get user by email, people without an account are invited instead
+		user, err := params.MongoService.FindUserByEmail(c, userEmail)
+		if err == mongo.ErrNoDocuments {
+			inviteOrganizer(c, params, event, authenticatedUser, userEmail, organizer)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find a user with that email"})
 			return
 		}
+		organizer.UserID = user.ID
 
-		_, err = params.MongoService.AddOrganizerToEvent(c, objID, user.ID)
+		result, err := params.MongoService.AddOrganizerToEvent(c, objID, organizer)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add organizer to event"})
 			return
 		}
 
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "That user is already an organizer of this event"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    objID,
+			Action:     models.AuditOrganizerAdd,
+			TargetType: models.AuditTargetOrganizer,
+			TargetID:   user.ID.Hex(),
+			Diff:       helpers.AuditDiff(nil, organizer),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"userID": user.ID, "message": "Organizer added to event successfully"})
 	}
 }
 
+// Change an organizer's role, the owner's role can only change by transferring the event
+func updateOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		objID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		userObjID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var req organizerRoleRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		event, err := params.MongoService.FindEventByID(c, objID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, objID, event, models.CapabilityOrganizersWrite) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to manage this event's organizers"})
+			return
+		}
+
+		current, ok := event.GetOrganizer(userObjID)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "That user is not an organizer of this event"})
+			return
+		}
+
+		if current.Role == models.EventRoleOwner {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The owner's role can't be changed, transfer the event instead"})
+			return
+		}
+
+		// Otherwise an organizer with a limited role could demote someone who can do more than them
+		if !canGrantOrganizer(c, params.MongoService, event, authenticatedUser, current) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can't change the role of an organizer who has capabilities you don't have"})
+			return
+		}
+
+		organizer, err := req.toOrganizer(userObjID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !current.AddedAt.IsZero() {
+			organizer.AddedAt = current.AddedAt
+		}
+
+		if !canGrantOrganizer(c, params.MongoService, event, authenticatedUser, organizer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can't give an organizer capabilities you don't have"})
+			return
+		}
+
+		_, err = params.MongoService.UpdateEventOrganizer(c, objID, organizer)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organizer"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    objID,
+			Action:     models.AuditOrganizerUpdate,
+			TargetType: models.AuditTargetOrganizer,
+			TargetID:   userObjID.Hex(),
+			Diff:       helpers.AuditDiff(current, organizer),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"organizer": organizer, "message": "Organizer updated successfully"})
+	}
+}
+
 // Remove organizer from event
 func removeOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -413,11 +879,6 @@ func removeOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, objID, nil) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to modify this event"})
-			return
-		}
-
 		// Convert userID to ObjectID
 		userObjID, err := primitive.ObjectIDFromHex(userID)
 		if err != nil {
@@ -426,14 +887,25 @@ func removeOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
 		}
 
 		// Get the event to make sure there are still > 1 organizers
-		event, err := params.MongoService.GetEvent(c, objID)
+		event, err := params.MongoService.FindEventByID(c, objID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
 			return
 		}
 
-		if event.CreatedByID == userObjID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove the creator of the event as an admin, you must delete the event instead"})
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, objID, event, models.CapabilityOrganizersWrite) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to manage this event's organizers"})
+			return
+		}
+
+		organizer, isOrganizer := event.GetOrganizer(userObjID)
+		if isOrganizer && organizer.Role == models.EventRoleOwner {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove the owner of the event, transfer or delete the event instead"})
+			return
+		}
+
+		if isOrganizer && !canGrantOrganizer(c, params.MongoService, event, authenticatedUser, organizer) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can't remove an organizer who has capabilities you don't have"})
 			return
 		}
 
@@ -448,6 +920,16 @@ func removeOrganizerHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		if isOrganizer {
+			helpers.Audit(c, params.MongoService, models.AuditEntry{
+				EventID:    objID,
+				Action:     models.AuditOrganizerRemove,
+				TargetType: models.AuditTargetOrganizer,
+				TargetID:   userObjID.Hex(),
+				Diff:       helpers.AuditDiff(organizer, nil),
+			})
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "Organizer removed from event successfully"})
 	}
 }
@@ -0,0 +1,168 @@
+package events
+
+import (
+	"api/internal/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockOrganizationService only implements the organization lookup granting organizers needs
+type mockOrganizationService struct {
+	mongodb.MongoService
+	organization *models.Organization
+}
+
+func (m *mockOrganizationService) GetOrganization(ctx context.Context, organizationID primitive.ObjectID) (*models.Organization, error) {
+	if m.organization == nil || m.organization.ID != organizationID {
+		return nil, mongo.ErrNoDocuments
+	}
+	return m.organization, nil
+}
+
+// mockEventReadService only implements the event lookups showing an event needs. Its GetEvent never hides the
+// organizers, so whether someone counts as an organizer can only come from their role.
+type mockEventReadService struct {
+	mongodb.MongoService
+	event *models.Event
+}
+
+func (m *mockEventReadService) GetEvent(ctx *gin.Context, eventID primitive.ObjectID) (*models.Event, error) {
+	return m.FindEventByID(ctx, eventID)
+}
+
+func (m *mockEventReadService) FindEventByID(ctx context.Context, eventID primitive.ObjectID) (*models.Event, error) {
+	if m.event.ID != eventID {
+		return nil, mongo.ErrNoDocuments
+	}
+	event := *m.event
+	return &event, nil
+}
+
+// hiddenEventTests are the drafts and private events only organizers who can read the event may see
+func hiddenEventTests() (*models.Event, []struct {
+	name     string
+	user     *models.User
+	expected int
+}) {
+	viewer := &models.User{ID: primitive.NewObjectID()}
+	custom := &models.User{ID: primitive.NewObjectID()}
+	event := &models.Event{
+		ID:           primitive.NewObjectID(),
+		Status:       models.EventStatusDraft,
+		OrganizerIDs: []primitive.ObjectID{viewer.ID, custom.ID},
+		Organizers: []models.EventOrganizer{
+			{UserID: viewer.ID, Role: models.EventRoleViewer},
+			{UserID: custom.ID, Role: models.EventRoleCustom, Capabilities: []models.EventCapability{models.CapabilityFormsRead}},
+		},
+	}
+
+	return event, []struct {
+		name     string
+		user     *models.User
+		expected int
+	}{
+		{"Organizer who can read the event", viewer, http.StatusOK},
+		{"Organizer without event:read", custom, http.StatusNotFound},
+		{"Stranger", &models.User{ID: primitive.NewObjectID()}, http.StatusNotFound},
+		{"Signed out", nil, http.StatusNotFound},
+	}
+}
+
+func TestGetEventHidesDrafts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	event, tests := hiddenEventTests()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/events/:event_id", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set("user", tt.user)
+				}
+			}, getEventHandler(&types.RouteParams{MongoService: &mockEventReadService{event: event}}))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/events/"+event.ID.Hex(), nil))
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestGetVisibleEventHidesDrafts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	event, tests := hiddenEventTests()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			_, ok := getVisibleEvent(c, &types.RouteParams{MongoService: &mockEventReadService{event: event}}, event.ID)
+			assert.Equal(t, tt.expected == http.StatusOK, ok)
+			if !ok {
+				assert.Equal(t, tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestCanGrantOrganizer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	admin := &models.User{ID: primitive.NewObjectID()}
+	reviewer := &models.User{ID: primitive.NewObjectID()}
+	orgAdmin := &models.User{ID: primitive.NewObjectID()}
+	orgMember := &models.User{ID: primitive.NewObjectID()}
+	stranger := &models.User{ID: primitive.NewObjectID()}
+
+	organization := &models.Organization{
+		ID: primitive.NewObjectID(),
+		Members: []models.OrganizationMember{
+			{UserID: orgAdmin.ID, Role: models.OrganizationRoleAdmin},
+			{UserID: orgMember.ID, Role: models.OrganizationRoleMember},
+		},
+	}
+	event := &models.Event{
+		OrganizationID: organization.ID,
+		OrganizerIDs:   []primitive.ObjectID{admin.ID, reviewer.ID},
+		Organizers: []models.EventOrganizer{
+			{UserID: admin.ID, Role: models.EventRoleAdmin},
+			{UserID: reviewer.ID, Role: models.EventRoleReviewer},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		user      *models.User
+		organizer models.EventOrganizer
+		expected  bool
+	}{
+		{"Admin grants admin", admin, models.EventOrganizer{Role: models.EventRoleAdmin}, true},
+		{"Reviewer grants viewer", reviewer, models.EventOrganizer{Role: models.EventRoleViewer}, true},
+		{"Reviewer grants admin", reviewer, models.EventOrganizer{Role: models.EventRoleAdmin}, false},
+		{"Reviewer grants a custom role beyond their own", reviewer, models.EventOrganizer{Role: models.EventRoleCustom, Capabilities: []models.EventCapability{models.CapabilitySecretsRead}}, false},
+		{"Organization admin who isn't an organizer", orgAdmin, models.EventOrganizer{Role: models.EventRoleAdmin}, true},
+		{"Organization member who isn't an organizer", orgMember, models.EventOrganizer{Role: models.EventRoleViewer}, false},
+		{"Stranger", stranger, models.EventOrganizer{Role: models.EventRoleViewer}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			m := &mockOrganizationService{organization: organization}
+			assert.Equal(t, tt.expected, canGrantOrganizer(c, m, event, tt.user, tt.organizer))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// inviteOrganizer invites an email without an account to organize the event, they join once they accept or log in with it
+func inviteOrganizer(c *gin.Context, params *types.RouteParams, event *models.Event, inviter *models.User, email string, organizer models.EventOrganizer) {
+	if err := utils.Validator.Var(email, "required,email"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email address"})
+		return
+	}
+
+	invitation, err := helpers.InviteEventOrganizer(c, params.MongoService, event, inviter, email, organizer)
+	if invitation == nil {
+		logger.Error("Failed to create event invitation", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite organizer"})
+		return
+	}
+
+	helpers.Audit(c, params.MongoService, models.AuditEntry{
+		EventID:    event.ID,
+		Action:     models.AuditInvitationCreate,
+		TargetType: models.AuditTargetInvitation,
+		TargetID:   invitation.ID.Hex(),
+		Diff:       helpers.AuditDiff(nil, invitation),
+	})
+
+	message := "That email doesn't have an account yet, they've been sent an invitation"
+	if err != nil {
+		// The invitation is still accepted automatically once they sign up and verify the address
+		logger.Error("Failed to send event invitation email", err)
+		message = "That email doesn't have an account yet, an invitation was created but the email could not be sent"
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"invitation": invitation, "message": message})
+}
+
+// List the pending invitations of an event
+func listInvitationsHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityOrganizersWrite) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to manage this event's organizers"})
+			return
+		}
+
+		invitations, err := params.MongoService.ListPendingEventInvitations(c, eventID)
+		if err != nil {
+			logger.Error("Failed to list event invitations", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list invitations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+	}
+}
+
+// Revoke a pending invitation so it can no longer be accepted
+func revokeInvitationHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		invitationID, err := primitive.ObjectIDFromHex(c.Param("invitation_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invitation ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityOrganizersWrite) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to manage this event's organizers"})
+			return
+		}
+
+		result, err := params.MongoService.RevokeEventInvitation(c, eventID, invitationID)
+		if err != nil {
+			logger.Error("Failed to revoke event invitation", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invitation"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found or no longer pending"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    eventID,
+			Action:     models.AuditInvitationRevoke,
+			TargetType: models.AuditTargetInvitation,
+			TargetID:   invitationID.Hex(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Invitation revoked successfully"})
+	}
+}
+
+type acceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Accept an invitation with the token from the emailed link, the token was sent to the invited address
+// so it's accepted for whoever is logged in
+func acceptInvitationHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req acceptInvitationRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		invitation, err := params.MongoService.GetPendingEventInvitationByToken(c, utils.HashToken(req.Token))
+		if err == nil {
+			invitation, err = helpers.AcceptEventInvitation(c, params.MongoService, invitation.ID, authenticatedUser.ID)
+		}
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This invitation is invalid, has expired or was revoked"})
+				return
+			}
+			logger.Error("Failed to accept event invitation", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invitation"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    invitation.EventID,
+			Action:     models.AuditInvitationAccept,
+			TargetType: models.AuditTargetInvitation,
+			TargetID:   invitation.ID.Hex(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"eventID": invitation.EventID, "message": "Invitation accepted, you are now an organizer of this event"})
+	}
+}
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// publishEventHandler makes a draft or archived event visible, its metadata must have everything applicants need first
+func publishEventHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := getEventForStatusChange(c, params)
+		if !ok {
+			return
+		}
+
+		if problems := event.Metadata.PublishProblems(); len(problems) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(problems, "\n")})
+			return
+		}
+
+		changeEventStatus(c, params, event, []models.EventStatus{models.EventStatusDraft, models.EventStatusArchived}, models.EventStatusPublished, models.AuditEventPublish)
+	}
+}
+
+// archiveEventHandler marks an event as over, it can still be seen but is no longer listed
+func archiveEventHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := getEventForStatusChange(c, params)
+		if !ok {
+			return
+		}
+
+		changeEventStatus(c, params, event, []models.EventStatus{models.EventStatusPublished, models.EventStatusCancelled}, models.EventStatusArchived, models.AuditEventArchive)
+	}
+}
+
+// cancelEventHandler marks a published event as cancelled, it stays visible so applicants can see it isn't happening
+func cancelEventHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, ok := getEventForStatusChange(c, params)
+		if !ok {
+			return
+		}
+
+		changeEventStatus(c, params, event, []models.EventStatus{models.EventStatusPublished}, models.EventStatusCancelled, models.AuditEventCancel)
+	}
+}
+
+// getEventForStatusChange loads the event in the URL for an organizer allowed to edit it, writing the response if they can't
+func getEventForStatusChange(c *gin.Context, params *types.RouteParams) (*models.Event, bool) {
+	return getEventForWrite(c, params, "You are not allowed to change this event's status")
+}
+
+// getEventForWrite loads the event in the URL for an organizer allowed to edit it, responding with deniedMessage if they can't
+func getEventForWrite(c *gin.Context, params *types.RouteParams, deniedMessage string) (*models.Event, bool) {
+	eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return nil, false
+	}
+
+	authenticatedUser, ok := utils.GetUserFromContext(c, true)
+	if !ok {
+		return nil, false
+	}
+
+	event, err := params.MongoService.FindEventByID(c, eventID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return nil, false
+		}
+		logger.Error("Failed to get event", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
+		return nil, false
+	}
+
+	if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, event, models.CapabilityEventWrite) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": deniedMessage})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// changeEventStatus moves the event to a new status if it's currently in one of from
+func changeEventStatus(c *gin.Context, params *types.RouteParams, event *models.Event, from []models.EventStatus, to models.EventStatus, action models.AuditAction) {
+	current := event.GetStatus()
+	allowed := false
+	for _, status := range from {
+		if status == current {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.JSON(http.StatusConflict, gin.H{"error": "This event is " + string(current) + " so it can't be " + string(to)})
+		return
+	}
+
+	// The status is checked again in the update in case it changed since we read it
+	result, err := params.MongoService.SetEventStatus(c, event.ID, from, to)
+	if err != nil {
+		logger.Error("Failed to set event status", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change the event's status"})
+		return
+	}
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The event's status has changed, please try again"})
+		return
+	}
+
+	helpers.Audit(c, params.MongoService, models.AuditEntry{
+		EventID:    event.ID,
+		Action:     action,
+		TargetType: models.AuditTargetEvent,
+		TargetID:   event.ID.Hex(),
+		Diff:       map[string]models.AuditChange{"status": {Before: current, After: to}},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Event " + string(to), "status": to})
+}
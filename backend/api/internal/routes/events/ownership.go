@@ -0,0 +1,352 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"fmt"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const ownershipTransferTTL = 7 * 24 * time.Hour
+
+type ownershipTransferRequest struct {
+	UserID primitive.ObjectID `json:"userID" validate:"required"`
+}
+
+// Ask another organizer to take over the event, only the owner can do this and it waits for the new owner to accept
+func requestOwnershipTransferHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		var req ownershipTransferRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		event, err := params.MongoService.FindEventByID(c, eventID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+
+		if !event.OrganizationID.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This event is owned by an organization, its admins can manage it instead"})
+			return
+		}
+
+		if !mongodb.IsUserEventOwner(c, params.MongoService, authenticatedUser, event) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Only the event's owner can transfer it"})
+			return
+		}
+
+		if req.UserID == authenticatedUser.ID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You already own this event"})
+			return
+		}
+
+		if _, ok := event.GetOrganizer(req.UserID); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The event can only be transferred to one of its organizers"})
+			return
+		}
+
+		transfer := models.EventOwnershipTransfer{
+			EventID:     eventID,
+			FromUserID:  authenticatedUser.ID,
+			ToUserID:    req.UserID,
+			Status:      models.EventOwnershipTransferPending,
+			RequestedAt: time.Now(),
+			ExpiresAt:   time.Now().Add(ownershipTransferTTL),
+		}
+
+		result, err := params.MongoService.CreateEventOwnershipTransfer(c, transfer)
+		if err != nil {
+			logger.Error("Failed to create event ownership transfer", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer event"})
+			return
+		}
+		transfer.ID = result.InsertedID.(primitive.ObjectID)
+		auditOwnershipTransfer(c, params, models.AuditTransferRequest, &transfer)
+
+		if err := sendOwnershipTransferEmail(c, params, event, transfer); err != nil {
+			logger.Error("Failed to send event ownership transfer email", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"transfer": transfer, "message": "Transfer requested, it will take effect once they accept"})
+	}
+}
+
+// sendOwnershipTransferEmail lets the new owner know there's a transfer waiting for them to accept
+func sendOwnershipTransferEmail(c *gin.Context, params *types.RouteParams, event *models.Event, transfer models.EventOwnershipTransfer) error {
+	newOwner, err := params.MongoService.FindUserByID(c, transfer.ToUserID)
+	if err != nil {
+		return err
+	}
+
+	link := utils.WebsiteURL("/events/" + event.ID.Hex() + "/transfer")
+	body := fmt.Sprintf("Hi %s,\n\nYou've been asked to take over ownership of %s on ApplicantAtlas. "+
+		"Once you accept the event will count towards your plan and its responses will be billed to you. "+
+		"This request expires in %d days.\n\n%s",
+		newOwner.FirstName, event.Metadata.Name, int(ownershipTransferTTL.Hours()/24), link)
+
+	return utils.SendPlatformEmail(newOwner.Email, "Take over ownership of "+event.Metadata.Name, body)
+}
+
+// List the event's transfers, including finished ones so organizers can see who has owned the event
+func listOwnershipTransfersHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilityEventRead) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to view this event"})
+			return
+		}
+
+		transfers, err := params.MongoService.ListEventOwnershipTransfers(c, eventID)
+		if err != nil {
+			logger.Error("Failed to list event ownership transfers", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list transfers"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"transfers": transfers})
+	}
+}
+
+// Cancel a pending transfer, only the owner who requested it can
+func cancelOwnershipTransferHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		transfer, ok := getPendingOwnershipTransfer(c, params)
+		if !ok {
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if transfer.FromUserID != authenticatedUser.ID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Only the owner who requested the transfer can cancel it"})
+			return
+		}
+
+		finishOwnershipTransfer(c, params, transfer, models.EventOwnershipTransferCancelled)
+	}
+}
+
+// Decline a pending transfer, only the organizer it was sent to can
+func declineOwnershipTransferHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		transfer, ok := getPendingOwnershipTransfer(c, params)
+		if !ok {
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if transfer.ToUserID != authenticatedUser.ID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "This transfer was not sent to you"})
+			return
+		}
+
+		finishOwnershipTransfer(c, params, transfer, models.EventOwnershipTransferDeclined)
+	}
+}
+
+func finishOwnershipTransfer(c *gin.Context, params *types.RouteParams, transfer *models.EventOwnershipTransfer, status models.EventOwnershipTransferStatus) {
+	transfer, err := params.MongoService.FinishEventOwnershipTransfer(c, transfer.ID, status, primitive.NilObjectID, primitive.NilObjectID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "This transfer is no longer pending"})
+			return
+		}
+		logger.Error("Failed to update event ownership transfer", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transfer"})
+		return
+	}
+
+	action := models.AuditTransferDecline
+	if status == models.EventOwnershipTransferCancelled {
+		action = models.AuditTransferCancel
+	}
+	auditOwnershipTransfer(c, params, action, transfer)
+
+	c.JSON(http.StatusOK, gin.H{"transfer": transfer})
+}
+
+// Accept a pending transfer. The event's quota moves from the previous owner's subscription to the new owner's,
+// and since responses are billed to the event's owner they're billed to the new owner from now on.
+func acceptOwnershipTransferHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		transfer, ok := getPendingOwnershipTransfer(c, params)
+		if !ok {
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if transfer.ToUserID != authenticatedUser.ID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "This transfer was not sent to you"})
+			return
+		}
+
+		event, err := params.MongoService.FindEventByID(c, transfer.EventID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+
+		previousOwner, isPreviousOwner := event.GetOrganizer(transfer.FromUserID)
+		newOwner, isOrganizer := event.GetOrganizer(transfer.ToUserID)
+		if !isPreviousOwner || previousOwner.Role != models.EventRoleOwner || !isOrganizer {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The event's organizers have changed since this transfer was requested"})
+			return
+		}
+
+		newOwnerUser, err := params.MongoService.FindUserByID(c, transfer.ToUserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+			return
+		}
+
+		if newOwnerUser.CurrentSubscriptionID == primitive.NilObjectID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You need a subscription to own an event"})
+			return
+		}
+
+		// Claim the quota first so the event never ends up owned by someone over their limit
+		_, err = params.MongoService.IncrementSubscriptionUtilization(c, newOwnerUser.CurrentSubscriptionID, "eventsCreated", "maxEvents")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You've reached your plan's event limit, please upgrade your plan to take over this event"})
+			return
+		}
+
+		previousSubscriptionID := primitive.NilObjectID
+		if previousOwnerUser, err := params.MongoService.FindUserByID(c, transfer.FromUserID); err == nil {
+			previousSubscriptionID = previousOwnerUser.CurrentSubscriptionID
+		}
+
+		releaseQuota := func() {
+			if _, err := params.MongoService.DecrementSubscriptionEventUtilization(c, newOwnerUser.CurrentSubscriptionID, event.ID); err != nil {
+				logger.Error("Failed to release event quota after a failed transfer", err)
+			}
+		}
+
+		// Accepting claims the transfer so it can't be accepted twice, it's reopened below if the event can't be handed over
+		transfer, err = params.MongoService.FinishEventOwnershipTransfer(c, transfer.ID, models.EventOwnershipTransferAccepted, previousSubscriptionID, newOwnerUser.CurrentSubscriptionID)
+		if err != nil {
+			releaseQuota()
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This transfer is no longer pending"})
+				return
+			}
+			logger.Error("Failed to accept event ownership transfer", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept transfer"})
+			return
+		}
+
+		// The previous owner stays on as an admin, the new owner's role is replaced by owner
+		previousOwner.Role = models.EventRoleAdmin
+		newOwner.Role = models.EventRoleOwner
+		newOwner.Capabilities = nil
+
+		result, err := params.MongoService.TransferEventOwnership(c, event.ID, previousOwner, newOwner)
+		if err != nil || result.MatchedCount == 0 {
+			releaseQuota()
+			if _, err := params.MongoService.ReopenEventOwnershipTransfer(c, transfer.ID); err != nil {
+				logger.Error("Failed to reopen event ownership transfer", err)
+			}
+			logger.Error("Failed to transfer event ownership", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer event"})
+			return
+		}
+
+		if !previousSubscriptionID.IsZero() {
+			if _, err := params.MongoService.DecrementSubscriptionEventUtilization(c, previousSubscriptionID, event.ID); err != nil {
+				logger.Error("Failed to release the previous owner's event quota", err)
+			}
+		}
+
+		auditOwnershipTransfer(c, params, models.AuditTransferAccept, transfer)
+
+		c.JSON(http.StatusOK, gin.H{"transfer": transfer, "message": "You are now the owner of this event"})
+	}
+}
+
+// auditOwnershipTransfer records a step of a transfer, only accepting it actually changes the owner
+func auditOwnershipTransfer(c *gin.Context, params *types.RouteParams, action models.AuditAction, transfer *models.EventOwnershipTransfer) {
+	entry := models.AuditEntry{
+		EventID:    transfer.EventID,
+		Action:     action,
+		TargetType: models.AuditTargetTransfer,
+		TargetID:   transfer.ID.Hex(),
+	}
+	if action == models.AuditTransferAccept {
+		entry.Diff = map[string]models.AuditChange{
+			"owner": {Before: transfer.FromUserID.Hex(), After: transfer.ToUserID.Hex()},
+		}
+	}
+	helpers.Audit(c, params.MongoService, entry)
+}
+
+// getPendingOwnershipTransfer returns the pending transfer of the event in the URL, writing the response if there isn't one
+func getPendingOwnershipTransfer(c *gin.Context, params *types.RouteParams) (*models.EventOwnershipTransfer, bool) {
+	eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return nil, false
+	}
+
+	transfer, err := params.MongoService.GetPendingEventOwnershipTransfer(c, eventID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This event has no pending transfer"})
+			return nil, false
+		}
+		logger.Error("Failed to get event ownership transfer", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transfer"})
+		return nil, false
+	}
+
+	return transfer, true
+}
@@ -0,0 +1,382 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"errors"
+	"net/http"
+	"shared/logger"
+	"shared/messages"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// scheduleTimeLayouts are the formats session times are accepted in, times without an offset are in the event's timezone
+var scheduleTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+type scheduleItemRequest struct {
+	Title         string    `json:"title" validate:"required,max=100"`
+	Description   string    `json:"description" validate:"max=2000"`
+	StartTime     string    `json:"startTime" validate:"required"` // e.g. 2026-03-14T09:00 in the event's timezone, or RFC3339
+	EndTime       string    `json:"endTime" validate:"required"`
+	Location      string    `json:"location" validate:"max=100"`
+	Track         string    `json:"track" validate:"max=50"`
+	Speaker       string    `json:"speaker" validate:"max=100"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt"` // Only used when updating, to check nobody else changed the session since it was read
+}
+
+// listScheduleHandler returns an event's schedule to anyone who can see the event, with times in the event's timezone
+func listScheduleHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		event, ok := getVisibleEvent(c, params, eventID)
+		if !ok {
+			return
+		}
+
+		items, err := params.MongoService.ListScheduleItems(c, eventID)
+		if err != nil {
+			logger.Error("Failed to list schedule items", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schedule"})
+			return
+		}
+
+		authenticatedUser, _ := utils.GetUserFromContext(c, false)
+		for i := range items {
+			prepareScheduleItem(&items[i], event, authenticatedUser)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"timezone": event.Metadata.Timezone, "items": items})
+	}
+}
+
+func createScheduleItemHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		event, req, ok := getScheduleEventForWrite(c, params)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		item := models.ScheduleItem{
+			ID:            primitive.NewObjectID(),
+			EventID:       event.ID,
+			CreatedAt:     now,
+			LastUpdatedAt: now,
+		}
+		if !applyScheduleItemRequest(c, &item, req, event) {
+			return
+		}
+
+		if _, err := params.MongoService.CreateScheduleItem(c, item); err != nil {
+			logger.Error("Failed to create schedule item", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule item"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    event.ID,
+			Action:     models.AuditScheduleItemCreate,
+			TargetType: models.AuditTargetScheduleItem,
+			TargetID:   item.ID.Hex(),
+			Diff:       helpers.AuditDiff(nil, item),
+		})
+
+		prepareScheduleItem(&item, event, nil)
+		c.JSON(http.StatusOK, gin.H{"message": "Schedule item created successfully", "item": item})
+	}
+}
+
+func updateScheduleItemHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		itemID, err := primitive.ObjectIDFromHex(c.Param("item_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule item ID"})
+			return
+		}
+
+		event, req, ok := getScheduleEventForWrite(c, params)
+		if !ok {
+			return
+		}
+
+		item, ok := getScheduleItem(c, params, event.ID, itemID)
+		if !ok {
+			return
+		}
+
+		if item.LastUpdatedAt.After(req.LastUpdatedAt) {
+			c.JSON(http.StatusConflict, gin.H{"error": messages.UpdateAttemptOnChangedEntity})
+			return
+		}
+
+		before := *item
+		item.LastUpdatedAt = time.Now()
+		if !applyScheduleItemRequest(c, item, req, event) {
+			return
+		}
+
+		result, err := params.MongoService.UpdateScheduleItem(c, *item)
+		if err != nil {
+			logger.Error("Failed to update schedule item", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule item"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule item not found"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    event.ID,
+			Action:     models.AuditScheduleItemUpdate,
+			TargetType: models.AuditTargetScheduleItem,
+			TargetID:   item.ID.Hex(),
+			Diff:       helpers.AuditDiff(before, *item),
+		})
+
+		prepareScheduleItem(item, event, nil)
+		c.JSON(http.StatusOK, gin.H{"message": "Schedule item updated successfully", "item": item})
+	}
+}
+
+func deleteScheduleItemHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		itemID, err := primitive.ObjectIDFromHex(c.Param("item_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule item ID"})
+			return
+		}
+
+		event, ok := getEventForScheduleChange(c, params)
+		if !ok {
+			return
+		}
+
+		item, ok := getScheduleItem(c, params, event.ID, itemID)
+		if !ok {
+			return
+		}
+
+		if _, err := params.MongoService.DeleteScheduleItem(c, event.ID, itemID); err != nil {
+			logger.Error("Failed to delete schedule item", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule item"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    event.ID,
+			Action:     models.AuditScheduleItemDelete,
+			TargetType: models.AuditTargetScheduleItem,
+			TargetID:   itemID.Hex(),
+			Diff:       helpers.AuditDiff(*item, nil),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Schedule item deleted successfully"})
+	}
+}
+
+// registerForScheduleItemHandler lets someone say they're attending a session, which adds it to their calendar feed.
+// Once the event has a participant form only its participants can register.
+func registerForScheduleItemHandler(params *types.RouteParams, registered bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		itemID, err := primitive.ObjectIDFromHex(c.Param("item_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule item ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		event, ok := getVisibleEvent(c, params, eventID)
+		if !ok {
+			return
+		}
+
+		if registered {
+			if event.GetStatus() != models.EventStatusPublished {
+				c.JSON(http.StatusConflict, gin.H{"error": "This event is " + string(event.GetStatus()) + " so its sessions can't be registered for"})
+				return
+			}
+
+			settings, err := params.MongoService.GetEventSettings(c, eventID)
+			if err != nil {
+				logger.Error("Failed to get event settings", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register for session"})
+				return
+			}
+			if !settings.ParticipantFormID.IsZero() && !helpers.IsEventParticipant(c, params.MongoService, eventID) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Only the event's participants can register for its sessions"})
+				return
+			}
+		}
+
+		result, err := params.MongoService.SetScheduleItemRegistration(c, eventID, itemID, authenticatedUser.ID, registered)
+		if err != nil {
+			logger.Error("Failed to set schedule item registration", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update registration"})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule item not found"})
+			return
+		}
+
+		if registered {
+			c.JSON(http.StatusOK, gin.H{"message": "Registered for session"})
+		} else {
+			c.JSON(http.StatusOK, gin.H{"message": "Unregistered from session"})
+		}
+	}
+}
+
+// getVisibleEvent loads an event the way getEventHandler does, organizers with event:read can see drafts and private events but nobody else can
+func getVisibleEvent(c *gin.Context, params *types.RouteParams, eventID primitive.ObjectID) (*models.Event, bool) {
+	event, err := params.MongoService.GetEvent(c, eventID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return nil, false
+		}
+		logger.Error("Failed to get event", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event"})
+		return nil, false
+	}
+
+	user, _ := utils.GetUserFromContext(c, false)
+	if !event.IsVisibleToPublic() && !mongodb.UserHasEventCapability(c, params.MongoService, user, eventID, nil, models.CapabilityEventRead) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return nil, false
+	}
+	return event, true
+}
+
+// getEventForScheduleChange loads the event in the URL for an organizer allowed to edit it, writing the response if they can't
+func getEventForScheduleChange(c *gin.Context, params *types.RouteParams) (*models.Event, bool) {
+	return getEventForWrite(c, params, "You are not allowed to change this event's schedule")
+}
+
+// getScheduleEventForWrite reads the request to create or update a session along with the event it's for
+func getScheduleEventForWrite(c *gin.Context, params *types.RouteParams) (*models.Event, scheduleItemRequest, bool) {
+	var req scheduleItemRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, req, false
+	}
+
+	if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+		return nil, req, false
+	}
+
+	event, ok := getEventForScheduleChange(c, params)
+	if !ok {
+		return nil, req, false
+	}
+
+	// Sessions are checked against the event's dates, so those have to be set first
+	if event.Metadata.StartTime.IsZero() || event.Metadata.EndTime.IsZero() || event.Metadata.Timezone == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The event needs a start time, end time and timezone before sessions can be scheduled"})
+		return nil, req, false
+	}
+
+	return event, req, true
+}
+
+func getScheduleItem(c *gin.Context, params *types.RouteParams, eventID primitive.ObjectID, itemID primitive.ObjectID) (*models.ScheduleItem, bool) {
+	item, err := params.MongoService.GetScheduleItem(c, eventID, itemID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule item not found"})
+			return nil, false
+		}
+		logger.Error("Failed to get schedule item", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get schedule item"})
+		return nil, false
+	}
+	return item, true
+}
+
+// applyScheduleItemRequest copies the request onto the item, checking the session falls within the event
+func applyScheduleItemRequest(c *gin.Context, item *models.ScheduleItem, req scheduleItemRequest, event *models.Event) bool {
+	location, err := time.LoadLocation(event.Metadata.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The event's timezone is invalid"})
+		return false
+	}
+
+	startTime, err := parseScheduleTime(req.StartTime, location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start time, use YYYY-MM-DDTHH:MM in the event's timezone"})
+		return false
+	}
+
+	endTime, err := parseScheduleTime(req.EndTime, location)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end time, use YYYY-MM-DDTHH:MM in the event's timezone"})
+		return false
+	}
+
+	if !endTime.After(startTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The session must end after it starts"})
+		return false
+	}
+	if startTime.Before(event.Metadata.StartTime) || endTime.After(event.Metadata.EndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The session must be within the event's start and end times"})
+		return false
+	}
+
+	item.Title = req.Title
+	item.Description = req.Description
+	item.StartTime = startTime.UTC()
+	item.EndTime = endTime.UTC()
+	item.Location = req.Location
+	item.Track = req.Track
+	item.Speaker = req.Speaker
+	return true
+}
+
+// parseScheduleTime parses a session time, times without an offset are in location
+func parseScheduleTime(value string, location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range scheduleTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid time")
+}
+
+// prepareScheduleItem fills in what's returned about who registered and puts the times in the event's timezone
+func prepareScheduleItem(item *models.ScheduleItem, event *models.Event, user *models.User) {
+	item.RegisteredCount = len(item.RegisteredUserIDs)
+	item.Registered = user != nil && item.IsRegistered(user.ID)
+
+	if location, err := time.LoadLocation(event.Metadata.Timezone); err == nil {
+		item.StartTime = item.StartTime.In(location)
+		item.EndTime = item.EndTime.In(location)
+	}
+}
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+		valid    bool
+	}{
+		{"Event's timezone", "2026-03-14T09:00", time.Date(2026, 3, 14, 13, 0, 0, 0, time.UTC), true},
+		{"Event's timezone with seconds", "2026-03-14T09:00:30", time.Date(2026, 3, 14, 13, 0, 30, 0, time.UTC), true},
+		{"RFC3339 keeps its offset", "2026-03-14T09:00:00Z", time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC), true},
+		{"Date only", "2026-03-14", time.Time{}, false},
+		{"Not a time", "soon", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseScheduleTime(tt.value, newYork)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(parsed), "expected %s, got %s", tt.expected, parsed)
+		})
+	}
+}
+
+func TestApplyScheduleItemRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	event := &models.Event{Metadata: models.EventMetadata{
+		Timezone:  "America/New_York",
+		StartTime: time.Date(2026, 3, 14, 13, 0, 0, 0, time.UTC), // 9am in New York
+		EndTime:   time.Date(2026, 3, 15, 22, 0, 0, 0, time.UTC),
+	}}
+
+	tests := []struct {
+		name      string
+		startTime string
+		endTime   string
+		timezone  string
+		expected  int
+	}{
+		{"Within the event", "2026-03-14T10:00", "2026-03-14T11:00", "", http.StatusOK},
+		{"Ends before it starts", "2026-03-14T11:00", "2026-03-14T10:00", "", http.StatusBadRequest},
+		{"Ends as it starts", "2026-03-14T10:00", "2026-03-14T10:00", "", http.StatusBadRequest},
+		{"Starts before the event", "2026-03-14T08:00", "2026-03-14T10:00", "", http.StatusBadRequest},
+		{"Ends after the event", "2026-03-15T17:00", "2026-03-15T19:00", "", http.StatusBadRequest},
+		{"Invalid start time", "tomorrow", "2026-03-14T10:00", "", http.StatusBadRequest},
+		{"Invalid event timezone", "2026-03-14T10:00", "2026-03-14T11:00", "Mars/Olympus_Mons", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			e := *event
+			if tt.timezone != "" {
+				e.Metadata.Timezone = tt.timezone
+			}
+
+			var item models.ScheduleItem
+			ok := applyScheduleItemRequest(c, &item, scheduleItemRequest{Title: "Opening", StartTime: tt.startTime, EndTime: tt.endTime}, &e)
+			assert.Equal(t, tt.expected == http.StatusOK, ok)
+			if !ok {
+				assert.Equal(t, tt.expected, w.Code)
+				return
+			}
+			assert.Equal(t, "Opening", item.Title)
+			assert.Equal(t, time.Date(2026, 3, 14, 14, 0, 0, 0, time.UTC), item.StartTime)
+			assert.Equal(t, time.UTC, item.StartTime.Location(), "times are stored in UTC")
+		})
+	}
+}
+
+func TestPrepareScheduleItem(t *testing.T) {
+	registered := &models.User{ID: primitive.NewObjectID()}
+	other := &models.User{ID: primitive.NewObjectID()}
+	event := &models.Event{Metadata: models.EventMetadata{Timezone: "America/New_York"}}
+	start := time.Date(2026, 3, 14, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		registered bool
+	}{
+		{"Registered", registered, true},
+		{"Not registered", other, false},
+		{"Not logged in", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := models.ScheduleItem{StartTime: start, EndTime: start.Add(time.Hour), RegisteredUserIDs: []primitive.ObjectID{registered.ID, primitive.NewObjectID()}}
+			prepareScheduleItem(&item, event, tt.user)
+
+			assert.Equal(t, 2, item.RegisteredCount)
+			assert.Equal(t, tt.registered, item.Registered)
+			assert.Equal(t, 10, item.StartTime.Hour(), "times are returned in the event's timezone")
+			assert.True(t, start.Equal(item.StartTime))
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package secrets
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/types"
 	"net/http"
+	"shared/logger"
 	"shared/models"
 	"shared/mongodb"
 	"shared/utils"
@@ -23,10 +25,10 @@ Secret API Operations:
 */
 
 func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
-	r.GET("", middlewares.JWTAuthMiddleware(), listSecrets(params))
-	r.POST("", middlewares.JWTAuthMiddleware(), createSecret(params))
-	r.PUT("", middlewares.JWTAuthMiddleware(), updateSecret(params))
-	r.DELETE("", middlewares.JWTAuthMiddleware(), deleteSecret(params))
+	r.GET("", middlewares.JWTAuthMiddleware(params.MongoService), listSecrets(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), createSecret(params))
+	r.PUT("", middlewares.JWTAuthMiddleware(params.MongoService), updateSecret(params))
+	r.DELETE("", middlewares.JWTAuthMiddleware(params.MongoService), deleteSecret(params))
 }
 
 func listSecrets(params *types.RouteParams) gin.HandlerFunc {
@@ -48,7 +50,7 @@ func listSecrets(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, eventID, nil) {
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, models.CapabilitySecretsRead) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not an organizer of this event"})
 			return
 		}
@@ -81,7 +83,7 @@ func createSecret(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authUser, eventID, nil) {
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authUser, eventID, nil, models.CapabilitySecretsWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not an organizer of this event"})
 			return
 		}
@@ -93,12 +95,22 @@ func createSecret(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		// The secrets always belong to the event in the URL, that's the one the user was checked against
+		newSecret.EventID = eventID
+
+		previous, ok := getEventSecretsForAudit(c, params, eventID)
+		if !ok {
+			return
+		}
+
 		_, err = params.MongoService.CreateOrUpdateEventSecrets(c, newSecret)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create secret"})
 			return
 		}
 
+		auditSecrets(c, params, eventID, models.AuditSecretCreate, previous, &newSecret)
+
 		c.JSON(http.StatusOK, gin.H{"message": "Secret created successfully"})
 	}
 }
@@ -124,11 +136,19 @@ func updateSecret(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authUser, eventID, nil) {
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authUser, eventID, nil, models.CapabilitySecretsWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not an organizer of this event"})
 			return
 		}
 
+		// The secrets always belong to the event in the URL, that's the one the user was checked against
+		updatedSecret.EventID = eventID
+
+		previous, ok := getEventSecretsForAudit(c, params, eventID)
+		if !ok {
+			return
+		}
+
 		// Update the secret in the database
 		_, err = params.MongoService.CreateOrUpdateEventSecrets(c, updatedSecret)
 		if err != nil {
@@ -136,6 +156,8 @@ func updateSecret(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		auditSecrets(c, params, eventID, models.AuditSecretUpdate, previous, &updatedSecret)
+
 		c.JSON(http.StatusOK, gin.H{"message": "Secret updated successfully"})
 	}
 }
@@ -153,11 +175,16 @@ func deleteSecret(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyEvent(c, params.MongoService, authUser, eventID, nil) {
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authUser, eventID, nil, models.CapabilitySecretsWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not an organizer of this event"})
 			return
 		}
 
+		previous, ok := getEventSecretsForAudit(c, params, eventID)
+		if !ok {
+			return
+		}
+
 		// Delete the secret from the database
 		_, err = params.MongoService.DeleteEventSecrets(c, eventID)
 		if err != nil {
@@ -165,6 +192,43 @@ func deleteSecret(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		auditSecrets(c, params, eventID, models.AuditSecretDelete, previous, nil)
+
 		c.JSON(http.StatusOK, gin.H{"message": "Event Secrets deleted successfully"})
 	}
 }
+
+// getEventSecretsForAudit returns the event's secrets before they're changed, or nil if it doesn't have any yet
+func getEventSecretsForAudit(c *gin.Context, params *types.RouteParams, eventID primitive.ObjectID) (*models.EventSecrets, bool) {
+	secrets, err := params.MongoService.GetEventSecrets(c, bson.M{"eventID": eventID}, false)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, true
+		}
+		logger.Error("Failed to get event secrets", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get event secrets"})
+		return nil, false
+	}
+	return secrets, true
+}
+
+// auditSecrets records which secrets changed without copying their values into the audit log.
+// Secrets left out of an update aren't changed, so they're left out of the diff too.
+func auditSecrets(c *gin.Context, params *types.RouteParams, eventID primitive.ObjectID, action models.AuditAction, before *models.EventSecrets, after *models.EventSecrets) {
+	diff := helpers.AuditRedactedDiff(before, after)
+	if after != nil {
+		for key, change := range diff {
+			if change.After == nil {
+				delete(diff, key)
+			}
+		}
+	}
+
+	helpers.Audit(c, params.MongoService, models.AuditEntry{
+		EventID:    eventID,
+		Action:     action,
+		TargetType: models.AuditTargetSecret,
+		TargetID:   eventID.Hex(),
+		Diff:       diff,
+	})
+}
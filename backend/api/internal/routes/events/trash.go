@@ -0,0 +1,178 @@
+package events
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"context"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// trashKind is one of the kinds of things that can be in an event's trash
+type trashKind struct {
+	Name            string // As it's shown in errors
+	ReadCapability  models.EventCapability
+	WriteCapability models.EventCapability
+	AuditAction     models.AuditAction
+	AuditTargetType models.AuditTargetType
+	Restore         func(ctx context.Context, m mongodb.MongoService, eventID primitive.ObjectID, id primitive.ObjectID) (*mongo.UpdateResult, error)
+}
+
+// trashKinds is keyed by the kind in restore URLs
+var trashKinds = map[string]trashKind{
+	"forms": {
+		Name:            "form",
+		ReadCapability:  models.CapabilityFormsRead,
+		WriteCapability: models.CapabilityFormsWrite,
+		AuditAction:     models.AuditFormRestore,
+		AuditTargetType: models.AuditTargetForm,
+		Restore: func(ctx context.Context, m mongodb.MongoService, eventID primitive.ObjectID, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+			return m.RestoreForm(ctx, eventID, id)
+		},
+	},
+	"email_templates": {
+		Name:            "email template",
+		ReadCapability:  models.CapabilityEmailTemplatesRead,
+		WriteCapability: models.CapabilityEmailTemplatesWrite,
+		AuditAction:     models.AuditEmailTemplateRestore,
+		AuditTargetType: models.AuditTargetEmailTemplate,
+		Restore: func(ctx context.Context, m mongodb.MongoService, eventID primitive.ObjectID, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+			return m.RestoreEmailTemplate(ctx, eventID, id)
+		},
+	},
+	"pipelines": {
+		Name:            "pipeline",
+		ReadCapability:  models.CapabilityPipelinesRead,
+		WriteCapability: models.CapabilityPipelinesWrite,
+		AuditAction:     models.AuditPipelineRestore,
+		AuditTargetType: models.AuditTargetPipeline,
+		Restore: func(ctx context.Context, m mongodb.MongoService, eventID primitive.ObjectID, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+			return m.RestorePipeline(ctx, eventID, id)
+		},
+	},
+}
+
+// listTrashHandler lists the event's deleted forms, email templates and pipelines that haven't been purged yet.
+// Each kind is only listed if the user can read it.
+func listTrashHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		event, err := params.MongoService.FindEventByID(c, eventID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+
+		canRead := func(kind string) bool {
+			return mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, event, trashKinds[kind].ReadCapability)
+		}
+		if !canRead("forms") && !canRead("email_templates") && !canRead("pipelines") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to view this event's trash"})
+			return
+		}
+
+		filter := bson.M{"eventID": eventID, "isDeleted": true}
+		forms := []models.FormStructure{}
+		emailTemplates := []models.EmailTemplate{}
+		pipelines := []models.PipelineConfiguration{}
+
+		if canRead("forms") {
+			if forms, err = params.MongoService.ListForms(c, filter); err != nil {
+				logger.Error("Failed to list deleted forms", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list trash"})
+				return
+			}
+		}
+		if canRead("email_templates") {
+			if emailTemplates, err = params.MongoService.ListEmailTemplates(c, filter); err != nil {
+				logger.Error("Failed to list deleted email templates", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list trash"})
+				return
+			}
+		}
+		if canRead("pipelines") {
+			if pipelines, err = params.MongoService.ListPipelines(c, filter); err != nil {
+				logger.Error("Failed to list deleted pipelines", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list trash"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"forms":           forms,
+			"email_templates": emailTemplates,
+			"pipelines":       pipelines,
+		})
+	}
+}
+
+// restoreFromTrashHandler takes a form, email template or pipeline back out of the event's trash
+func restoreFromTrashHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		kind, ok := trashKinds[c.Param("kind")]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Only forms, email templates and pipelines can be restored"})
+			return
+		}
+
+		eventID, err := primitive.ObjectIDFromHex(c.Param("event_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+			return
+		}
+
+		itemID, err := primitive.ObjectIDFromHex(c.Param("item_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + kind.Name + " ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, eventID, nil, kind.WriteCapability) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to restore this event's " + kind.Name + "s"})
+			return
+		}
+
+		result, err := kind.Restore(c, params.MongoService, eventID, itemID)
+		if err != nil {
+			logger.Error("Failed to restore "+kind.Name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore " + kind.Name})
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "This " + kind.Name + " isn't in the event's trash"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    eventID,
+			Action:     kind.AuditAction,
+			TargetType: kind.AuditTargetType,
+			TargetID:   itemID.Hex(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Restored " + kind.Name})
+	}
+}
@@ -1,6 +1,7 @@
 package forms
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/routes/forms/responses"
 	"api/internal/types"
@@ -18,10 +19,11 @@ import (
 )
 
 func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
-	r.GET(":form_id", middlewares.JWTAuthMiddleware(), getFormDataHandler(params))
-	r.POST("", middlewares.JWTAuthMiddleware(), createFormHandler(params))
-	r.PUT(":form_id", middlewares.JWTAuthMiddleware(), updateFormHandler(params))
-	r.DELETE(":form_id", middlewares.JWTAuthMiddleware(), deleteFormHandler(params))
+	r.GET(":form_id", middlewares.JWTAuthMiddleware(params.MongoService), getFormDataHandler(params))
+	r.GET(":form_id/sign-in", getFormSignInHandler(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), createFormHandler(params))
+	r.PUT(":form_id", middlewares.JWTAuthMiddleware(params.MongoService), updateFormHandler(params))
+	r.DELETE(":form_id", middlewares.JWTAuthMiddleware(params.MongoService), deleteFormHandler(params))
 
 	responsesGroup := r.Group(":form_id/responses")
 	responses.RegisterFormResponsesRoutes(responsesGroup, params)
@@ -52,7 +54,7 @@ func getFormDataHandler(params *types.RouteParams) gin.HandlerFunc {
 				return
 			}
 
-			if !mongodb.CanUserModifyForm(c, params.MongoService, authenticatedUser, formID, form) {
+			if !mongodb.UserHasFormCapability(c, params.MongoService, authenticatedUser, formID, form, models.CapabilityFormsRead) {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to view this form"})
 				return
 			}
@@ -60,7 +62,7 @@ func getFormDataHandler(params *types.RouteParams) gin.HandlerFunc {
 
 		// Check if the authenticated user's emails are in the form's whitelist, if it exists
 		if form.IsRestricted {
-			allowed, restrictMessage := mongodb.IsUserEmailInWhitelist(c, form.AllowedSubmitters)
+			allowed, restrictMessage := mongodb.IsUserEmailInWhitelist(c, params.MongoService, form.AllowedSubmitters)
 			if !allowed {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": restrictMessage})
 				return
@@ -72,6 +74,32 @@ func getFormDataHandler(params *types.RouteParams) gin.HandlerFunc {
 	}
 }
 
+// getFormSignInHandler tells the sign in page how to show the form's event to applicants before they're logged in
+func getFormSignInHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		formID, err := primitive.ObjectIDFromHex(c.Param("form_id"))
+		if err != nil || formID.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form ID"})
+			return
+		}
+
+		// Unpublished forms are only for organizers, who sign in the usual way
+		form, err := params.MongoService.GetForm(c, formID, true)
+		if err != nil || form.Status != "published" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
+			return
+		}
+
+		settings, err := params.MongoService.GetEventSettings(c, form.EventID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"preferMagicLink": settings.PreferMagicLinkSignIn})
+	}
+}
+
 func createFormHandler(params *types.RouteParams) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req models.FormStructure
@@ -91,21 +119,13 @@ func createFormHandler(params *types.RouteParams) gin.HandlerFunc {
 		}
 
 		// Check if user is authorized to create form on this event
-		event, err := params.MongoService.GetEvent(c, req.EventID)
+		event, err := params.MongoService.FindEventByID(c, req.EventID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
 
-		var found = false
-		for _, organizerID := range event.OrganizerIDs {
-			if organizerID == authenticatedUser.ID {
-				found = true
-				break
-			}
-		}
-
-		if !found {
+		if !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, req.EventID, event, models.CapabilityFormsWrite) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "You are not authorized to create a form for this event"})
 			return
 		}
@@ -116,6 +136,14 @@ func createFormHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    req.EventID,
+			Action:     models.AuditFormCreate,
+			TargetType: models.AuditTargetForm,
+			TargetID:   formID.InsertedID.(primitive.ObjectID).Hex(),
+			Diff:       helpers.AuditDiff(nil, req),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"id": formID})
 	}
 }
@@ -139,7 +167,13 @@ func deleteFormHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyForm(c, params.MongoService, authenticatedUser, formID, nil) {
+		form, err := params.MongoService.GetForm(c, formID, false)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
+			return
+		}
+
+		if !mongodb.UserHasFormCapability(c, params.MongoService, authenticatedUser, formID, form, models.CapabilityFormsWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to delete this form"})
 			return
 		}
@@ -150,6 +184,14 @@ func deleteFormHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    form.EventID,
+			Action:     models.AuditFormDelete,
+			TargetType: models.AuditTargetForm,
+			TargetID:   formID.Hex(),
+			Diff:       helpers.AuditDiff(form, nil),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Form deleted successfully"})
 	}
 }
@@ -185,13 +227,14 @@ func updateFormHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		form, err := params.MongoService.GetForm(c, formID, true)
+		// Secrets are kept so changes to who can submit show up in the audit log
+		form, err := params.MongoService.GetForm(c, formID, false)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Form not found"})
 			return
 		}
 
-		if !mongodb.CanUserModifyForm(c, params.MongoService, authenticatedUser, formID, form) {
+		if !mongodb.UserHasFormCapability(c, params.MongoService, authenticatedUser, formID, form, models.CapabilityFormsWrite) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "You are not authorized to update this form"})
 			return
 		}
@@ -210,6 +253,14 @@ func updateFormHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    form.EventID,
+			Action:     models.AuditFormUpdate,
+			TargetType: models.AuditTargetForm,
+			TargetID:   formID.Hex(),
+			Diff:       helpers.AuditDiff(form, req),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Form updated successfully", "lastUpdatedAt": newLastUpdatedAt})
 	}
 }
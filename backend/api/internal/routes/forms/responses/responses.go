@@ -24,11 +24,12 @@ import (
 )
 
 func RegisterFormResponsesRoutes(r *gin.RouterGroup, params *types.RouteParams) {
-	r.POST("", middlewares.JWTAuthMiddleware(), submitFormHandler(params))
-	r.GET("", middlewares.JWTAuthMiddleware(), listFormResponsesHandler(params))
-	r.GET("csv", middlewares.JWTAuthMiddleware(), downloadFormResponsesAsCSVHandler(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), submitFormHandler(params))
+	r.GET("", middlewares.JWTAuthMiddleware(params.MongoService), listFormResponsesHandler(params))
+	r.GET("csv", middlewares.JWTAuthMiddleware(params.MongoService), downloadFormResponsesAsCSVHandler(params))
 
-	r.PUT(":response_id", middlewares.JWTAuthMiddleware(), updateFormResponseHandler(params))
+	r.PUT(":response_id", middlewares.JWTAuthMiddleware(params.MongoService), updateFormResponseHandler(params))
+	r.POST(":response_id/check-in", middlewares.JWTAuthMiddleware(params.MongoService), checkInFormResponseHandler(params))
 }
 
 func submitFormHandler(params *types.RouteParams) gin.HandlerFunc {
@@ -121,7 +122,7 @@ func submitFormHandler(params *types.RouteParams) gin.HandlerFunc {
 
 		// If the form is restricted, check if the user is in the whitelist
 		if form.IsRestricted {
-			allowed, restrictMessage := mongodb.IsUserEmailInWhitelist(c, form.AllowedSubmitters)
+			allowed, restrictMessage := mongodb.IsUserEmailInWhitelist(c, params.MongoService, form.AllowedSubmitters)
 			if !allowed {
 				c.JSON(http.StatusUnauthorized, gin.H{"error": restrictMessage})
 				return
@@ -160,27 +161,14 @@ func submitFormHandler(params *types.RouteParams) gin.HandlerFunc {
 
 		// TODO: We should do this in a transaction
 
-		// Check billing
-		eventDetails, err := params.MongoService.GetEvent(c, form.EventID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-			logger.Error("Failed to get event details", err)
-			return
-		}
-
-		u, err := params.MongoService.GetUserDetails(c, eventDetails.CreatedByID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
-			return
-		}
-
-		if u.CurrentSubscriptionID == primitive.NilObjectID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User does not have a subscription"})
-			return
-		}
-
-		sub, err := params.MongoService.GetSubscription(c, u.CurrentSubscriptionID)
+		// Check billing, responses are billed to the event's organization or owner
+		sub, err := params.MongoService.GetEventSubscription(c, form.EventID)
 		if err != nil {
+			if err == mongodb.ErrNoSubscription {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User does not have a subscription"})
+				return
+			}
+			logger.Error("Failed to get event subscription", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription"})
 			return
 		}
@@ -230,12 +218,22 @@ func submitFormHandler(params *types.RouteParams) gin.HandlerFunc {
 
 		// Submit form
 		req.UserID = authenticatedUser.ID
-		if _, err := params.MongoService.CreateResponse(c, req); err != nil {
+		req.CheckedInAt = time.Time{} // Only check-in staff can check an applicant in
+		result, err := params.MongoService.CreateResponse(c, req)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			logger.Error("Failed to create form response", err)
 			return
 		}
 
+		// The answers are the response itself, so they aren't copied into the log
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    form.EventID,
+			Action:     models.AuditResponseSubmit,
+			TargetType: models.AuditTargetResponse,
+			TargetID:   result.InsertedID.(primitive.ObjectID).Hex(),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Success"})
 	}
 }
@@ -322,8 +320,8 @@ func listFormResponsesHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyForm(c, params.MongoService, authenticatedUser, form.ID, form) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this form"})
+		if ok, message := mongodb.CanUserAccessFormResponses(c, params.MongoService, authenticatedUser, form, models.CapabilityResponsesRead); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": message})
 			return
 		}
 
@@ -391,8 +389,8 @@ func downloadFormResponsesAsCSVHandler(params *types.RouteParams) gin.HandlerFun
 			return
 		}
 
-		if !mongodb.CanUserModifyForm(c, params.MongoService, authenticatedUser, form.ID, form) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this form"})
+		if ok, message := mongodb.CanUserAccessFormResponses(c, params.MongoService, authenticatedUser, form, models.CapabilityResponsesRead); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": message})
 			return
 		}
 
@@ -458,8 +456,8 @@ func updateFormResponseHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyForm(c, params.MongoService, authenticatedUser, form.ID, form) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this form"})
+		if ok, message := mongodb.CanUserAccessFormResponses(c, params.MongoService, authenticatedUser, form, models.CapabilityResponsesWrite); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": message})
 			return
 		}
 
@@ -491,27 +489,14 @@ func updateFormResponseHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		// Check billing
-		eventDetails, err := params.MongoService.GetEvent(c, form.EventID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-			logger.Error("Failed to get event details", err)
-			return
-		}
-
-		u, err := params.MongoService.GetUserDetails(c, eventDetails.CreatedByID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
-			return
-		}
-
-		if u.CurrentSubscriptionID == primitive.NilObjectID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User does not have a subscription"})
-			return
-		}
-
-		sub, err := params.MongoService.GetSubscription(c, u.CurrentSubscriptionID)
+		// Check billing, responses are billed to the event's organization or owner
+		sub, err := params.MongoService.GetEventSubscription(c, form.EventID)
 		if err != nil {
+			if err == mongodb.ErrNoSubscription {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User does not have a subscription"})
+				return
+			}
+			logger.Error("Failed to get event subscription", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription"})
 			return
 		}
@@ -558,6 +543,68 @@ func updateFormResponseHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		// Only which questions were answered differently is logged, the answers stay in the response
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    form.EventID,
+			Action:     models.AuditResponseUpdate,
+			TargetType: models.AuditTargetResponse,
+			TargetID:   responseID.Hex(),
+			Diff:       helpers.AuditRedactedDiff(responses[0].Data, response.Data),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"id": responseID, "lastUpdatedAt": newUpdatedAt})
 	}
 }
+
+// Check in the applicant who submitted the response, check-in staff can do this without seeing the answers
+func checkInFormResponseHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		responseID, err := primitive.ObjectIDFromHex(c.Param("response_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid response ID"})
+			return
+		}
+
+		formID, err := primitive.ObjectIDFromHex(c.Param("form_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form ID"})
+			return
+		}
+
+		form, err := params.MongoService.GetForm(c, formID, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Form does not exist"})
+			return
+		}
+
+		if ok, message := mongodb.CanUserAccessFormResponses(c, params.MongoService, authenticatedUser, form, models.CapabilityResponsesCheckIn); !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": message})
+			return
+		}
+
+		result, err := params.MongoService.CheckInResponse(c, formID, responseID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			logger.Error("Failed to check in form response", err)
+			return
+		}
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Response does not exist or is already checked in"})
+			return
+		}
+
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    form.EventID,
+			Action:     models.AuditResponseCheckIn,
+			TargetType: models.AuditTargetResponse,
+			TargetID:   responseID.Hex(),
+		})
+
+		c.JSON(http.StatusOK, gin.H{"message": "Checked in"})
+	}
+}
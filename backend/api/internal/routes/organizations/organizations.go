@@ -0,0 +1,446 @@
+package organizations
+
+import (
+	"api/internal/helpers"
+	"api/internal/middlewares"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxCreatedOrganizations is how many organizations a user can create, each comes with its own free subscription
+// so without a limit anyone could get as many free events as they liked. Platform admins can create more.
+const maxCreatedOrganizations = 3
+
+// RegisterRoutes sets up the routes for organizations
+func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
+	r.GET("", middlewares.JWTAuthMiddleware(params.MongoService), listOrganizationsHandler(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware(), createOrganizationHandler(params))
+	r.GET(":organization_id", middlewares.JWTAuthMiddleware(params.MongoService), getOrganizationHandler(params))
+	r.PUT(":organization_id", middlewares.JWTAuthMiddleware(params.MongoService), updateOrganizationHandler(params))
+	r.DELETE(":organization_id", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware(), deleteOrganizationHandler(params))
+	r.GET(":organization_id/events", middlewares.JWTAuthMiddleware(params.MongoService), listOrganizationEventsHandler(params))
+	r.GET(":organization_id/subscription", middlewares.JWTAuthMiddleware(params.MongoService), getOrganizationSubscriptionHandler(params))
+	r.POST(":organization_id/members/:user_email", middlewares.JWTAuthMiddleware(params.MongoService), addMemberHandler(params))
+	r.PUT(":organization_id/members/:user_id", middlewares.JWTAuthMiddleware(params.MongoService), updateMemberHandler(params))
+	r.DELETE(":organization_id/members/:user_id", middlewares.JWTAuthMiddleware(params.MongoService), removeMemberHandler(params))
+}
+
+type organizationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// List the organizations the user is a member of
+func listOrganizationsHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		organizations, err := params.MongoService.ListUserOrganizations(c, authenticatedUser.ID)
+		if err != nil {
+			logger.Error("Failed to list organizations", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list organizations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"organizations": organizations})
+	}
+}
+
+// Create an organization with the user as its owner, it gets its own subscription on the default plan
+func createOrganizationHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req organizationRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if !authenticatedUser.IsPlatformAdmin {
+			created, err := params.MongoService.CountOrganizationsCreatedBy(c, authenticatedUser.ID)
+			if err != nil {
+				logger.Error("Failed to count created organizations", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+				return
+			}
+			if created >= maxCreatedOrganizations {
+				c.JSON(http.StatusForbidden, gin.H{"error": "You have created as many organizations as you can, please contact support for more"})
+				return
+			}
+		}
+
+		// The ID is picked up front so the subscription can point at the organization
+		now := time.Now()
+		organization := models.Organization{
+			ID:          primitive.NewObjectID(),
+			Name:        req.Name,
+			Members:     []models.OrganizationMember{{UserID: authenticatedUser.ID, Role: models.OrganizationRoleOwner, AddedAt: now}},
+			CreatedByID: authenticatedUser.ID,
+			CreatedAt:   now,
+		}
+
+		subscriptionID, err := helpers.CreateDefaultSubscription(c, params.MongoService, primitive.NilObjectID, organization.ID)
+		if err != nil {
+			logger.Error("Failed to create organization subscription", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+			return
+		}
+		organization.CurrentSubscriptionID = subscriptionID
+
+		if _, err := params.MongoService.CreateOrganization(c, organization); err != nil {
+			logger.Error("Failed to create organization", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Organization created successfully", "organization": organization})
+	}
+}
+
+func getOrganizationHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organization, _, ok := getOrganizationMembership(c, params, false)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"organization": organization})
+	}
+}
+
+// Rename an organization, owners and admins can do this
+func updateOrganizationHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req organizationRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		organization, _, ok := getOrganizationMembership(c, params, true)
+		if !ok {
+			return
+		}
+
+		if _, err := params.MongoService.UpdateOrganizationName(c, organization.ID, req.Name); err != nil {
+			logger.Error("Failed to update organization", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Organization updated successfully"})
+	}
+}
+
+// Delete an organization and cancel its subscription, only owners can and only once it owns no events
+func deleteOrganizationHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organization, member, ok := getOrganizationMembership(c, params, true)
+		if !ok {
+			return
+		}
+
+		if member.Role != models.OrganizationRoleOwner {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Only the organization's owners can delete it"})
+			return
+		}
+
+		events, _, err := params.MongoService.ListEventsMetadata(c, mongodb.EventListOptions{OrganizationID: organization.ID})
+		if err != nil {
+			logger.Error("Failed to list organization events", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization"})
+			return
+		}
+
+		if len(events) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Delete the organization's events before deleting the organization"})
+			return
+		}
+
+		if _, err := params.MongoService.DeleteOrganization(c, organization.ID); err != nil {
+			logger.Error("Failed to delete organization", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Organization deleted successfully"})
+	}
+}
+
+// List the events the organization owns
+func listOrganizationEventsHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organization, _, ok := getOrganizationMembership(c, params, false)
+		if !ok {
+			return
+		}
+
+		events, _, err := params.MongoService.ListEventsMetadata(c, mongodb.EventListOptions{OrganizationID: organization.ID})
+		if err != nil {
+			logger.Error("Failed to list organization events", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list events"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"events": events})
+	}
+}
+
+// Get the organization's subscription and how much of it has been used
+func getOrganizationSubscriptionHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		organization, _, ok := getOrganizationMembership(c, params, false)
+		if !ok {
+			return
+		}
+
+		if organization.CurrentSubscriptionID == primitive.NilObjectID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Organization does not have a subscription"})
+			return
+		}
+
+		subscription, err := params.MongoService.GetSubscription(c, organization.CurrentSubscriptionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subscription"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"subscription": subscription})
+	}
+}
+
+type memberRoleRequest struct {
+	Role models.OrganizationRole `json:"role"`
+}
+
+// Add a member by email, the role defaults to member. Only owners can add other owners.
+func addMemberHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req memberRoleRequest
+		if c.Request.ContentLength > 0 {
+			if err := utils.BindJSON(c, &req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.Role == "" {
+			req.Role = models.OrganizationRoleMember
+		}
+
+		organization, member, ok := getOrganizationMembership(c, params, true)
+		if !ok {
+			return
+		}
+
+		if !canGrantRole(c, member, req.Role) {
+			return
+		}
+
+		user, err := params.MongoService.FindUserByEmail(c, c.Param("user_email"))
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User not found, please ensure that user has an account"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find a user with that email"})
+			return
+		}
+
+		newMember := models.OrganizationMember{UserID: user.ID, Role: req.Role, AddedAt: time.Now()}
+		result, err := params.MongoService.AddOrganizationMember(c, organization.ID, newMember)
+		if err != nil {
+			logger.Error("Failed to add organization member", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "That user is already a member of this organization"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"member": newMember, "message": "Member added successfully"})
+	}
+}
+
+// Change a member's role, owners can only be changed by other owners and the last owner can't be demoted
+func updateMemberHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		var req memberRoleRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		organization, member, ok := getOrganizationMembership(c, params, true)
+		if !ok {
+			return
+		}
+
+		target, isMember := organization.GetMember(userID)
+		if !isMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "That user is not a member of this organization"})
+			return
+		}
+
+		if !canGrantRole(c, member, req.Role) || !canGrantRole(c, member, target.Role) {
+			return
+		}
+
+		if target.Role == models.OrganizationRoleOwner && req.Role != models.OrganizationRoleOwner && countOwners(organization) <= 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An organization needs at least one owner"})
+			return
+		}
+
+		target.Role = req.Role
+		if _, err := params.MongoService.UpdateOrganizationMember(c, organization.ID, target); err != nil {
+			logger.Error("Failed to update organization member", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update member"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"member": target, "message": "Member updated successfully"})
+	}
+}
+
+// Remove a member, anyone can leave an organization as long as they aren't its last owner
+func removeMemberHandler(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		// Members removing themselves don't need to be able to manage the organization
+		organization, member, ok := getOrganizationMembership(c, params, userID != authenticatedUser.ID)
+		if !ok {
+			return
+		}
+
+		target, isMember := organization.GetMember(userID)
+		if !isMember {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "That user is not a member of this organization"})
+			return
+		}
+
+		if userID != authenticatedUser.ID && !canGrantRole(c, member, target.Role) {
+			return
+		}
+
+		if target.Role == models.OrganizationRoleOwner && countOwners(organization) <= 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An organization needs at least one owner, delete the organization instead"})
+			return
+		}
+
+		if _, err := params.MongoService.RemoveOrganizationMember(c, organization.ID, userID); err != nil {
+			logger.Error("Failed to remove organization member", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+	}
+}
+
+// getOrganizationMembership loads the organization in the URL and the user's membership of it, writing the response
+// if it doesn't exist or the user isn't allowed. manage requires the user to be an owner or admin.
+func getOrganizationMembership(c *gin.Context, params *types.RouteParams, manage bool) (*models.Organization, models.OrganizationMember, bool) {
+	organizationID, err := primitive.ObjectIDFromHex(c.Param("organization_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organization ID"})
+		return nil, models.OrganizationMember{}, false
+	}
+
+	authenticatedUser, ok := utils.GetUserFromContext(c, true)
+	if !ok {
+		return nil, models.OrganizationMember{}, false
+	}
+
+	// Organizations aren't part of any event, so event scoped API keys can't touch them
+	if apiKey, ok := utils.GetAPIKeyFromContext(c); ok && apiKey.IsEventScoped() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This API key is limited to a single event"})
+		return nil, models.OrganizationMember{}, false
+	}
+
+	organization, err := params.MongoService.GetOrganization(c, organizationID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return nil, models.OrganizationMember{}, false
+	}
+
+	member, isMember := organization.GetMember(authenticatedUser.ID)
+	if !isMember {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Organization not found"})
+		return nil, models.OrganizationMember{}, false
+	}
+
+	if manage && !member.CanManage() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not allowed to manage this organization"})
+		return nil, models.OrganizationMember{}, false
+	}
+
+	return organization, member, true
+}
+
+// canGrantRole checks the member can give or take away the role, writing the response if they can't
+func canGrantRole(c *gin.Context, member models.OrganizationMember, role models.OrganizationRole) bool {
+	if !models.IsValidOrganizationRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return false
+	}
+
+	if role == models.OrganizationRoleOwner && member.Role != models.OrganizationRoleOwner {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Only owners can manage the organization's owners"})
+		return false
+	}
+
+	return true
+}
+
+func countOwners(organization *models.Organization) int {
+	owners := 0
+	for _, member := range organization.Members {
+		if member.Role == models.OrganizationRoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
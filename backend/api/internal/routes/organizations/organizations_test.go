@@ -0,0 +1,84 @@
+package organizations
+
+import (
+	"api/internal/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockOrganizationService only implements what creating an organization and its subscription needs
+type mockOrganizationService struct {
+	mongodb.MongoService
+	created       int64
+	organizations []models.Organization
+	subscriptions []models.Subscription
+}
+
+func (m *mockOrganizationService) CountOrganizationsCreatedBy(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return m.created, nil
+}
+
+func (m *mockOrganizationService) ListPlans(ctx context.Context, filter bson.M) ([]models.Plan, error) {
+	return []models.Plan{{ID: primitive.NewObjectID(), Default: true, Limits: models.PlanLimits{MaxEvents: 5}}}, nil
+}
+
+func (m *mockOrganizationService) CreateNewSubscription(ctx context.Context, subscription models.Subscription) (*mongo.InsertOneResult, error) {
+	m.subscriptions = append(m.subscriptions, subscription)
+	return &mongo.InsertOneResult{InsertedID: primitive.NewObjectID()}, nil
+}
+
+func (m *mockOrganizationService) CreateOrganization(ctx context.Context, organization models.Organization) (*mongo.InsertOneResult, error) {
+	m.organizations = append(m.organizations, organization)
+	return &mongo.InsertOneResult{InsertedID: organization.ID}, nil
+}
+
+func TestCreateOrganizationLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		created       int64
+		platformAdmin bool
+		expected      int
+	}{
+		{"Below the limit", maxCreatedOrganizations - 1, false, http.StatusOK},
+		// Each organization comes with free events, so this is what stops anyone getting unlimited ones
+		{"At the limit", maxCreatedOrganizations, false, http.StatusForbidden},
+		{"Platform admin past the limit", maxCreatedOrganizations, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockOrganizationService{created: tt.created}
+			router := gin.New()
+			router.POST("/organizations", func(c *gin.Context) {
+				c.Set("user", &models.User{ID: primitive.NewObjectID(), IsPlatformAdmin: tt.platformAdmin})
+			}, createOrganizationHandler(&types.RouteParams{MongoService: m}))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/organizations", strings.NewReader(`{"name":"Chess club"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected == http.StatusOK {
+				assert.Len(t, m.organizations, 1)
+				assert.Len(t, m.subscriptions, 1)
+			} else {
+				assert.Empty(t, m.organizations)
+				assert.Empty(t, m.subscriptions, "no subscription is created for an organization that isn't")
+			}
+		})
+	}
+}
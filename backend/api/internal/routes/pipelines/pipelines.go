@@ -1,6 +1,7 @@
 package pipelines
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/types"
 	"fmt"
@@ -19,12 +20,12 @@ import (
 )
 
 func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
-	r.GET(":pipeline_id", middlewares.JWTAuthMiddleware(), getPipelineConfigHandler(params))
-	r.POST("", middlewares.JWTAuthMiddleware(), createPipelineConfigHandler(params))
-	r.PUT(":pipeline_id", middlewares.JWTAuthMiddleware(), updatePipelineConfigHandler(params))
-	r.DELETE(":pipeline_id", middlewares.JWTAuthMiddleware(), deletePipelineConfigHandler(params))
+	r.GET(":pipeline_id", middlewares.JWTAuthMiddleware(params.MongoService), getPipelineConfigHandler(params))
+	r.POST("", middlewares.JWTAuthMiddleware(params.MongoService), createPipelineConfigHandler(params))
+	r.PUT(":pipeline_id", middlewares.JWTAuthMiddleware(params.MongoService), updatePipelineConfigHandler(params))
+	r.DELETE(":pipeline_id", middlewares.JWTAuthMiddleware(params.MongoService), deletePipelineConfigHandler(params))
 
-	r.GET(":pipeline_id/runs", middlewares.JWTAuthMiddleware(), getPipelineRunsHandler(params))
+	r.GET(":pipeline_id/runs", middlewares.JWTAuthMiddleware(params.MongoService), getPipelineRunsHandler(params))
 }
 
 func getPipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
@@ -46,8 +47,8 @@ func getPipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		canModify := mongodb.CanUserModifyPipeline(c, params.MongoService, authenticatedUser, primitive.NilObjectID, pipelineConfig)
-		if !canModify {
+		canRead := mongodb.UserHasPipelineCapability(c, params.MongoService, authenticatedUser, primitive.NilObjectID, pipelineConfig, models.CapabilityPipelinesRead)
+		if !canRead {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to view this pipeline"})
 			return
 		}
@@ -70,15 +71,16 @@ func createPipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		event, err := params.MongoService.GetEvent(c, req.EventID)
+		event, err := params.MongoService.FindEventByID(c, req.EventID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
 
-		canUserModifyEvent := mongodb.CanUserModifyEvent(c, params.MongoService, authenticatedUser, primitive.NilObjectID, event)
-		if !canUserModifyEvent {
+		canCreate := mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, primitive.NilObjectID, event, models.CapabilityPipelinesWrite)
+		if !canCreate {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You cannot create a pipeline on this event"})
+			return
 		}
 
 		pipelineID, err := params.MongoService.CreatePipeline(c, req)
@@ -87,6 +89,14 @@ func createPipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    req.EventID,
+			Action:     models.AuditPipelineCreate,
+			TargetType: models.AuditTargetPipeline,
+			TargetID:   pipelineID.InsertedID.(primitive.ObjectID).Hex(),
+			Diff:       helpers.AuditDiff(nil, req),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"id": pipelineID})
 	}
 }
@@ -117,7 +127,7 @@ func updatePipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Pipeline configuration not found"})
 			return
 		}
-		if !mongodb.CanUserModifyPipeline(c, params.MongoService, authenticatedUser, pipelineID, pipelineConfig) {
+		if !mongodb.UserHasPipelineCapability(c, params.MongoService, authenticatedUser, pipelineID, pipelineConfig, models.CapabilityPipelinesWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to update this pipeline"})
 			return
 		}
@@ -136,6 +146,14 @@ func updatePipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    pipelineConfig.EventID,
+			Action:     models.AuditPipelineUpdate,
+			TargetType: models.AuditTargetPipeline,
+			TargetID:   pipelineID.Hex(),
+			Diff:       helpers.AuditDiff(pipelineConfig, req),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Pipeline configuration updated successfully", "lastUpdatedAt": newLastUpdatedAt})
 	}
 }
@@ -153,7 +171,13 @@ func deletePipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyPipeline(c, params.MongoService, authenticatedUser, pipelineID, nil) {
+		pipelineConfig, err := params.MongoService.GetPipeline(c, pipelineID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pipeline configuration not found"})
+			return
+		}
+
+		if !mongodb.UserHasPipelineCapability(c, params.MongoService, authenticatedUser, pipelineID, pipelineConfig, models.CapabilityPipelinesWrite) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to delete this pipeline"})
 			return
 		}
@@ -164,6 +188,14 @@ func deletePipelineConfigHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		helpers.Audit(c, params.MongoService, models.AuditEntry{
+			EventID:    pipelineConfig.EventID,
+			Action:     models.AuditPipelineDelete,
+			TargetType: models.AuditTargetPipeline,
+			TargetID:   pipelineID.Hex(),
+			Diff:       helpers.AuditDiff(pipelineConfig, nil),
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Pipeline configuration deleted successfully"})
 	}
 }
@@ -181,7 +213,7 @@ func getPipelineRunsHandler(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
-		if !mongodb.CanUserModifyPipeline(c, params.MongoService, authenticatedUser, pipelineID, nil) {
+		if !mongodb.UserHasPipelineCapability(c, params.MongoService, authenticatedUser, pipelineID, nil, models.CapabilityPipelinesRead) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to view this pipeline's runs"})
 			return
 		}
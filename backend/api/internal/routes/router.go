@@ -1,14 +1,17 @@
 package routes
 
 import (
+	"api/internal/routes/admin"
 	"api/internal/routes/auth"
 	"api/internal/routes/emails"
 	"api/internal/routes/events"
 	"api/internal/routes/forms"
+	"api/internal/routes/organizations"
 	"api/internal/routes/pipelines"
 	"api/internal/routes/users"
 	"api/internal/types"
 	"net/http"
+	"shared/logger"
 	"shared/utils"
 
 	"github.com/gin-gonic/gin"
@@ -35,9 +38,29 @@ func SetupRoutes(r *gin.Engine, params *types.RouteParams) {
 	emailTemplateGroup := r.Group("/email_templates")
 	emails.RegisterEmailTemplateRoutes(emailTemplateGroup, params)
 
+	organizationGroup := r.Group("/organizations")
+	organizations.RegisterRoutes(organizationGroup, params)
+
+	adminGroup := r.Group("/admin")
+	admin.RegisterRoutes(adminGroup, params)
+
 	r.GET("/version", getVersion)
+	r.GET("/.well-known/jwks.json", getJWKS)
 }
 
 func getVersion(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"version": utils.VERSION})
 }
+
+// getJWKS publishes the public keys access tokens are signed with so other services can verify them
+func getJWKS(c *gin.Context) {
+	keySet, err := utils.PublicJWKS()
+	if err != nil {
+		logger.Error("Failed to load JWT signing keys", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load signing keys"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keySet)
+}
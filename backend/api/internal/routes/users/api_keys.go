@@ -0,0 +1,131 @@
+package users
+
+import (
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyPrefixLength is how much of a key we keep in plain text so users can recognise it
+const apiKeyPrefixLength = 10
+
+type createAPIKeyRequest struct {
+	Name      string             `json:"name" validate:"required,max=100"`
+	EventID   primitive.ObjectID `json:"eventID"`   // Optional, limits the key to a single event
+	ReadOnly  bool               `json:"readOnly"`  // Read-only keys can only make GET requests
+	ExpiresAt time.Time          `json:"expiresAt"` // Optional, RFC3339
+}
+
+// createAPIKey creates a personal API key, the key itself is only returned in this response
+func createAPIKey(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		var req createAPIKeyRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		if !req.ExpiresAt.IsZero() && req.ExpiresAt.Before(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+			return
+		}
+
+		if !req.EventID.IsZero() && !mongodb.UserHasEventCapability(c, params.MongoService, authenticatedUser, req.EventID, nil, models.CapabilityEventRead) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not an organizer of that event"})
+			return
+		}
+
+		key, err := utils.GenerateAPIKey()
+		if err != nil {
+			logger.Error("Failed to generate API key", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+			return
+		}
+
+		apiKey := models.APIKey{
+			UserID:    authenticatedUser.ID,
+			Name:      req.Name,
+			Prefix:    key[:apiKeyPrefixLength],
+			KeyHash:   utils.HashToken(key),
+			EventID:   req.EventID,
+			ReadOnly:  req.ReadOnly,
+			CreatedAt: time.Now(),
+			ExpiresAt: req.ExpiresAt,
+		}
+
+		res, err := params.MongoService.CreateAPIKey(c, apiKey)
+		if err != nil {
+			logger.Error("Failed to store API key", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+			return
+		}
+		apiKey.ID = res.InsertedID.(primitive.ObjectID)
+
+		c.JSON(http.StatusOK, gin.H{"apiKey": apiKey, "key": key})
+	}
+}
+
+// listAPIKeys lists the authenticated user's API keys that haven't been revoked
+func listAPIKeys(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		keys, err := params.MongoService.ListUserAPIKeys(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"apiKeys": keys})
+	}
+}
+
+// revokeAPIKey revokes one of the authenticated user's API keys
+func revokeAPIKey(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		keyID, err := primitive.ObjectIDFromHex(c.Param("key_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID"})
+			return
+		}
+
+		result, err := params.MongoService.RevokeAPIKey(c, authenticatedUser.ID, keyID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+	}
+}
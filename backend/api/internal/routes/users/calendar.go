@@ -0,0 +1,171 @@
+package users
+
+import (
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// calendarFeedTokenBytes is the entropy of calendar feed tokens, the token is the only thing protecting the feed
+const calendarFeedTokenBytes = 32
+
+// createCalendarFeed creates the user's calendar feed, or replaces its URL if they already have one.
+// The feed URL is only returned in this response.
+func createCalendarFeed(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		token, err := utils.GenerateSecureToken(calendarFeedTokenBytes)
+		if err != nil {
+			logger.Error("Failed to generate calendar feed token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+			return
+		}
+
+		if _, err := params.MongoService.SetUserCalendarFeedToken(c, authenticatedUser.ID, utils.HashToken(token)); err != nil {
+			logger.Error("Failed to set calendar feed token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Calendar feed created, any previous feed URL no longer works",
+			"path":    "/users/calendar-feed/" + token + ".ics",
+		})
+	}
+}
+
+// deleteCalendarFeed turns off the user's calendar feed
+func deleteCalendarFeed(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		if _, err := params.MongoService.SetUserCalendarFeedToken(c, authenticatedUser.ID, ""); err != nil {
+			logger.Error("Failed to remove calendar feed token", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete calendar feed"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Calendar feed deleted"})
+	}
+}
+
+// getCalendarFeed returns a user's calendar feed, the events they organize or have applied to and the sessions they registered for.
+// Calendar apps can't log in so the token in the URL is what authenticates the request.
+func getCalendarFeed(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+		user, err := params.MongoService.FindUserByCalendarFeedToken(c, utils.HashToken(token))
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+				return
+			}
+			logger.Error("Failed to find calendar feed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar feed"})
+			return
+		}
+		if user.Disabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+
+		sessions, err := params.MongoService.ListUserScheduleItems(c, user.ID)
+		if err != nil {
+			logger.Error("Failed to list calendar feed sessions", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar feed"})
+			return
+		}
+
+		sessionEventIDs := []primitive.ObjectID{}
+		for _, session := range sessions {
+			sessionEventIDs = append(sessionEventIDs, session.EventID)
+		}
+
+		events, err := calendarFeedEvents(c, params.MongoService, user, sessionEventIDs)
+		if err != nil {
+			logger.Error("Failed to list calendar feed events", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get calendar feed"})
+			return
+		}
+
+		calendar := utils.Calendar{Name: "ApplicantAtlas", Events: []utils.CalendarEvent{}}
+		eventsByID := make(map[primitive.ObjectID]*models.Event)
+		for i, event := range events {
+			eventsByID[event.ID] = &events[i]
+			if calendarEvent, ok := utils.NewEventCalendarEvent(&event, utils.WebsiteURL("/events/"+event.ID.Hex()+"/participant")); ok {
+				calendar.Events = append(calendar.Events, calendarEvent)
+			}
+		}
+
+		for i, session := range sessions {
+			// Sessions of events that went back to being drafts are left out along with the event
+			if event, ok := eventsByID[session.EventID]; ok {
+				calendar.Events = append(calendar.Events, utils.NewScheduleItemCalendarEvent(&sessions[i], event))
+			}
+		}
+
+		c.Data(http.StatusOK, utils.CalendarContentType, calendar.Encode(time.Now()))
+	}
+}
+
+// calendarFeedEvents lists the events the user organizes, has responded to a form of or are in eventIDs, drafts are left out
+func calendarFeedEvents(c *gin.Context, m mongodb.MongoService, user *models.User, eventIDs []primitive.ObjectID) ([]models.Event, error) {
+	organizing, _, err := m.ListEventsMetadata(c, mongodb.EventListOptions{OrganizerID: user.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := m.ListResponses(c, bson.M{"userID": user.ID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	formIDs := []primitive.ObjectID{}
+	for _, response := range responses {
+		formIDs = append(formIDs, response.FormID)
+	}
+
+	if len(formIDs) > 0 {
+		forms, err := m.ListForms(c, bson.M{"_id": bson.M{"$in": formIDs}})
+		if err != nil {
+			return nil, err
+		}
+		for _, form := range forms {
+			eventIDs = append(eventIDs, form.EventID)
+		}
+	}
+
+	applied, _, err := m.ListEventsMetadata(c, mongodb.EventListOptions{IDs: eventIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[primitive.ObjectID]bool)
+	events := []models.Event{}
+	for _, event := range append(organizing, applied...) {
+		if seen[event.ID] || event.GetStatus() == models.EventStatusDraft {
+			continue
+		}
+		seen[event.ID] = true
+		events = append(events, event)
+	}
+	return events, nil
+}
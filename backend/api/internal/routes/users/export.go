@@ -0,0 +1,194 @@
+package users
+
+import (
+	"api/internal/types"
+	"fmt"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/mongodb"
+	"shared/utils"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userDataExport is everything we hold about a user, returned by GET /users/me/export
+type userDataExport struct {
+	ExportedAt time.Time            `json:"exportedAt"`
+	Profile    *models.User         `json:"profile"`
+	Responses  []exportedResponse   `json:"responses"`
+	FormAccess []exportedFormAccess `json:"formAccess"` // Empty until the user's email is verified
+	Emails     []models.SentEmail   `json:"emails"`     // Empty until the user's email is verified
+}
+
+type exportedResponse struct {
+	ID            primitive.ObjectID `json:"id"`
+	FormID        primitive.ObjectID `json:"formID"`
+	FormName      string             `json:"formName"`
+	EventID       primitive.ObjectID `json:"eventID,omitempty"`
+	EventName     string             `json:"eventName,omitempty"`
+	SubmittedAt   time.Time          `json:"submittedAt"`
+	LastUpdatedAt time.Time          `json:"lastUpdatedAt"`
+	Answers       []exportedAnswer   `json:"answers"`
+}
+
+type exportedAnswer struct {
+	Key      string      `json:"key"`
+	Question string      `json:"question"` // Empty if the question has since been removed from the form
+	Answer   interface{} `json:"answer"`
+	Internal bool        `json:"internal,omitempty"` // Filled in by the event's organizers rather than the user
+}
+
+type exportedFormAccess struct {
+	FormID    primitive.ObjectID `json:"formID"`
+	FormName  string             `json:"formName"`
+	EventID   primitive.ObjectID `json:"eventID"`
+	EventName string             `json:"eventName"`
+	ExpiresAt time.Time          `json:"expiresAt,omitempty"`
+}
+
+// exportUserData returns a JSON file of the user's profile, form responses, form access grants and the emails events have sent them
+func exportUserData(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return // Error is handled in GetUserFromContext
+		}
+
+		// Unlike GetUserDetails this keeps their birthday, secrets are still left out by the json tags
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+			return
+		}
+
+		responses, err := params.MongoService.ListResponses(c, bson.M{"userID": user.ID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+		if err != nil {
+			logger.Error("Failed to list responses for export", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+
+		formIDs := []primitive.ObjectID{}
+		for _, response := range responses {
+			formIDs = append(formIDs, response.FormID)
+		}
+
+		// Forms they've been given access to are looked up by email since that's how access is granted.
+		// Only a verified email is trusted, anyone can change their email to someone else's address.
+		// Deleted forms are included since their responses are kept until the form is purged.
+		formsFilter := []bson.M{{"_id": bson.M{"$in": formIDs}}}
+		if user.EmailVerified {
+			formsFilter = append(formsFilter, bson.M{"allowedSubmitters.email": user.Email})
+		}
+		forms, err := params.MongoService.ListForms(c, bson.M{"$or": formsFilter, "isDeleted": mongodb.IncludeDeleted})
+		if err != nil {
+			logger.Error("Failed to list forms for export", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+
+		formsByID := make(map[primitive.ObjectID]models.FormStructure)
+		eventIDs := []primitive.ObjectID{}
+		for _, form := range forms {
+			formsByID[form.ID] = form
+			eventIDs = append(eventIDs, form.EventID)
+		}
+
+		events, _, err := params.MongoService.ListEventsMetadata(c, mongodb.EventListOptions{IDs: eventIDs})
+		if err != nil {
+			logger.Error("Failed to list events for export", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+			return
+		}
+
+		eventNames := make(map[primitive.ObjectID]string)
+		for _, event := range events {
+			eventNames[event.ID] = event.Metadata.Name
+		}
+
+		emails := []models.SentEmail{}
+		if user.EmailVerified {
+			emails, err = params.MongoService.ListSentEmails(c, user.Email)
+			if err != nil {
+				logger.Error("Failed to list sent emails for export", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
+				return
+			}
+		}
+
+		export := userDataExport{
+			ExportedAt: time.Now(),
+			Profile:    user,
+			Responses:  []exportedResponse{},
+			FormAccess: []exportedFormAccess{},
+			Emails:     emails,
+		}
+
+		for _, response := range responses {
+			form := formsByID[response.FormID]
+			export.Responses = append(export.Responses, exportedResponse{
+				ID:            response.ID,
+				FormID:        response.FormID,
+				FormName:      form.Name,
+				EventID:       form.EventID,
+				EventName:     eventNames[form.EventID],
+				SubmittedAt:   response.CreatedAt,
+				LastUpdatedAt: response.LastUpdatedAt,
+				Answers:       exportAnswers(form, response),
+			})
+		}
+
+		for _, form := range forms {
+			for _, submitter := range form.AllowedSubmitters {
+				if !user.EmailVerified || submitter.Email != user.Email {
+					continue
+				}
+				export.FormAccess = append(export.FormAccess, exportedFormAccess{
+					FormID:    form.ID,
+					FormName:  form.Name,
+					EventID:   form.EventID,
+					EventName: eventNames[form.EventID],
+					ExpiresAt: submitter.ExpiresAt,
+				})
+			}
+		}
+
+		filename := fmt.Sprintf("applicantatlas-export-%s.json", export.ExportedAt.Format("2006-01-02"))
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.IndentedJSON(http.StatusOK, export)
+	}
+}
+
+// exportAnswers pairs each answer with its question, in the order the form asks them followed by answers to removed questions
+func exportAnswers(form models.FormStructure, response models.FormResponse) []exportedAnswer {
+	answers := []exportedAnswer{}
+	seen := make(map[string]struct{})
+	for _, attr := range form.Attrs {
+		value, exists := response.Data[attr.Key]
+		if !exists {
+			continue
+		}
+		answers = append(answers, exportedAnswer{Key: attr.Key, Question: attr.Question, Answer: value, Internal: attr.IsInternal})
+		seen[attr.Key] = struct{}{}
+	}
+
+	removedKeys := []string{}
+	for key := range response.Data {
+		if _, exists := seen[key]; !exists {
+			removedKeys = append(removedKeys, key)
+		}
+	}
+	sort.Strings(removedKeys)
+
+	for _, key := range removedKeys {
+		answers = append(answers, exportedAnswer{Key: key, Answer: response.Data[key]})
+	}
+
+	return answers
+}
@@ -0,0 +1,63 @@
+package users
+
+import (
+	"shared/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportAnswers(t *testing.T) {
+	form := models.FormStructure{
+		Attrs: []models.FormField{
+			{Key: "name", Question: "What's your name?"},
+			{Key: "shirt", Question: "Shirt size"},
+			{Key: "score", Question: "Reviewer score", IsInternal: true},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		data     map[string]interface{}
+		expected []exportedAnswer
+	}{
+		{
+			"Answers follow the form's order",
+			map[string]interface{}{"shirt": "M", "name": "Ada"},
+			[]exportedAnswer{
+				{Key: "name", Question: "What's your name?", Answer: "Ada"},
+				{Key: "shirt", Question: "Shirt size", Answer: "M"},
+			},
+		},
+		{
+			"Unanswered questions are left out",
+			map[string]interface{}{"shirt": "M"},
+			[]exportedAnswer{{Key: "shirt", Question: "Shirt size", Answer: "M"}},
+		},
+		{
+			// The question is gone but the answer is still stored, so it's exported without one
+			"Removed questions come last, sorted by key",
+			map[string]interface{}{"zeta": "z", "name": "Ada", "alpha": "a"},
+			[]exportedAnswer{
+				{Key: "name", Question: "What's your name?", Answer: "Ada"},
+				{Key: "alpha", Answer: "a"},
+				{Key: "zeta", Answer: "z"},
+			},
+		},
+		{
+			"Internal fields are marked as filled in by organizers",
+			map[string]interface{}{"name": "Ada", "score": 4},
+			[]exportedAnswer{
+				{Key: "name", Question: "What's your name?", Answer: "Ada"},
+				{Key: "score", Question: "Reviewer score", Answer: 4, Internal: true},
+			},
+		},
+		{"No answers", map[string]interface{}{}, []exportedAnswer{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, exportAnswers(form, models.FormResponse{Data: tt.data}))
+		})
+	}
+}
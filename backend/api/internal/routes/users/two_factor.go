@@ -0,0 +1,211 @@
+package users
+
+import (
+	"api/internal/helpers"
+	"api/internal/types"
+	"net/http"
+	"shared/logger"
+	"shared/models"
+	"shared/utils"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const totpIssuer = "ApplicantAtlas"
+
+// setupTwoFactor starts TOTP enrollment, 2FA isn't enabled until a code is confirmed with confirmTwoFactor
+func setupTwoFactor(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+			return
+		}
+
+		if user.TwoFactor.Enabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+
+		secret, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			logger.Error("Failed to generate TOTP secret", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up two-factor authentication"})
+			return
+		}
+
+		if err := params.MongoService.SetUserTwoFactorPendingSecret(c, user.ID, secret); err != nil {
+			logger.Error("Failed to store pending TOTP secret", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up two-factor authentication"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"secret":          secret,
+			"provisioningURI": utils.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+		})
+	}
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// confirmTwoFactor enables 2FA once the user proves their authenticator works, recovery codes are only returned here
+func confirmTwoFactor(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		var req twoFactorCodeRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+			return
+		}
+
+		if user.TwoFactor.Enabled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is already enabled"})
+			return
+		}
+
+		if user.TwoFactor.PendingSecret == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication setup has not been started"})
+			return
+		}
+
+		step, ok := utils.ValidateTOTPCode(user.TwoFactor.PendingSecret, req.Code, time.Now(), 0)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor authentication code"})
+			return
+		}
+
+		recoveryCodes, recoveryCodeHashes, err := helpers.GenerateRecoveryCodes()
+		if err != nil {
+			logger.Error("Failed to generate recovery codes", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+			return
+		}
+
+		result, err := params.MongoService.EnableUserTwoFactor(c, user.ID, user.TwoFactor.PendingSecret, recoveryCodeHashes, step)
+		if err != nil {
+			logger.Error("Failed to enable two-factor authentication", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+			return
+		}
+
+		// The pending secret changed from under us, setup was restarted in another tab
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication setup was restarted, please try again"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recoveryCodes": recoveryCodes})
+	}
+}
+
+// disableTwoFactor turns off 2FA, a current code or recovery code is required
+func disableTwoFactor(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := verifyTwoFactorRequest(c, params)
+		if !ok {
+			return
+		}
+
+		if err := params.MongoService.DisableUserTwoFactor(c, user.ID); err != nil {
+			logger.Error("Failed to disable two-factor authentication", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+	}
+}
+
+// regenerateRecoveryCodes replaces the user's recovery codes, a current code or recovery code is required
+func regenerateRecoveryCodes(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := verifyTwoFactorRequest(c, params)
+		if !ok {
+			return
+		}
+
+		recoveryCodes, recoveryCodeHashes, err := helpers.GenerateRecoveryCodes()
+		if err != nil {
+			logger.Error("Failed to generate recovery codes", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+			return
+		}
+
+		if err := params.MongoService.SetUserTwoFactorRecoveryCodes(c, user.ID, recoveryCodeHashes); err != nil {
+			logger.Error("Failed to store recovery codes", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"recoveryCodes": recoveryCodes})
+	}
+}
+
+// verifyTwoFactorRequest loads the authenticated user and checks the 2FA code in the request body, writing the response on failure
+func verifyTwoFactorRequest(c *gin.Context, params *types.RouteParams) (*models.User, bool) {
+	authenticatedUser, ok := utils.GetUserFromContext(c, true)
+	if !ok {
+		return nil, false
+	}
+
+	var req twoFactorCodeRequest
+	if err := utils.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+		return nil, false
+	}
+
+	user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+		return nil, false
+	}
+
+	if !user.TwoFactor.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return nil, false
+	}
+
+	valid, err := helpers.VerifyTwoFactorCode(c, params.MongoService, user, req.Code)
+	if err != nil {
+		logger.Error("Failed to verify two-factor code", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify two-factor authentication code"})
+		return nil, false
+	}
+
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid two-factor authentication code"})
+		return nil, false
+	}
+
+	return user, true
+}
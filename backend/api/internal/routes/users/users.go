@@ -1,22 +1,45 @@
 package users
 
 import (
+	"api/internal/helpers"
 	"api/internal/middlewares"
 	"api/internal/types"
 	"net/http"
+	"shared/logger"
 	"shared/models"
+	"shared/mongodb"
 	"shared/utils"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // RegisterRoutes sets up the routes for user management
 func RegisterRoutes(r *gin.RouterGroup, params *types.RouteParams) {
-	r.GET("/me", middlewares.JWTAuthMiddleware(), getUserMyself(params))
-	r.PUT("/me", middlewares.JWTAuthMiddleware(), updateUserMyself(params))
-	r.GET("/me/subscription", middlewares.JWTAuthMiddleware(), getSubscriptionUtilization(params))
+	r.GET("/me", middlewares.JWTAuthMiddleware(params.MongoService), getUserMyself(params))
+	r.GET("/me/subscription", middlewares.JWTAuthMiddleware(params.MongoService), getSubscriptionUtilization(params))
+
+	// Account management can't be done with an API key
+	account := r.Group("/me", middlewares.JWTAuthMiddleware(params.MongoService), middlewares.SessionOnlyMiddleware())
+	account.PUT("", updateUserMyself(params))
+	account.PUT("/password", changePasswordMyself(params))
+	account.POST("/2fa/setup", setupTwoFactor(params))
+	account.POST("/2fa/confirm", confirmTwoFactor(params))
+	account.POST("/2fa/disable", disableTwoFactor(params))
+	account.POST("/2fa/recovery-codes", regenerateRecoveryCodes(params))
+	account.GET("/api-keys", listAPIKeys(params))
+	account.POST("/api-keys", createAPIKey(params))
+	account.DELETE("/api-keys/:key_id", revokeAPIKey(params))
+	account.GET("/export", middlewares.NoImpersonationMiddleware(), exportUserData(params))
+	account.POST("/calendar-feed", createCalendarFeed(params))
+	account.DELETE("/calendar-feed", deleteCalendarFeed(params))
+
+	// Calendar apps fetch the feed without logging in, the token in the URL authenticates it
+	r.GET("/calendar-feed/:token", getCalendarFeed(params))
+
 	r.GET("/:id", getUserDetails(params))
 
 }
@@ -28,7 +51,8 @@ func getUserMyself(params *types.RouteParams) gin.HandlerFunc {
 			return // Error is handled in GetUserFromContext
 		}
 
-		user, err := params.MongoService.FindUserByEmail(c, authenticatedUser.Email)
+		// Looked up by ID since the email in the token can be stale after the user changes it
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
 			return
@@ -77,10 +101,95 @@ func updateUserMyself(params *types.RouteParams) gin.HandlerFunc {
 			return
 		}
 
+		currentUser, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+			return
+		}
+
+		// Changing email requires the new address to be verified again
+		if updatedUserDetails.Email != currentUser.Email {
+			err = params.MongoService.UpdateUserEmail(c, authenticatedUser.ID, updatedUserDetails.Email)
+			if err != nil {
+				if err == mongodb.ErrUserAlreadyExists {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "An account with that email already exists"})
+					return
+				}
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email"})
+				return
+			}
+
+			currentUser.Email = updatedUserDetails.Email
+			if err := helpers.SendEmailVerification(c, params.MongoService, currentUser); err != nil {
+				logger.Error("Failed to send verification email", err)
+			}
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "User details updated successfully"})
 	}
 }
 
+type changePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required,securepwd"`
+}
+
+// changePasswordMyself changes the password of the authenticated user, every other session is logged out
+func changePasswordMyself(params *types.RouteParams) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticatedUser, ok := utils.GetUserFromContext(c, true)
+		if !ok {
+			return
+		}
+
+		var req changePasswordRequest
+		if err := utils.BindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors := utils.ValidateStruct(utils.Validator, req); len(errors) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": strings.Join(errors, "\n")})
+			return
+		}
+
+		user, err := params.MongoService.FindUserByID(c, authenticatedUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user details"})
+			return
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Current password is incorrect"})
+			return
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+
+		if err := params.MongoService.UpdateUserPassword(c, user.ID, string(hash)); err != nil {
+			logger.Error("Failed to update password", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+			return
+		}
+
+		// Any outstanding reset links and other logins shouldn't survive a password change
+		if _, err := params.MongoService.InvalidateUserTokens(c, user.ID, models.UserTokenPasswordReset); err != nil {
+			logger.Error("Failed to invalidate password reset tokens", err)
+		}
+
+		sessionID, _ := utils.GetSessionIDFromContext(c)
+		if _, err := params.MongoService.RevokeOtherUserSessions(c, user.ID, sessionID); err != nil {
+			logger.Error("Failed to revoke other sessions after password change", err)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Password updated successfully"})
+	}
+}
+
 // get user details
 func getUserDetails(params *types.RouteParams) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"api/internal/types"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"shared/models"
+	"shared/mongodb"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mockPasswordService only implements the lookups and updates changing a password needs
+type mockPasswordService struct {
+	mongodb.MongoService
+	user              models.User
+	updatedHash       string
+	invalidatedTokens []models.UserTokenType
+	keptSessionID     *primitive.ObjectID
+}
+
+func (m *mockPasswordService) FindUserByID(ctx context.Context, userId primitive.ObjectID) (*models.User, error) {
+	if userId != m.user.ID {
+		return nil, mongo.ErrNoDocuments
+	}
+	user := m.user
+	return &user, nil
+}
+
+func (m *mockPasswordService) UpdateUserPassword(ctx context.Context, userId primitive.ObjectID, passwordHash string) error {
+	m.updatedHash = passwordHash
+	return nil
+}
+
+func (m *mockPasswordService) InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType) (*mongo.UpdateResult, error) {
+	m.invalidatedTokens = append(m.invalidatedTokens, tokenType)
+	return &mongo.UpdateResult{}, nil
+}
+
+func (m *mockPasswordService) RevokeOtherUserSessions(ctx context.Context, userID primitive.ObjectID, keepSessionID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	m.keptSessionID = &keepSessionID
+	return &mongo.UpdateResult{}, nil
+}
+
+func TestChangePasswordMyself(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	currentHash, err := bcrypt.GenerateFromPassword([]byte("Old-password-1!"), bcrypt.MinCost)
+	if !assert.NoError(t, err) {
+		return
+	}
+	user := models.User{ID: primitive.NewObjectID(), PasswordHash: string(currentHash)}
+	sessionID := primitive.NewObjectID()
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		expected        int
+	}{
+		{"Changes the password", "Old-password-1!", "New-password-2@", http.StatusOK},
+		{"Wrong current password", "Not-my-password-1!", "New-password-2@", http.StatusBadRequest},
+		{"Weak new password", "Old-password-1!", "short", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockPasswordService{user: user}
+			router := gin.New()
+			router.PUT("/password", func(c *gin.Context) {
+				c.Set("user", &models.User{ID: user.ID})
+				c.Set("sessionID", sessionID)
+			}, changePasswordMyself(&types.RouteParams{MongoService: m}))
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/password", strings.NewReader(`{"currentPassword":"`+tt.currentPassword+`","newPassword":"`+tt.newPassword+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+			if tt.expected != http.StatusOK {
+				assert.Empty(t, m.updatedHash)
+				assert.Nil(t, m.keptSessionID, "sessions are left alone when the password isn't changed")
+				return
+			}
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(m.updatedHash), []byte(tt.newPassword)))
+			assert.Equal(t, []models.UserTokenType{models.UserTokenPasswordReset}, m.invalidatedTokens, "outstanding reset links stop working")
+			if assert.NotNil(t, m.keptSessionID) {
+				assert.Equal(t, sessionID, *m.keptSessionID, "only the session that changed the password stays logged in")
+			}
+		})
+	}
+}
@@ -35,6 +35,7 @@ func main() {
 		"SendEmail":       handlers.NewSendEmailHandler(mongoService),
 		"AllowFormAccess": handlers.NewAllowFormAccessHandler(mongoService),
 		"Webhook":         handlers.NewWebhookHandler(mongoService),
+		"DeleteAccount":   handlers.NewDeleteAccountHandler(mongoService),
 	}
 
 	messageConsumer, err := consumer.NewMessageConsumer(mongoService, actionHandlers)
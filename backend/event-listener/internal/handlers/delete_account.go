@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"shared/kafka"
+	"shared/mongodb"
+)
+
+type DeleteAccountHandler struct {
+	mongo *mongodb.Service
+}
+
+func NewDeleteAccountHandler(mongo *mongodb.Service) *DeleteAccountHandler {
+	return &DeleteAccountHandler{mongo: mongo}
+}
+
+func (s DeleteAccountHandler) HandleAction(action kafka.PipelineActionMessage) error {
+	deleteAccountAction, ok := action.(*kafka.DeleteAccountMessage)
+	if !ok {
+		return errors.New("invalid action type for DeleteAccountHandler")
+	}
+
+	ctx := context.Background()
+	deletion, err := s.mongo.GetAccountDeletion(ctx, deleteAccountAction.AccountDeletionID)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.mongo.StartAccountDeletion(ctx, deletion.ID)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		log.Printf("Account deletion %s already completed, skipping", deletion.ID.Hex())
+		return nil
+	}
+
+	report, jobErr := s.mongo.DeleteUserData(ctx, deletion.UserID, deletion.Email)
+	if err := s.mongo.FinishAccountDeletion(ctx, deletion.ID, report, jobErr); err != nil {
+		return errors.Join(jobErr, err)
+	}
+
+	// Returning the error lets the message be retried, every step of the deletion is safe to repeat
+	return jobErr
+}
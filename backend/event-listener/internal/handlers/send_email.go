@@ -2,17 +2,22 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"log"
 	"net/smtp"
 	"strings"
 	"time"
 
 	"shared/kafka"
+	"shared/models"
 	"shared/mongodb"
+	"shared/utils"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -94,6 +99,26 @@ func (s *SendEmailHandler) HandleAction(action kafka.PipelineActionMessage) erro
 		body = "<html><body>" + body + "</body></html>"
 	}
 
+	if sendEmailAction.AttachCalendarInvite {
+		invite, err := s.calendarInvite(sendEmailAction.EventID)
+		if err != nil {
+			return err
+		}
+
+		// Without a start time there's nothing to put in a calendar, so the email is sent without it
+		if invite != nil {
+			boundary := uuid.NewString()
+			body = "--" + boundary + "\r\n" + contentType + "\r\n" + body + "\r\n" +
+				"--" + boundary + "\r\n" +
+				"Content-Type: text/calendar; charset=\"UTF-8\"; method=PUBLISH; name=\"invite.ics\"\r\n" +
+				"Content-Disposition: attachment; filename=\"invite.ics\"\r\n" +
+				"Content-Transfer-Encoding: base64\r\n\r\n" +
+				wrapBase64(invite) +
+				"--" + boundary + "--\r\n"
+			contentType = "Content-Type: multipart/mixed; boundary=\"" + boundary + "\"\r\n"
+		}
+	}
+
 	// Compile email message
 	message := []byte(subject + from + toHeader + ccHeader + replyTo + dateHeader + messageID + mime + contentType + "\r\n" + body)
 
@@ -115,5 +140,51 @@ func (s *SendEmailHandler) HandleAction(action kafka.PipelineActionMessage) erro
 		return err
 	}
 
+	// The email is already sent so failing the action here would only send it again on retry
+	_, err = s.mongo.RecordSentEmail(context.TODO(), models.SentEmail{
+		EventID:         sendEmailAction.EventID,
+		EmailTemplateID: emailTemplate.ID,
+		PipelineRunID:   sendEmailAction.PipelineRunID,
+		To:              to,
+		From:            emailTemplate.From,
+		Subject:         emailTemplate.Subject,
+		Body:            emailTemplate.Body,
+		IsHTML:          emailTemplate.IsHTML,
+		SentAt:          time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to record sent email: %v", err)
+	}
+
 	return nil
 }
+
+// calendarInvite returns the event as an iCalendar file, or nil if the event doesn't have a start time yet
+func (s *SendEmailHandler) calendarInvite(eventID primitive.ObjectID) ([]byte, error) {
+	event, err := s.mongo.FindEventByID(context.TODO(), eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendarEvent, ok := utils.NewEventCalendarEvent(event, "")
+	if !ok {
+		log.Printf("Not attaching a calendar invite, event %s doesn't have a start time", eventID.Hex())
+		return nil, nil
+	}
+
+	calendar := utils.Calendar{Name: event.Metadata.Name, Timezone: event.Metadata.Timezone, Events: []utils.CalendarEvent{calendarEvent}}
+	return calendar.Encode(time.Now()), nil
+}
+
+// wrapBase64 base64 encodes an attachment in lines of 76 characters as RFC 2045 requires
+func wrapBase64(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.String()
+}
@@ -33,6 +33,11 @@ func ProcessMessage(msgValue []byte, mongoService *mongodb.Service, actionHandle
 			return "action type is not a string"
 		}
 
+		// Account jobs aren't part of a pipeline run, their handlers track their own status
+		if actionTypeStr == kafka.DeleteAccountMessageType {
+			return processAccountMessage(msgValue, actionTypeStr, actionHandlers)
+		}
+
 		// Get the pipeline run ID
 		pipelineRunIDAny, ok := actionTypeMap["pipelineRunID"]
 		if !ok {
@@ -160,3 +165,29 @@ func ProcessMessage(msgValue []byte, mongoService *mongodb.Service, actionHandle
 		return true, nil
 	}
 }
+
+// processAccountMessage runs a job for a user's account, returning an error message if it failed
+func processAccountMessage(msgValue []byte, actionType string, actionHandlers map[string]types.EventHandler) string {
+	handler, ok := actionHandlers[actionType]
+	if !ok {
+		return fmt.Sprintf("No handler found for action type: %s\n", actionType)
+	}
+
+	var action kafka.PipelineActionMessage
+	switch actionType {
+	case kafka.DeleteAccountMessageType:
+		action = new(kafka.DeleteAccountMessage)
+	default:
+		return fmt.Sprintf("No object found for action type: %s\n", actionType)
+	}
+
+	if err := json.Unmarshal(msgValue, &action); err != nil {
+		return fmt.Sprintf("Error unmarshalling %s action: %v\n", actionType, err)
+	}
+
+	if err := handler.HandleAction(action); err != nil {
+		return fmt.Sprintf("Error handling %s action: %v\n", actionType, err)
+	}
+
+	return ""
+}
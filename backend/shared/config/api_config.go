@@ -2,6 +2,7 @@ package config
 
 import (
 	"sync"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
@@ -10,12 +11,27 @@ type APIConfig struct {
 	// MESSAGE_BROKER_TYPE is the type of message broker to use
 	MESSAGE_BROKER_TYPE string `env:"MESSAGE_BROKER_TYPE" envDefault:"kafka"` // kafka | sqs
 
-	// JWT_SECRET_TOKEN is the secret key to use for JWT tokens
+	// JWT_PRIVATE_KEYS is one or more PEM encoded RSA or Ed25519 private keys used to sign JWTs, the first one signs new tokens.
+	// See shared/utils/jwt_keys.go for how to rotate keys. JWT_PRIVATE_KEYS_FILE is a path to a file with the same contents.
+	JWT_PRIVATE_KEYS      string `env:"JWT_PRIVATE_KEYS"`
+	JWT_PRIVATE_KEYS_FILE string `env:"JWT_PRIVATE_KEYS_FILE,file"`
+
+	// JWT_SECRET_TOKEN is deprecated, when JWT_PRIVATE_KEYS isn't set a signing key is derived from it
 	JWT_SECRET_TOKEN string `env:"JWT_SECRET_TOKEN"`
 
+	// ACCESS_TOKEN_TTL is how long an access token (JWT) is valid for before it must be refreshed
+	ACCESS_TOKEN_TTL time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"15m"`
+
+	// REFRESH_TOKEN_TTL is how long a login session can go without being refreshed before it expires
+	REFRESH_TOKEN_TTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+
 	// CORS_ALLOW_ORIGINS is a comma-separated list of origins to allow CORS requests from
 	CORS_ALLOW_ORIGINS []string `env:"CORS_ALLOW_ORIGINS" envSeparator:","`
 
+	// TRUSTED_PROXIES is a comma-separated list of proxy IPs or CIDRs whose X-Forwarded-For header is trusted for the client's IP.
+	// By default none are, so the IP logins are throttled by can't be spoofed with the header.
+	TRUSTED_PROXIES []string `env:"TRUSTED_PROXIES" envSeparator:","`
+
 	// Kafka options
 
 	// KAFKA_BROKER_URLS is the URL of the Kafka broker
@@ -27,6 +43,27 @@ type APIConfig struct {
 
 	// Optional Slack Integration
 	SLACK_WEBHOOK_URL string `env:"SLACK_WEBHOOK_URL" envDefault:""`
+
+	// WEBSITE_URL is the base URL of the website, used to build links in emails we send to users
+	WEBSITE_URL string `env:"WEBSITE_URL" envDefault:"http://localhost:3000"`
+
+	// TRASH_RETENTION is how long deleted forms, email templates and pipelines can be restored before they're purged for good
+	TRASH_RETENTION time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+
+	// Platform SMTP options, used for account emails like password resets (event emails use the event's own secrets)
+	SMTP_HOST     string `env:"SMTP_HOST"`
+	SMTP_PORT     int    `env:"SMTP_PORT" envDefault:"587"`
+	SMTP_USERNAME string `env:"SMTP_USERNAME"`
+	SMTP_PASSWORD string `env:"SMTP_PASSWORD"`
+	SMTP_FROM     string `env:"SMTP_FROM"`
+
+	// Optional OpenID Connect login, enabled when OIDC_ISSUER_URL is set. Any standards-compliant issuer works.
+	OIDC_ISSUER_URL    string `env:"OIDC_ISSUER_URL"`
+	OIDC_CLIENT_ID     string `env:"OIDC_CLIENT_ID"`
+	OIDC_CLIENT_SECRET string `env:"OIDC_CLIENT_SECRET"`
+	// OIDC_REDIRECT_URL is where the provider sends the user back to, defaults to the website's /oidc/callback page
+	OIDC_REDIRECT_URL string   `env:"OIDC_REDIRECT_URL"`
+	OIDC_SCOPES       []string `env:"OIDC_SCOPES" envSeparator:"," envDefault:"openid,email,profile"`
 }
 
 var (
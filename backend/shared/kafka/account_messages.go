@@ -0,0 +1,31 @@
+package kafka
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeleteAccountMessageType is sent when a user deletes their account, it isn't part of a pipeline run
+const DeleteAccountMessageType = "DeleteAccount"
+
+// DeleteAccountMessage asks the event listener to run an account deletion job
+type DeleteAccountMessage struct {
+	Name              string             `bson:"_id,omitempty" json:"_id,omitempty"`
+	Type              string             `json:"type" bson:"type" validate:"required,eq=DeleteAccount"`
+	AccountDeletionID primitive.ObjectID `bson:"accountDeletionID" json:"accountDeletionID" validate:"required"`
+}
+
+func (s DeleteAccountMessage) MessageType() string {
+	return s.Type
+}
+
+func (s DeleteAccountMessage) GetName() string {
+	return s.Name
+}
+
+func NewDeleteAccountMessage(name string, accountDeletionID primitive.ObjectID) *DeleteAccountMessage {
+	return &DeleteAccountMessage{
+		Name:              name,
+		Type:              DeleteAccountMessageType,
+		AccountDeletionID: accountDeletionID,
+	}
+}
@@ -15,15 +15,16 @@ type PipelineActionMessage interface {
 // SendEmailMessage requires either an email field ID or an email address.
 // SendEmailMessage represents a send email message
 type SendEmailMessage struct {
-	ActionID        primitive.ObjectID     `bson:"actionID" json:"actionID" validate:"required"`
-	PipelineID      primitive.ObjectID     `bson:"pipelineID" json:"pipelineID" validate:"required"`
-	Name            string                 `bson:"_id,omitempty" json:"_id,omitempty"`
-	PipelineRunID   primitive.ObjectID     `bson:"pipelineRunID" json:"pipelineRunID" validate:"required"`
-	Type            string                 `json:"type" bson:"type" validate:"required,eq=SendEmail"`
-	EmailTemplateID primitive.ObjectID     `bson:"emailTemplateID" json:"emailTemplateID" validate:"required"`
-	EventID         primitive.ObjectID     `bson:"eventID" json:"eventID" validate:"required"`
-	Data            map[string]interface{} `bson:"data" json:"data" validate:"required"`
-	EmailFieldID    string                 `bson:"emailFieldID" json:"emailFieldID"`
+	ActionID             primitive.ObjectID     `bson:"actionID" json:"actionID" validate:"required"`
+	PipelineID           primitive.ObjectID     `bson:"pipelineID" json:"pipelineID" validate:"required"`
+	Name                 string                 `bson:"_id,omitempty" json:"_id,omitempty"`
+	PipelineRunID        primitive.ObjectID     `bson:"pipelineRunID" json:"pipelineRunID" validate:"required"`
+	Type                 string                 `json:"type" bson:"type" validate:"required,eq=SendEmail"`
+	EmailTemplateID      primitive.ObjectID     `bson:"emailTemplateID" json:"emailTemplateID" validate:"required"`
+	EventID              primitive.ObjectID     `bson:"eventID" json:"eventID" validate:"required"`
+	Data                 map[string]interface{} `bson:"data" json:"data" validate:"required"`
+	EmailFieldID         string                 `bson:"emailFieldID" json:"emailFieldID"`
+	AttachCalendarInvite bool                   `bson:"attachCalendarInvite,omitempty" json:"attachCalendarInvite,omitempty"` // Adds the event as an .ics file
 }
 
 func (s SendEmailMessage) MessageType() string {
@@ -34,17 +35,18 @@ func (s SendEmailMessage) GetName() string {
 	return s.Name
 }
 
-func NewSendEmailMessage(name string, actionID primitive.ObjectID, pipelineID primitive.ObjectID, pipelineRunID primitive.ObjectID, emailTemplate primitive.ObjectID, eventID primitive.ObjectID, data map[string]interface{}, emailFieldID string) *SendEmailMessage {
+func NewSendEmailMessage(name string, actionID primitive.ObjectID, pipelineID primitive.ObjectID, pipelineRunID primitive.ObjectID, emailTemplate primitive.ObjectID, eventID primitive.ObjectID, data map[string]interface{}, emailFieldID string, attachCalendarInvite bool) *SendEmailMessage {
 	return &SendEmailMessage{
-		Name:            name,
-		ActionID:        actionID,
-		PipelineID:      pipelineID,
-		PipelineRunID:   pipelineRunID,
-		Type:            "SendEmail",
-		EmailTemplateID: emailTemplate,
-		EventID:         eventID,
-		Data:            data,
-		EmailFieldID:    emailFieldID,
+		Name:                 name,
+		ActionID:             actionID,
+		PipelineID:           pipelineID,
+		PipelineRunID:        pipelineRunID,
+		Type:                 "SendEmail",
+		EmailTemplateID:      emailTemplate,
+		EventID:              eventID,
+		Data:                 data,
+		EmailFieldID:         emailFieldID,
+		AttachCalendarInvite: attachCalendarInvite,
 	}
 }
 
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountDeletionStatus string
+
+const (
+	AccountDeletionPending   AccountDeletionStatus = "Pending"
+	AccountDeletionRunning   AccountDeletionStatus = "Running"
+	AccountDeletionFailure   AccountDeletionStatus = "Failure"
+	AccountDeletionCompleted AccountDeletionStatus = "Completed"
+)
+
+// AccountDeletion is a background job that removes a deleted user's data, the user checks on it with the status token they were given
+type AccountDeletion struct {
+	ID              primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	UserID          primitive.ObjectID    `bson:"userID" json:"-"`
+	Email           string                `bson:"email" json:"-"` // Needed to clean up data keyed by email once the user is gone
+	StatusTokenHash string                `bson:"statusTokenHash" json:"-"`
+	Status          AccountDeletionStatus `bson:"status" json:"status"`
+	RequestedAt     time.Time             `bson:"requestedAt" json:"requestedAt"`
+	StartedAt       time.Time             `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	CompletedAt     time.Time             `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+	ErrorMsg        string                `bson:"errorMsg,omitempty" json:"errorMsg,omitempty"`
+	Report          AccountDeletionReport `bson:"report" json:"report"`
+}
+
+// AccountDeletionReport describes what was done with the user's data
+type AccountDeletionReport struct {
+	ResponsesDeleted               int64 `bson:"responsesDeleted" json:"responsesDeleted"`
+	EventsTransferred              int64 `bson:"eventsTransferred" json:"eventsTransferred"` // Events they created are handed to another organizer, or an owner of the organization that owns them
+	EventsDeleted                  int64 `bson:"eventsDeleted" json:"eventsDeleted"`         // Events they created with no other organizers are deleted with their forms, responses and pipelines
+	OrganizerMembershipsRemoved    int64 `bson:"organizerMembershipsRemoved" json:"organizerMembershipsRemoved"`
+	OrganizationMembershipsRemoved int64 `bson:"organizationMembershipsRemoved" json:"organizationMembershipsRemoved"` // Organizations they were the last owner of are handed to another member
+	FormAccessRemoved              int64 `bson:"formAccessRemoved" json:"formAccessRemoved"`
+	ScheduleRegistrationsRemoved   int64 `bson:"scheduleRegistrationsRemoved" json:"scheduleRegistrationsRemoved"`
+	SentEmailsDeleted              int64 `bson:"sentEmailsDeleted" json:"sentEmailsDeleted"`
+	SubscriptionsCancelled         int64 `bson:"subscriptionsCancelled" json:"subscriptionsCancelled"`
+	SessionsDeleted                int64 `bson:"sessionsDeleted" json:"sessionsDeleted"`
+	APIKeysDeleted                 int64 `bson:"apiKeysDeleted" json:"apiKeysDeleted"`
+	AuditEntriesScrubbed           int64 `bson:"auditEntriesScrubbed" json:"auditEntriesScrubbed"` // Their audit log entries are kept under a pseudonym, without their IP address or the diffs about them
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey is a personal API key a user can use in place of a JWT for scripted access, only the hash of the key is stored
+type APIKey struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id" mongoPreventOverride:"true"`
+	UserID    primitive.ObjectID `bson:"userID" json:"userID" mongoPreventOverride:"true"`
+	Name      string             `bson:"name" json:"name"`
+	Prefix    string             `bson:"prefix" json:"prefix"` // The start of the key so users can tell their keys apart
+	KeyHash   string             `bson:"keyHash" json:"-"`
+	EventID   primitive.ObjectID `bson:"eventID,omitempty" json:"eventID,omitempty"` // When set the key can only access this event
+	ReadOnly  bool               `bson:"readOnly" json:"readOnly"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"` // Zero means the key never expires
+	LastUsed  time.Time          `bson:"lastUsed,omitempty" json:"lastUsed,omitempty"`
+	RevokedAt time.Time          `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+}
+
+// IsActive returns true if the key has not been revoked and has not expired
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt.IsZero() && (k.ExpiresAt.IsZero() || k.ExpiresAt.After(time.Now()))
+}
+
+// IsEventScoped returns true if the key is limited to a single event
+func (k *APIKey) IsEventScoped() bool {
+	return !k.EventID.IsZero()
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditAction is what was done, named target.verb
+type AuditAction string
+
+const (
+	AuditEventCreate          AuditAction = "event.create"
+	AuditEventClone           AuditAction = "event.clone"
+	AuditEventUpdate          AuditAction = "event.update"
+	AuditEventSettingsUpdate  AuditAction = "event.settings.update"
+	AuditEventDelete          AuditAction = "event.delete"
+	AuditEventPublish         AuditAction = "event.publish"
+	AuditEventArchive         AuditAction = "event.archive"
+	AuditEventCancel          AuditAction = "event.cancel"
+	AuditOrganizerAdd         AuditAction = "organizer.add"
+	AuditOrganizerUpdate      AuditAction = "organizer.update"
+	AuditOrganizerRemove      AuditAction = "organizer.remove"
+	AuditInvitationCreate     AuditAction = "invitation.create"
+	AuditInvitationRevoke     AuditAction = "invitation.revoke"
+	AuditInvitationAccept     AuditAction = "invitation.accept"
+	AuditTransferRequest      AuditAction = "transfer.request"
+	AuditTransferCancel       AuditAction = "transfer.cancel"
+	AuditTransferAccept       AuditAction = "transfer.accept"
+	AuditTransferDecline      AuditAction = "transfer.decline"
+	AuditSecretCreate         AuditAction = "secret.create"
+	AuditSecretUpdate         AuditAction = "secret.update"
+	AuditSecretDelete         AuditAction = "secret.delete"
+	AuditFormCreate           AuditAction = "form.create"
+	AuditFormUpdate           AuditAction = "form.update"
+	AuditFormDelete           AuditAction = "form.delete"
+	AuditFormRestore          AuditAction = "form.restore"
+	AuditPipelineCreate       AuditAction = "pipeline.create"
+	AuditPipelineUpdate       AuditAction = "pipeline.update"
+	AuditPipelineDelete       AuditAction = "pipeline.delete"
+	AuditPipelineRestore      AuditAction = "pipeline.restore"
+	AuditEmailTemplateCreate  AuditAction = "emailTemplate.create"
+	AuditEmailTemplateUpdate  AuditAction = "emailTemplate.update"
+	AuditEmailTemplateDelete  AuditAction = "emailTemplate.delete"
+	AuditEmailTemplateRestore AuditAction = "emailTemplate.restore"
+	AuditScheduleItemCreate   AuditAction = "scheduleItem.create"
+	AuditScheduleItemUpdate   AuditAction = "scheduleItem.update"
+	AuditScheduleItemDelete   AuditAction = "scheduleItem.delete"
+	AuditResponseSubmit       AuditAction = "response.submit"
+	AuditResponseUpdate       AuditAction = "response.update"
+	AuditResponseCheckIn      AuditAction = "response.checkIn"
+	AuditUserDisable          AuditAction = "user.disable"
+	AuditUserEnable           AuditAction = "user.enable"
+	AuditUserImpersonate      AuditAction = "user.impersonate"
+	AuditImpersonatedRequest  AuditAction = "user.impersonatedRequest" // Every request made with an impersonated session
+	AuditSubscriptionUpdate   AuditAction = "subscription.update"
+	AuditPlanUpdate           AuditAction = "plan.update"
+)
+
+// AuditTargetType is the kind of thing an audited action changed
+type AuditTargetType string
+
+const (
+	AuditTargetEvent         AuditTargetType = "event"
+	AuditTargetOrganizer     AuditTargetType = "organizer"
+	AuditTargetInvitation    AuditTargetType = "invitation"
+	AuditTargetTransfer      AuditTargetType = "transfer"
+	AuditTargetSecret        AuditTargetType = "secret"
+	AuditTargetForm          AuditTargetType = "form"
+	AuditTargetPipeline      AuditTargetType = "pipeline"
+	AuditTargetEmailTemplate AuditTargetType = "emailTemplate"
+	AuditTargetScheduleItem  AuditTargetType = "scheduleItem"
+	AuditTargetResponse      AuditTargetType = "response"
+	AuditTargetUser          AuditTargetType = "user"
+	AuditTargetSubscription  AuditTargetType = "subscription"
+	AuditTargetPlan          AuditTargetType = "plan"
+)
+
+// AuditRedacted stands in for values that mustn't be copied into the audit log, like secrets
+const AuditRedacted = "[redacted]"
+
+// AuditEntry records a change someone made, entries are never updated or deleted except to pseudonymize a deleted user
+type AuditEntry struct {
+	ID         primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	EventID    primitive.ObjectID     `bson:"eventID,omitempty" json:"eventID,omitempty"`
+	ActorID    primitive.ObjectID     `bson:"actorID" json:"actorID"`
+	APIKeyID   primitive.ObjectID     `bson:"apiKeyID,omitempty" json:"apiKeyID,omitempty"` // Set when the actor used an API key rather than logging in
+	Action     AuditAction            `bson:"action" json:"action"`
+	TargetType AuditTargetType        `bson:"targetType" json:"targetType"`
+	TargetID   string                 `bson:"targetID,omitempty" json:"targetID,omitempty"` // An event's secrets are stored together, so this is the event's ID for them
+	IP         string                 `bson:"ip" json:"ip"`
+	UserAgent  string                 `bson:"userAgent" json:"userAgent"`
+	Request    string                 `bson:"request,omitempty" json:"request,omitempty"` // The method and path, only recorded for impersonated requests
+	Diff       map[string]AuditChange `bson:"diff,omitempty" json:"diff,omitempty"`       // Keyed by the changed field's JSON name
+	CreatedAt  time.Time              `bson:"createdAt" json:"createdAt"`
+
+	// ImpersonatedByID is the platform admin who was impersonating the actor
+	ImpersonatedByID primitive.ObjectID `bson:"impersonatedByID,omitempty" json:"impersonatedByID,omitempty"`
+}
+
+// AuditChange is the value of a field before and after an audited action
+type AuditChange struct {
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
@@ -33,7 +33,8 @@ type PlanCycleOptions struct {
 
 type Subscription struct {
 	ID                       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID                   primitive.ObjectID `bson:"userId" json:"userId"`
+	UserID                   primitive.ObjectID `bson:"userId" json:"userId"`                                     // Empty for organization subscriptions
+	OrganizationID           primitive.ObjectID `bson:"organizationId,omitempty" json:"organizationId,omitempty"` // Set instead of UserID when an organization pays
 	PlanID                   primitive.ObjectID `bson:"planId" json:"planId"`
 	StartDate                time.Time          `bson:"startDate" json:"startDate"`
 	EndDate                  time.Time          `bson:"endDate" json:"endDate"`
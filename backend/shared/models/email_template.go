@@ -21,4 +21,6 @@ type EmailTemplate struct {
 	IsHTML         bool               `bson:"isHTML" json:"isHTML"`
 
 	LastUpdatedAt time.Time `bson:"lastUpdatedAt" json:"lastUpdatedAt"`
+	IsDeleted     bool      `bson:"isDeleted,omitempty" json:"isDeleted,omitempty" mongoPreventOverride:"true"`
+	DeletedAt     time.Time `bson:"deletedAt,omitempty" json:"deletedAt,omitempty" mongoPreventOverride:"true"` // When the template was moved to the trash
 }
@@ -10,12 +10,127 @@ import (
 
 // Event represents an event in the database
 type Event struct {
-	ID           primitive.ObjectID   `bson:"_id,omitempty" mongoPreventOverride:"true"`
-	OrganizerIDs []primitive.ObjectID `bson:"organizerIDs" json:"organizerIDs"`
-	CreatedByID  primitive.ObjectID   `bson:"createdByID" json:"createdByID"`
-	Metadata     EventMetadata        `bson:"metadata" json:"metadata"`
+	ID             primitive.ObjectID   `bson:"_id,omitempty" mongoPreventOverride:"true"`
+	OrganizerIDs   []primitive.ObjectID `bson:"organizerIDs" json:"organizerIDs"` // Kept alongside Organizers so events can be queried by organizer
+	Organizers     []EventOrganizer     `bson:"organizers" json:"organizers"`
+	CreatedByID    primitive.ObjectID   `bson:"createdByID" json:"createdByID"`
+	OrganizationID primitive.ObjectID   `bson:"organizationID,omitempty" json:"organizationID,omitempty"` // Set when an organization owns the event and pays for it
+	Metadata       EventMetadata        `bson:"metadata" json:"metadata"`
+	Settings       EventSettings        `bson:"settings" json:"settings"`
+	Status         EventStatus          `bson:"status,omitempty" json:"status,omitempty"` // Changed through the publish, archive and cancel routes rather than with the metadata
+	PublishedAt    time.Time            `bson:"publishedAt,omitempty" json:"publishedAt,omitempty"`
+	ClonedFromID   primitive.ObjectID   `bson:"clonedFromID,omitempty" json:"clonedFromID,omitempty"` // The event this one was copied from, if any
 }
 
+// EventStatus is where an event is in its lifecycle
+type EventStatus string
+
+const (
+	EventStatusDraft     EventStatus = "draft" // Only organizers can see it
+	EventStatusPublished EventStatus = "published"
+	EventStatusArchived  EventStatus = "archived" // Over, it can still be seen but isn't listed
+	EventStatusCancelled EventStatus = "cancelled"
+)
+
+// GetStatus returns the event's status, events from before statuses existed were already public so they count as published
+func (e *Event) GetStatus() EventStatus {
+	if e.Status == "" {
+		return EventStatusPublished
+	}
+	return e.Status
+}
+
+// GetVisibility returns who can find the event, events without a visibility can be seen by anyone with the link
+func (e *Event) GetVisibility() EventVisibility {
+	if e.Metadata.Visibility == "" {
+		return EventVisibilityUnlisted
+	}
+	return e.Metadata.Visibility
+}
+
+// IsVisibleToPublic checks if someone who doesn't organize the event can see it
+func (e *Event) IsVisibleToPublic() bool {
+	return e.GetStatus() != EventStatusDraft && e.GetVisibility() != EventVisibilityPrivate
+}
+
+// StripPrivate removes what only organizers and the event's participants can see
+func (m *EventMetadata) StripPrivate() {
+	if m.Venue != nil && m.Venue.MeetingURL != "" {
+		venue := *m.Venue
+		venue.MeetingURL = ""
+		m.Venue = &venue
+	}
+}
+
+// PublishProblems lists the metadata that's missing or wrong for the event to be published, it's empty if it can be
+func (m EventMetadata) PublishProblems() []string {
+	problems := []string{}
+	if m.StartTime.IsZero() {
+		problems = append(problems, "A start time is required")
+	}
+	if m.EndTime.IsZero() {
+		problems = append(problems, "An end time is required")
+	} else if !m.StartTime.IsZero() && !m.EndTime.After(m.StartTime) {
+		problems = append(problems, "The end time must be after the start time")
+	}
+	if m.Timezone == "" {
+		problems = append(problems, "A timezone is required")
+	}
+	if m.ContactEmail == "" {
+		problems = append(problems, "A contact email is required")
+	}
+	if m.Venue != nil && m.Venue.Type != EventLocationOnline && (m.Venue.Address == nil || m.Venue.Address.City == "" || m.Venue.Address.Country == "") {
+		problems = append(problems, "The venue's address needs at least a city and country")
+	}
+	return problems
+}
+
+// GetOrganizer returns the user's role on the event.
+// Events from before roles existed only have OrganizerIDs, where the creator is the owner and everyone else is an admin.
+func (e *Event) GetOrganizer(userID primitive.ObjectID) (EventOrganizer, bool) {
+	isOrganizer := false
+	for _, organizerID := range e.OrganizerIDs {
+		if organizerID == userID {
+			isOrganizer = true
+			break
+		}
+	}
+	if !isOrganizer {
+		return EventOrganizer{}, false
+	}
+
+	for _, organizer := range e.Organizers {
+		if organizer.UserID == userID {
+			return organizer, true
+		}
+	}
+
+	if userID == e.CreatedByID {
+		return EventOrganizer{UserID: userID, Role: EventRoleOwner}, true
+	}
+	return EventOrganizer{UserID: userID, Role: EventRoleAdmin}, true
+}
+
+// EventSettings are organizer-only settings for an event, unlike the metadata these are never public
+type EventSettings struct {
+	// RequireOrganizerTwoFactor blocks organizers without 2FA enabled from accessing responses
+	RequireOrganizerTwoFactor bool `bson:"requireOrganizerTwoFactor" json:"requireOrganizerTwoFactor"`
+	// PreferMagicLinkSignIn shows applicants the emailed sign in link before the password form on the event's forms
+	PreferMagicLinkSignIn bool `bson:"preferMagicLinkSignIn" json:"preferMagicLinkSignIn"`
+	// ParticipantFormID is the form accepted applicants are given access to, usually by an AllowFormAccess pipeline action.
+	// Anyone who can access or has submitted it is a participant and can see the venue's meeting URL.
+	ParticipantFormID primitive.ObjectID `bson:"participantFormID,omitempty" json:"participantFormID,omitempty"`
+}
+
+// EventVisibility is who can find an event once it's published
+type EventVisibility string
+
+const (
+	EventVisibilityPublic   EventVisibility = "public"   // Listed in the public event directory
+	EventVisibilityUnlisted EventVisibility = "unlisted" // Anyone with the link can see it
+	EventVisibilityPrivate  EventVisibility = "private"  // Only organizers can see it
+)
+
 // EventMetadata represents the user defined metadata for an event
 type EventMetadata struct {
 	Name string `bson:"name" json:"name" validate:"required,max=50"` // this is the only required field
@@ -28,16 +143,39 @@ type EventMetadata struct {
 	Description  string `bson:"description,omitempty" json:"description,omitempty" validate:"max=500"`
 	ContactEmail string `bson:"contactEmail,omitempty" json:"contactEmail,omitempty"`
 
+	Visibility EventVisibility `bson:"visibility,omitempty" json:"visibility,omitempty" validate:"omitempty,oneof=public unlisted private"`
+	Tags       []string        `bson:"tags,omitempty" json:"tags,omitempty" validate:"max=10,dive,required,max=30"`
+
+	Venue *EventVenue `bson:"venue,omitempty" json:"venue,omitempty"`
+
 	LastUpdatedAt time.Time `bson:"lastUpdatedAt" json:"lastUpdatedAt"` // RFC3339
 }
 
-// Address represents a physical address
-// TODO: Add validation
-/*type Address struct {
-	StreetAddress string `bson:"streetAddress,omitempty" json:"streetAddress,omitempty"`
-	City          string `bson:"city,omitempty" json:"city,omitempty"`
-	Region        string `bson:"region,omitempty" json:"region,omitempty"`
-	ZipCode       string `bson:"zipCode,omitempty" json:"zipCode,omitempty"`
-	Country       string `bson:"country,omitempty" json:"country,omitempty"`
+// EventLocationType is how people attend an event
+type EventLocationType string
+
+const (
+	EventLocationInPerson EventLocationType = "inPerson"
+	EventLocationOnline   EventLocationType = "online"
+	EventLocationHybrid   EventLocationType = "hybrid" // Both in person and online
+)
+
+// IsValid checks if the location type exists
+func (t EventLocationType) IsValid() bool {
+	return t == EventLocationInPerson || t == EventLocationOnline || t == EventLocationHybrid
+}
+
+// EventVenue is where an event takes place
+type EventVenue struct {
+	Type        EventLocationType `bson:"type" json:"type" validate:"required,oneof=inPerson online hybrid"`
+	Name        string            `bson:"name,omitempty" json:"name,omitempty" validate:"max=100"`
+	Address     *Address          `bson:"address,omitempty" json:"address,omitempty"`                                        // Not used by online events
+	Coordinates *Coordinates      `bson:"coordinates,omitempty" json:"coordinates,omitempty"`                                // Lets the event be found by distance
+	MeetingURL  string            `bson:"meetingURL,omitempty" json:"meetingURL,omitempty" validate:"omitempty,url,max=500"` // Only shown to organizers and participants
+}
+
+// Coordinates is a point on the globe. Mongo reads embedded documents as legacy coordinate pairs in field order, so longitude comes first.
+type Coordinates struct {
+	Longitude float64 `bson:"lng" json:"longitude" validate:"min=-180,max=180"`
+	Latitude  float64 `bson:"lat" json:"latitude" validate:"min=-90,max=90"`
 }
-*/
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventInvitationStatus string
+
+const (
+	EventInvitationPending  EventInvitationStatus = "pending"
+	EventInvitationAccepted EventInvitationStatus = "accepted"
+	EventInvitationRevoked  EventInvitationStatus = "revoked"
+)
+
+// EventInvitation invites someone to organize an event by email, it's accepted with the emailed link or
+// automatically once a user with a verified matching email logs in
+type EventInvitation struct {
+	ID           primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	EventID      primitive.ObjectID    `bson:"eventID" json:"eventID"`
+	Email        string                `bson:"email" json:"email"` // Stored lowercase
+	Role         EventRole             `bson:"role" json:"role"`
+	Capabilities []EventCapability     `bson:"capabilities,omitempty" json:"capabilities,omitempty"` // Only used by the custom role
+	InvitedByID  primitive.ObjectID    `bson:"invitedByID" json:"invitedByID"`
+	TokenHash    string                `bson:"tokenHash" json:"-"`
+	Status       EventInvitationStatus `bson:"status" json:"status"`
+	CreatedAt    time.Time             `bson:"createdAt" json:"createdAt"`
+	ExpiresAt    time.Time             `bson:"expiresAt" json:"expiresAt"`
+	AcceptedAt   time.Time             `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
+	AcceptedByID primitive.ObjectID    `bson:"acceptedByID,omitempty" json:"acceptedByID,omitempty"`
+}
+
+// Organizer returns the organizer the invitation makes the user
+func (i EventInvitation) Organizer(userID primitive.ObjectID) EventOrganizer {
+	return EventOrganizer{UserID: userID, Role: i.Role, Capabilities: i.Capabilities, AddedAt: time.Now()}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventOwnershipTransferStatus string
+
+const (
+	EventOwnershipTransferPending   EventOwnershipTransferStatus = "pending"
+	EventOwnershipTransferAccepted  EventOwnershipTransferStatus = "accepted"
+	EventOwnershipTransferDeclined  EventOwnershipTransferStatus = "declined"
+	EventOwnershipTransferCancelled EventOwnershipTransferStatus = "cancelled"
+)
+
+// EventOwnershipTransfer is a request from an event's owner to hand the event to another organizer, it only takes
+// effect once they accept. Transfers are kept after they finish as a record of who owned the event.
+type EventOwnershipTransfer struct {
+	ID          primitive.ObjectID           `bson:"_id,omitempty" json:"id"`
+	EventID     primitive.ObjectID           `bson:"eventID" json:"eventID"`
+	FromUserID  primitive.ObjectID           `bson:"fromUserID" json:"fromUserID"`
+	ToUserID    primitive.ObjectID           `bson:"toUserID" json:"toUserID"`
+	Status      EventOwnershipTransferStatus `bson:"status" json:"status"`
+	RequestedAt time.Time                    `bson:"requestedAt" json:"requestedAt"`
+	ExpiresAt   time.Time                    `bson:"expiresAt" json:"expiresAt"`
+	RespondedAt time.Time                    `bson:"respondedAt,omitempty" json:"respondedAt,omitempty"`
+
+	// The subscriptions the event's quota was moved between when the transfer was accepted
+	FromSubscriptionID primitive.ObjectID `bson:"fromSubscriptionID,omitempty" json:"fromSubscriptionID,omitempty"`
+	ToSubscriptionID   primitive.ObjectID `bson:"toSubscriptionID,omitempty" json:"toSubscriptionID,omitempty"`
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventRole is the set of capabilities an organizer has on an event
+type EventRole string
+
+const (
+	EventRoleOwner    EventRole = "owner" // The creator of the event, there's only ever one
+	EventRoleAdmin    EventRole = "admin"
+	EventRoleReviewer EventRole = "reviewer"
+	EventRoleViewer   EventRole = "viewer"
+	EventRoleCheckIn  EventRole = "checkIn"
+	EventRoleCustom   EventRole = "custom" // Uses the capabilities stored on the organizer
+)
+
+// EventCapability is something an organizer is allowed to do on an event, named resource:action
+type EventCapability string
+
+const (
+	CapabilityEventRead           EventCapability = "event:read"
+	CapabilityEventWrite          EventCapability = "event:write"
+	CapabilitySettingsWrite       EventCapability = "settings:write"
+	CapabilityOrganizersWrite     EventCapability = "organizers:write"
+	CapabilityFormsRead           EventCapability = "forms:read"
+	CapabilityFormsWrite          EventCapability = "forms:write"
+	CapabilityResponsesRead       EventCapability = "responses:read"
+	CapabilityResponsesWrite      EventCapability = "responses:write"
+	CapabilityResponsesCheckIn    EventCapability = "responses:checkIn" // Marks an applicant as arrived without seeing or changing their answers
+	CapabilityPipelinesRead       EventCapability = "pipelines:read"
+	CapabilityPipelinesWrite      EventCapability = "pipelines:write"
+	CapabilityEmailTemplatesRead  EventCapability = "email_templates:read"
+	CapabilityEmailTemplatesWrite EventCapability = "email_templates:write"
+	CapabilitySecretsRead         EventCapability = "secrets:read"
+	CapabilitySecretsWrite        EventCapability = "secrets:write"
+	CapabilityAuditRead           EventCapability = "audit:read"
+)
+
+// AllEventCapabilities lists every capability, custom roles can only be given these
+var AllEventCapabilities = []EventCapability{
+	CapabilityEventRead,
+	CapabilityEventWrite,
+	CapabilitySettingsWrite,
+	CapabilityOrganizersWrite,
+	CapabilityFormsRead,
+	CapabilityFormsWrite,
+	CapabilityResponsesRead,
+	CapabilityResponsesWrite,
+	CapabilityResponsesCheckIn,
+	CapabilityPipelinesRead,
+	CapabilityPipelinesWrite,
+	CapabilityEmailTemplatesRead,
+	CapabilityEmailTemplatesWrite,
+	CapabilitySecretsRead,
+	CapabilitySecretsWrite,
+	CapabilityAuditRead,
+}
+
+// EventRoleCapabilities are the capabilities of each built-in role
+var EventRoleCapabilities = map[EventRole][]EventCapability{
+	// Deleting the event is left to the owner rather than being a capability
+	EventRoleOwner: AllEventCapabilities,
+	EventRoleAdmin: AllEventCapabilities,
+	EventRoleReviewer: {
+		CapabilityEventRead, CapabilityFormsRead, CapabilityResponsesRead, CapabilityResponsesWrite,
+		CapabilityPipelinesRead, CapabilityEmailTemplatesRead,
+	},
+	EventRoleViewer: {
+		CapabilityEventRead, CapabilityFormsRead, CapabilityResponsesRead, CapabilityPipelinesRead, CapabilityEmailTemplatesRead,
+	},
+	// Check-in staff are often volunteers, so they can check applicants in but not read what they answered
+	EventRoleCheckIn: {
+		CapabilityEventRead, CapabilityFormsRead, CapabilityResponsesCheckIn,
+	},
+}
+
+// EventOrganizer is an organizer of an event and their role on it
+type EventOrganizer struct {
+	UserID       primitive.ObjectID `bson:"userID" json:"userID"`
+	Role         EventRole          `bson:"role" json:"role"`
+	Capabilities []EventCapability  `bson:"capabilities,omitempty" json:"capabilities,omitempty"` // Only used by the custom role
+	AddedAt      time.Time          `bson:"addedAt,omitempty" json:"addedAt,omitempty"`
+}
+
+// GetCapabilities returns the capabilities the organizer's role allows
+func (o EventOrganizer) GetCapabilities() []EventCapability {
+	if o.Role == EventRoleCustom {
+		return o.Capabilities
+	}
+	return EventRoleCapabilities[o.Role]
+}
+
+// HasCapability checks if the organizer's role allows the capability
+func (o EventOrganizer) HasCapability(capability EventCapability) bool {
+	for _, c := range o.GetCapabilities() {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidEventRole checks if the role exists, owner isn't included since it can only be given by creating or transferring the event
+func IsValidEventRole(role EventRole) bool {
+	if role == EventRoleCustom {
+		return true
+	}
+	_, ok := EventRoleCapabilities[role]
+	return ok && role != EventRoleOwner
+}
+
+// IsValidEventCapability checks if the capability exists
+func IsValidEventCapability(capability EventCapability) bool {
+	for _, c := range AllEventCapabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
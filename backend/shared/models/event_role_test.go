@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventOrganizerHasCapability(t *testing.T) {
+	tests := []struct {
+		name       string
+		organizer  EventOrganizer
+		capability EventCapability
+		expected   bool
+	}{
+		{"Owner", EventOrganizer{Role: EventRoleOwner}, CapabilitySecretsWrite, true},
+		{"Admin", EventOrganizer{Role: EventRoleAdmin}, CapabilityOrganizersWrite, true},
+		{"Reviewer can write responses", EventOrganizer{Role: EventRoleReviewer}, CapabilityResponsesWrite, true},
+		{"Reviewer can't write forms", EventOrganizer{Role: EventRoleReviewer}, CapabilityFormsWrite, false},
+		{"Viewer can read responses", EventOrganizer{Role: EventRoleViewer}, CapabilityResponsesRead, true},
+		{"Viewer can't write responses", EventOrganizer{Role: EventRoleViewer}, CapabilityResponsesWrite, false},
+		{"Check-in can check applicants in", EventOrganizer{Role: EventRoleCheckIn}, CapabilityResponsesCheckIn, true},
+		{"Check-in can't read responses", EventOrganizer{Role: EventRoleCheckIn}, CapabilityResponsesRead, false},
+		{"Check-in can't write responses", EventOrganizer{Role: EventRoleCheckIn}, CapabilityResponsesWrite, false},
+		{"Check-in can't read pipelines", EventOrganizer{Role: EventRoleCheckIn}, CapabilityPipelinesRead, false},
+		{"Custom role has its capabilities", EventOrganizer{Role: EventRoleCustom, Capabilities: []EventCapability{CapabilitySecretsWrite}}, CapabilitySecretsWrite, true},
+		{"Custom role without event:read", EventOrganizer{Role: EventRoleCustom, Capabilities: []EventCapability{CapabilitySecretsWrite}}, CapabilityEventRead, false},
+		{"Custom capabilities are ignored on built-in roles", EventOrganizer{Role: EventRoleViewer, Capabilities: []EventCapability{CapabilitySecretsWrite}}, CapabilitySecretsWrite, false},
+		{"Unknown role", EventOrganizer{Role: "unknown"}, CapabilityEventRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.organizer.HasCapability(tt.capability))
+		})
+	}
+}
+
+func TestIsValidEventRole(t *testing.T) {
+	assert.True(t, IsValidEventRole(EventRoleAdmin))
+	assert.True(t, IsValidEventRole(EventRoleCustom))
+	assert.False(t, IsValidEventRole(EventRoleOwner), "owner can only be given by transferring the event")
+	assert.False(t, IsValidEventRole("unknown"))
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventIsVisibleToPublic(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     EventStatus
+		visibility EventVisibility
+		expected   bool
+	}{
+		{"From before statuses existed", "", "", true},
+		{"Draft", EventStatusDraft, EventVisibilityPublic, false},
+		{"Published", EventStatusPublished, EventVisibilityPublic, true},
+		{"Published but unlisted", EventStatusPublished, EventVisibilityUnlisted, true},
+		{"Published but private", EventStatusPublished, EventVisibilityPrivate, false},
+		{"Archived", EventStatusArchived, EventVisibilityPublic, true},
+		{"Cancelled", EventStatusCancelled, EventVisibilityPublic, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{Status: tt.status, Metadata: EventMetadata{Visibility: tt.visibility}}
+			assert.Equal(t, tt.expected, event.IsVisibleToPublic())
+		})
+	}
+}
+
+func TestEventMetadataPublishProblems(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	ready := EventMetadata{
+		Name:         "Hackathon",
+		StartTime:    start,
+		EndTime:      start.Add(24 * time.Hour),
+		Timezone:     "Europe/London",
+		ContactEmail: "organizers@example.com",
+	}
+
+	tests := []struct {
+		name     string
+		change   func(m *EventMetadata)
+		expected []string
+	}{
+		{"Ready", func(m *EventMetadata) {}, []string{}},
+		{"No start time", func(m *EventMetadata) { m.StartTime = time.Time{} }, []string{"A start time is required"}},
+		{"No end time", func(m *EventMetadata) { m.EndTime = time.Time{} }, []string{"An end time is required"}},
+		{"Ends before it starts", func(m *EventMetadata) { m.EndTime = start.Add(-time.Hour) }, []string{"The end time must be after the start time"}},
+		{"Ends as it starts", func(m *EventMetadata) { m.EndTime = start }, []string{"The end time must be after the start time"}},
+		{"No timezone or contact", func(m *EventMetadata) { m.Timezone = ""; m.ContactEmail = "" }, []string{"A timezone is required", "A contact email is required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := ready
+			tt.change(&metadata)
+			assert.Equal(t, tt.expected, metadata.PublishProblems())
+		})
+	}
+}
+
+func TestEventMetadataStripPrivate(t *testing.T) {
+	venue := &EventVenue{Type: EventLocationHybrid, Name: "Main hall", MeetingURL: "https://meet.example.com/abc"}
+	metadata := EventMetadata{Venue: venue}
+
+	metadata.StripPrivate()
+	assert.Empty(t, metadata.Venue.MeetingURL)
+	assert.Equal(t, "Main hall", metadata.Venue.Name)
+	assert.Equal(t, "https://meet.example.com/abc", venue.MeetingURL, "the venue it was given isn't changed")
+
+	withoutVenue := EventMetadata{}
+	withoutVenue.StripPrivate()
+	assert.Nil(t, withoutVenue.Venue)
+}
+
+func TestEventVenuePublishProblems(t *testing.T) {
+	start := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	addressProblem := []string{"The venue's address needs at least a city and country"}
+
+	tests := []struct {
+		name     string
+		venue    *EventVenue
+		expected []string
+	}{
+		{"No venue", nil, []string{}},
+		{"Online", &EventVenue{Type: EventLocationOnline}, []string{}},
+		{"In person with an address", &EventVenue{Type: EventLocationInPerson, Address: &Address{City: "Leeds", Country: "UK"}}, []string{}},
+		{"In person without an address", &EventVenue{Type: EventLocationInPerson}, addressProblem},
+		{"Hybrid without a country", &EventVenue{Type: EventLocationHybrid, Address: &Address{City: "Leeds"}}, addressProblem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := EventMetadata{
+				StartTime:    start,
+				EndTime:      start.Add(time.Hour),
+				Timezone:     "Europe/London",
+				ContactEmail: "organizers@example.com",
+				Venue:        tt.venue,
+			}
+			assert.Equal(t, tt.expected, metadata.PublishProblems())
+		})
+	}
+}
+
+func TestEventLocationTypeIsValid(t *testing.T) {
+	assert.True(t, EventLocationInPerson.IsValid())
+	assert.True(t, EventLocationOnline.IsValid())
+	assert.True(t, EventLocationHybrid.IsValid())
+	assert.False(t, EventLocationType("").IsValid())
+	assert.False(t, EventLocationType("boat").IsValid())
+}
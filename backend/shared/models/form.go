@@ -20,11 +20,11 @@ type SelectorSource struct {
 
 // Address represents a physical address
 type Address struct {
-	StreetAddress string `json:"streetAddress,omitempty" bson:"streetAddress"`
-	City          string `json:"city,omitempty" bson:"city"`
-	Region        string `json:"region,omitempty" bson:"region"`
-	ZipCode       string `json:"zipCode,omitempty" bson:"zipCode"`
-	Country       string `json:"country,omitempty" bson:"country"`
+	StreetAddress string `json:"streetAddress,omitempty" bson:"streetAddress" validate:"max=200"`
+	City          string `json:"city,omitempty" bson:"city" validate:"max=100"`
+	Region        string `json:"region,omitempty" bson:"region" validate:"max=100"`
+	ZipCode       string `json:"zipCode,omitempty" bson:"zipCode" validate:"max=20"`
+	Country       string `json:"country,omitempty" bson:"country" validate:"max=100"`
 }
 
 // EmailValidationOptions for validating email fields
@@ -90,7 +90,8 @@ type FormStructure struct {
 	Description              string                 `json:"description,omitempty" bson:"description"`
 	CreatedAt                time.Time              `json:"createdAt,omitempty" bson:"createdAt"`
 	Status                   string                 `json:"status,omitempty" bson:"status"`
-	IsDeleted                bool                   `json:"isDeleted,omitempty" bson:"isDeleted"`
+	IsDeleted                bool                   `json:"isDeleted,omitempty" bson:"isDeleted" mongoPreventOverride:"true"`
+	DeletedAt                time.Time              `json:"deletedAt,omitempty" bson:"deletedAt,omitempty" mongoPreventOverride:"true"` // When the form was moved to the trash
 	EventID                  primitive.ObjectID     `json:"eventID,omitempty" bson:"eventID" mongoPreventOverride:"true"`
 	MaxSubmissions           int                    `json:"maxSubmissions,omitempty" bson:"maxSubmissions"`
 	SubmissionMessage        string                 `json:"submissionMessage,omitempty" bson:"submissionMessage"`
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoginThrottleKind is what failed logins are being counted against
+type LoginThrottleKind string
+
+const (
+	LoginThrottleAccount LoginThrottleKind = "account" // Keyed by the lowercased email, whether or not an account uses it
+	LoginThrottleIP      LoginThrottleKind = "ip"
+)
+
+// LoginThrottle counts the recent failed logins for an account or IP address, it's stored in Mongo so every API instance sees the same counts
+type LoginThrottle struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind           LoginThrottleKind  `bson:"kind" json:"kind"`
+	Key            string             `bson:"key" json:"key"`
+	Failures       int                `bson:"failures" json:"failures"`
+	FirstFailureAt time.Time          `bson:"firstFailureAt" json:"firstFailureAt"`
+	LastFailureAt  time.Time          `bson:"lastFailureAt" json:"lastFailureAt"`
+	LockedUntil    time.Time          `bson:"lockedUntil,omitempty" json:"lockedUntil,omitempty"`
+}
+
+// IsLocked checks if logins are locked out at the given time
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t.LockedUntil.After(now)
+}
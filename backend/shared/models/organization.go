@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OrganizationRole is what a member can do in an organization
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner  OrganizationRole = "owner"  // Can do everything an admin can, and manage owners or delete the organization
+	OrganizationRoleAdmin  OrganizationRole = "admin"  // Manages members and has full control of every event the organization owns
+	OrganizationRoleMember OrganizationRole = "member" // Can create events owned by the organization
+)
+
+// Organization is a group such as a student club that owns events and pays for them with its own subscription,
+// so events and billing stay with the group as its members change
+type Organization struct {
+	ID                    primitive.ObjectID   `bson:"_id,omitempty" json:"id" mongoPreventOverride:"true"`
+	Name                  string               `bson:"name" json:"name" validate:"required,max=100"`
+	Members               []OrganizationMember `bson:"members" json:"members"`
+	CurrentSubscriptionID primitive.ObjectID   `bson:"currentSubscriptionId" json:"currentSubscriptionId"`
+	CreatedByID           primitive.ObjectID   `bson:"createdByID" json:"createdByID"`
+	CreatedAt             time.Time            `bson:"createdAt" json:"createdAt"`
+}
+
+// OrganizationMember is a member of an organization and their role in it
+type OrganizationMember struct {
+	UserID  primitive.ObjectID `bson:"userID" json:"userID"`
+	Role    OrganizationRole   `bson:"role" json:"role"`
+	AddedAt time.Time          `bson:"addedAt" json:"addedAt"`
+}
+
+// GetMember returns the user's membership of the organization
+func (o *Organization) GetMember(userID primitive.ObjectID) (OrganizationMember, bool) {
+	for _, member := range o.Members {
+		if member.UserID == userID {
+			return member, true
+		}
+	}
+	return OrganizationMember{}, false
+}
+
+// CanManage checks if the member's role lets them manage members and the organization's events
+func (m OrganizationMember) CanManage() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// IsValidOrganizationRole checks if the role exists
+func IsValidOrganizationRole(role OrganizationRole) bool {
+	switch role {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
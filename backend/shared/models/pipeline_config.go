@@ -66,8 +66,9 @@ type PipelineAction struct {
 // If an email field ID is provided, the email address will be pulled from the data.
 // SendEmail represents the action to send an email
 type SendEmail struct {
-	EmailTemplateID primitive.ObjectID `bson:"emailTemplateID" json:"emailTemplateID" validate:"required"`
-	EmailFieldID    string             `bson:"emailFieldID" json:"emailFieldID"`
+	EmailTemplateID      primitive.ObjectID `bson:"emailTemplateID" json:"emailTemplateID" validate:"required"`
+	EmailFieldID         string             `bson:"emailFieldID" json:"emailFieldID"`
+	AttachCalendarInvite bool               `bson:"attachCalendarInvite,omitempty" json:"attachCalendarInvite,omitempty"` // Adds the event as an .ics file so it can go straight into the recipient's calendar
 }
 
 type FormAllowedAccessOptions struct {
@@ -102,4 +103,6 @@ type PipelineConfiguration struct {
 	EventID       primitive.ObjectID `bson:"eventID" json:"eventID" validate:"required" mongoPreventOverride:"true"`
 	LastUpdatedAt time.Time          `bson:"lastUpdatedAt" json:"lastUpdatedAt" validate:"required"`
 	Enabled       bool               `bson:"enabled" json:"enabled" validate:"required"`
+	IsDeleted     bool               `bson:"isDeleted,omitempty" json:"isDeleted,omitempty" mongoPreventOverride:"true"`
+	DeletedAt     time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty" mongoPreventOverride:"true"` // When the pipeline was moved to the trash
 }
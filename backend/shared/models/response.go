@@ -14,4 +14,5 @@ type FormResponse struct {
 	UserID        primitive.ObjectID     `bson:"userID" json:"userID"`
 	CreatedAt     time.Time              `bson:"createdAt" json:"createdAt" validate:"required"`
 	LastUpdatedAt time.Time              `bson:"lastUpdatedAt" json:"lastUpdatedAt"`
+	CheckedInAt   time.Time              `bson:"checkedInAt,omitempty" json:"checkedInAt,omitempty" mongoPreventOverride:"true"` // Set by check-in staff when the applicant arrives
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScheduleItem is a session on an event's agenda, like the opening ceremony or a workshop
+type ScheduleItem struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EventID     primitive.ObjectID `bson:"eventID" json:"eventID"`
+	Title       string             `bson:"title" json:"title"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	StartTime   time.Time          `bson:"startTime" json:"startTime"`                   // Returned in the event's timezone
+	EndTime     time.Time          `bson:"endTime" json:"endTime"`                       // Returned in the event's timezone
+	Location    string             `bson:"location,omitempty" json:"location,omitempty"` // The room within the event's venue
+	Track       string             `bson:"track,omitempty" json:"track,omitempty"`
+	Speaker     string             `bson:"speaker,omitempty" json:"speaker,omitempty"`
+
+	RegisteredUserIDs []primitive.ObjectID `bson:"registeredUserIDs,omitempty" json:"-"` // Who registered to attend, only the count is ever returned
+	RegisteredCount   int                  `bson:"-" json:"registeredCount"`
+	Registered        bool                 `bson:"-" json:"registered"` // Whether the user asking is registered
+
+	CreatedAt     time.Time `bson:"createdAt" json:"createdAt"`
+	LastUpdatedAt time.Time `bson:"lastUpdatedAt" json:"lastUpdatedAt"`
+}
+
+// IsRegistered checks if the user registered to attend the session
+func (i ScheduleItem) IsRegistered(userID primitive.ObjectID) bool {
+	for _, registeredUserID := range i.RegisteredUserIDs {
+		if registeredUserID == userID {
+			return true
+		}
+	}
+	return false
+}
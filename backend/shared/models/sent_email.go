@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SentEmail is a record of an email a pipeline sent, kept so we can tell people what we've sent them
+type SentEmail struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	EventID         primitive.ObjectID `bson:"eventID" json:"eventID"`
+	EmailTemplateID primitive.ObjectID `bson:"emailTemplateID" json:"emailTemplateID"`
+	PipelineRunID   primitive.ObjectID `bson:"pipelineRunID" json:"pipelineRunID"`
+	To              string             `bson:"to" json:"to"`
+	From            string             `bson:"from" json:"from"`
+	Subject         string             `bson:"subject" json:"subject"`
+	Body            string             `bson:"body" json:"body"`
+	IsHTML          bool               `bson:"isHTML" json:"isHTML"`
+	SentAt          time.Time          `bson:"sentAt" json:"sentAt"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserSession represents a single login of a user, access tokens are tied to a session and refresh tokens rotate it
+type UserSession struct {
+	ID                       primitive.ObjectID `bson:"_id,omitempty" json:"id" mongoPreventOverride:"true"`
+	UserID                   primitive.ObjectID `bson:"userID" json:"userID" mongoPreventOverride:"true"`
+	RefreshTokenHash         string             `bson:"refreshTokenHash" json:"-"`
+	PreviousRefreshTokenHash string             `bson:"previousRefreshTokenHash" json:"-"` // Used to detect a refresh token being reused after rotation
+	UserAgent                string             `bson:"userAgent" json:"userAgent"`
+	IPAddress                string             `bson:"ipAddress" json:"ipAddress"`
+	CreatedAt                time.Time          `bson:"createdAt" json:"createdAt"`
+	LastRefreshedAt          time.Time          `bson:"lastRefreshedAt" json:"lastRefreshedAt"`
+	ExpiresAt                time.Time          `bson:"expiresAt" json:"expiresAt"`
+	RevokedAt                time.Time          `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	ImpersonatedByID         primitive.ObjectID `bson:"impersonatedByID,omitempty" json:"impersonatedByID,omitempty"` // The platform admin viewing the account for support, these sessions are read-only
+}
+
+// IsActive returns true if the session has not been revoked and has not expired
+func (s *UserSession) IsActive() bool {
+	return s.RevokedAt.IsZero() && s.ExpiresAt.After(time.Now())
+}
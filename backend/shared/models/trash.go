@@ -0,0 +1,10 @@
+package models
+
+// TrashPurgeReport counts what was permanently deleted from the trash once it was past the retention window
+type TrashPurgeReport struct {
+	FormsPurged          int64 `bson:"formsPurged" json:"formsPurged"`
+	ResponsesPurged      int64 `bson:"responsesPurged" json:"responsesPurged"` // Responses to the purged forms
+	EmailTemplatesPurged int64 `bson:"emailTemplatesPurged" json:"emailTemplatesPurged"`
+	PipelinesPurged      int64 `bson:"pipelinesPurged" json:"pipelinesPurged"`
+	PipelineRunsPurged   int64 `bson:"pipelineRunsPurged" json:"pipelineRunsPurged"` // Runs of the purged pipelines
+}
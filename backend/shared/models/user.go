@@ -13,6 +13,23 @@ type User struct {
 	FirstName             string             `bson:"firstName" json:"firstName"`
 	LastName              string             `bson:"lastName" json:"lastName"`
 	Email                 string             `bson:"email" json:"email"`
+	EmailVerified         bool               `bson:"emailVerified" json:"emailVerified"`
 	Birthday              time.Time          `bson:"birthday" json:"birthday"`
-	PasswordHash          string             `bson:"passwordHash" json:"-"` // Don't return the password hash
+	PasswordHash          string             `bson:"passwordHash" json:"-"` // Don't return the password hash, empty for users who only sign in with OIDC
+	OIDCIdentities        []OIDCIdentity     `bson:"oidcIdentities,omitempty" json:"-"`
+	TwoFactor             UserTwoFactor      `bson:"twoFactor" json:"twoFactor"`
+	IsPlatformAdmin       bool               `bson:"isPlatformAdmin,omitempty" json:"isPlatformAdmin,omitempty"` // Can use the /admin routes, only ever set directly in the database
+	Disabled              bool               `bson:"disabled,omitempty" json:"disabled,omitempty"`               // Disabled by a platform admin, the user can't log in
+	DisabledAt            time.Time          `bson:"disabledAt,omitempty" json:"disabledAt,omitempty"`
+	CalendarFeedTokenHash string             `bson:"calendarFeedTokenHash,omitempty" json:"-"` // The HashToken of the token in the user's calendar feed URL, empty until they create one
+}
+
+// UserTwoFactor holds a user's TOTP two-factor authentication settings, only whether it's enabled is ever returned
+type UserTwoFactor struct {
+	Enabled            bool      `bson:"enabled" json:"enabled"`
+	EnabledAt          time.Time `bson:"enabledAt,omitempty" json:"enabledAt,omitempty"`
+	Secret             string    `bson:"secret,omitempty" json:"-"`
+	PendingSecret      string    `bson:"pendingSecret,omitempty" json:"-"` // Set during enrollment until the user confirms a code
+	RecoveryCodeHashes []string  `bson:"recoveryCodeHashes,omitempty" json:"-"`
+	LastUsedStep       int64     `bson:"lastUsedStep,omitempty" json:"-"` // The last TOTP time step used, codes can't be replayed
 }
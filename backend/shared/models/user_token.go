@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserTokenType is what a single-use user token can be redeemed for
+type UserTokenType string
+
+const (
+	UserTokenPasswordReset     UserTokenType = "passwordReset"
+	UserTokenEmailVerification UserTokenType = "emailVerification"
+	UserTokenTwoFactorLogin    UserTokenType = "twoFactorLogin" // Issued after the first login step when 2FA is enabled
+	UserTokenMagicLink         UserTokenType = "magicLink"      // Logs the user in without their password
+)
+
+// UserToken is a single-use, expiring token that we email to a user, only the hash of the token is stored
+type UserToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userID" json:"userID"`
+	Type      UserTokenType      `bson:"type" json:"type"`
+	TokenHash string             `bson:"tokenHash" json:"-"`
+	Email     string             `bson:"email" json:"email"` // The email address the token was sent to
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	UsedAt    time.Time          `bson:"usedAt,omitempty" json:"usedAt,omitempty"`
+	Attempts  int                `bson:"attempts,omitempty" json:"attempts,omitempty"` // Failed attempts to redeem the token
+}
@@ -0,0 +1,480 @@
+package mongodb
+
+import (
+	"context"
+	"shared/logger"
+	"shared/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* ACCOUNT DELETIONS
+*
+ */
+
+const (
+	ACCOUNT_DELETION_COLLECTION = "account_deletions"
+)
+
+// CreateAccountDeletion stores a new account deletion job
+func (s *Service) CreateAccountDeletion(ctx context.Context, deletion models.AccountDeletion) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(ACCOUNT_DELETION_COLLECTION).InsertOne(ctx, deletion)
+}
+
+// GetAccountDeletion retrieves an account deletion job by its ID
+func (s *Service) GetAccountDeletion(ctx context.Context, deletionID primitive.ObjectID) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := s.Database.Collection(ACCOUNT_DELETION_COLLECTION).FindOne(ctx, bson.M{"_id": deletionID}).Decode(&deletion)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// GetAccountDeletionByStatusToken retrieves the account deletion job the status token was issued for
+func (s *Service) GetAccountDeletionByStatusToken(ctx context.Context, statusTokenHash string) (*models.AccountDeletion, error) {
+	var deletion models.AccountDeletion
+	err := s.Database.Collection(ACCOUNT_DELETION_COLLECTION).FindOne(ctx, bson.M{"statusTokenHash": statusTokenHash}).Decode(&deletion)
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// StartAccountDeletion marks the job as running, a job that already completed isn't matched so redelivered messages are ignored
+func (s *Service) StartAccountDeletion(ctx context.Context, deletionID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": deletionID, "status": bson.M{"$ne": models.AccountDeletionCompleted}}
+	update := bson.M{"$set": bson.M{"status": models.AccountDeletionRunning, "startedAt": time.Now()}}
+	return s.Database.Collection(ACCOUNT_DELETION_COLLECTION).UpdateOne(ctx, filter, update)
+}
+
+// FinishAccountDeletion records the outcome of the job, jobErr is nil if every step succeeded
+func (s *Service) FinishAccountDeletion(ctx context.Context, deletionID primitive.ObjectID, report models.AccountDeletionReport, jobErr error) error {
+	set := bson.M{"report": report, "completedAt": time.Now()}
+	if jobErr != nil {
+		set["status"] = models.AccountDeletionFailure
+		set["errorMsg"] = jobErr.Error()
+	} else {
+		set["status"] = models.AccountDeletionCompleted
+		set["errorMsg"] = ""
+	}
+
+	_, err := s.Database.Collection(ACCOUNT_DELETION_COLLECTION).UpdateOne(ctx, bson.M{"_id": deletionID}, bson.M{"$set": set})
+	return err
+}
+
+// DeleteUserData removes everything that belongs to a user and their account itself.
+// Every step only matches data that still needs cleaning up, so a failed job can safely be run again.
+func (s *Service) DeleteUserData(ctx context.Context, userID primitive.ObjectID, email string) (models.AccountDeletionReport, error) {
+	var report models.AccountDeletionReport
+
+	// Events they created are handed to another organizer, or deleted if nobody else runs them.
+	// Events owned by an organization stay with it.
+	cursor, err := s.Database.Collection("events").Find(ctx, bson.M{"createdByID": userID, "organizationID": bson.M{"$exists": false}})
+	if err != nil {
+		return report, err
+	}
+	var createdEvents []models.Event
+	if err := cursor.All(ctx, &createdEvents); err != nil {
+		return report, err
+	}
+
+	for _, event := range createdEvents {
+		successors := eventSuccessors(&event, userID)
+		if len(successors) == 0 {
+			if err := s.deleteEventInTransaction(ctx, event.ID); err != nil {
+				return report, err
+			}
+			report.EventsDeleted++
+			continue
+		}
+
+		newCreatorID, subscriptionID, err := s.chargeEventSuccessor(ctx, successors)
+		if err != nil {
+			return report, err
+		}
+
+		_, err = s.Database.Collection("events").UpdateOne(ctx,
+			bson.M{"_id": event.ID},
+			bson.M{"$set": bson.M{"createdByID": newCreatorID}, "$pull": bson.M{"organizerIDs": userID, "organizers": bson.M{"userID": userID}}},
+		)
+		if err != nil {
+			// Give the quota back so running the job again doesn't charge the successor twice
+			if !subscriptionID.IsZero() {
+				if _, releaseErr := s.DecrementSubscriptionEventUtilization(ctx, subscriptionID, event.ID); releaseErr != nil {
+					logger.Error("Failed to release event quota after a failed handover", releaseErr)
+				}
+			}
+			return report, err
+		}
+
+		// The account is already gone so their subscription is found by its user, it's cancelled further down anyway
+		_, err = s.Database.Collection(SUBSCRIPTION_COLLECTION).UpdateMany(ctx,
+			bson.M{"userId": userID, "status": models.SubscriptionStatusActive, "utilization.eventsCreated": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"utilization.eventsCreated": -1}},
+		)
+		if err != nil {
+			return report, err
+		}
+
+		_, err = s.UpdateEventOrganizer(ctx, event.ID, models.EventOrganizer{UserID: newCreatorID, Role: models.EventRoleOwner, AddedAt: time.Now()})
+		if err != nil {
+			return report, err
+		}
+		report.EventsTransferred++
+	}
+
+	membershipsResult, err := s.Database.Collection("events").UpdateMany(ctx, bson.M{"organizerIDs": userID}, bson.M{"$pull": bson.M{"organizerIDs": userID, "organizers": bson.M{"userID": userID}}})
+	if err != nil {
+		return report, err
+	}
+	report.OrganizerMembershipsRemoved = membershipsResult.ModifiedCount
+
+	if err := s.removeUserFromOrganizations(ctx, userID, &report); err != nil {
+		return report, err
+	}
+
+	// Goes after removeUserFromOrganizations so organizations they were the last owner of have a new one
+	if err := s.handOverOrganizationEvents(ctx, userID, &report); err != nil {
+		return report, err
+	}
+
+	_, err = s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).UpdateMany(ctx,
+		bson.M{"status": models.EventOwnershipTransferPending, "$or": []bson.M{{"fromUserID": userID}, {"toUserID": userID}}},
+		bson.M{"$set": bson.M{"status": models.EventOwnershipTransferCancelled, "respondedAt": time.Now()}},
+	)
+	if err != nil {
+		return report, err
+	}
+
+	// Found before the responses are deleted so their audit entries can still be matched if the job is run again
+	responseIDs, err := s.Database.Collection("responses").Distinct(ctx, "_id", bson.M{"userID": userID})
+	if err != nil {
+		return report, err
+	}
+	report.AuditEntriesScrubbed, err = s.scrubUserAuditEntries(ctx, userID, email, responseIDs)
+	if err != nil {
+		return report, err
+	}
+
+	// Responses hold whatever the user entered on the form so they're deleted rather than anonymized
+	responsesResult, err := s.Database.Collection("responses").DeleteMany(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return report, err
+	}
+	report.ResponsesDeleted = responsesResult.DeletedCount
+
+	scheduleResult, err := s.Database.Collection(SCHEDULE_ITEM_COLLECTION).UpdateMany(ctx, bson.M{"registeredUserIDs": userID}, bson.M{"$pull": bson.M{"registeredUserIDs": userID}})
+	if err != nil {
+		return report, err
+	}
+	report.ScheduleRegistrationsRemoved = scheduleResult.ModifiedCount
+
+	if email != "" {
+		formsResult, err := s.Database.Collection("forms").UpdateMany(ctx,
+			bson.M{"allowedSubmitters.email": email},
+			bson.M{"$pull": bson.M{"allowedSubmitters": bson.M{"email": email}}},
+		)
+		if err != nil {
+			return report, err
+		}
+		report.FormAccessRemoved = formsResult.ModifiedCount
+
+		sentEmailsResult, err := s.Database.Collection(SENT_EMAIL_COLLECTION).DeleteMany(ctx, bson.M{"to": email})
+		if err != nil {
+			return report, err
+		}
+		report.SentEmailsDeleted = sentEmailsResult.DeletedCount
+	}
+
+	subscriptionsResult, err := s.Database.Collection(SUBSCRIPTION_COLLECTION).UpdateMany(ctx,
+		bson.M{"userId": userID, "status": bson.M{"$ne": models.SubscriptionStatusCancelled}},
+		bson.M{"$set": bson.M{"status": models.SubscriptionStatusCancelled, "endDate": time.Now()}},
+	)
+	if err != nil {
+		return report, err
+	}
+	report.SubscriptionsCancelled = subscriptionsResult.ModifiedCount
+
+	sessionsResult, err := s.Database.Collection(SESSION_COLLECTION).DeleteMany(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return report, err
+	}
+	report.SessionsDeleted = sessionsResult.DeletedCount
+
+	apiKeysResult, err := s.Database.Collection(API_KEY_COLLECTION).DeleteMany(ctx, bson.M{"userID": userID})
+	if err != nil {
+		return report, err
+	}
+	report.APIKeysDeleted = apiKeysResult.DeletedCount
+
+	if _, err := s.Database.Collection(USER_TOKEN_COLLECTION).DeleteMany(ctx, bson.M{"userID": userID}); err != nil {
+		return report, err
+	}
+
+	if _, err := s.Database.Collection("users").DeleteOne(ctx, bson.M{"_id": userID}); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// eventSuccessors lists who an event could be handed to when userID's account is deleted, admins before more limited roles
+func eventSuccessors(event *models.Event, userID primitive.ObjectID) []primitive.ObjectID {
+	admins := []primitive.ObjectID{}
+	others := []primitive.ObjectID{}
+	for _, organizerID := range event.OrganizerIDs {
+		if organizerID == userID {
+			continue
+		}
+		if organizer, _ := event.GetOrganizer(organizerID); organizer.Role == models.EventRoleAdmin {
+			admins = append(admins, organizerID)
+		} else {
+			others = append(others, organizerID)
+		}
+	}
+	return append(admins, others...)
+}
+
+// chargeEventSuccessor picks the first successor whose plan has room for another event and counts the event against it, like
+// accepting an ownership transfer does. When nobody has room the first successor with a subscription takes it over their limit
+// rather than the event being deleted. The returned subscription ID is zero if nobody could be charged.
+func (s *Service) chargeEventSuccessor(ctx context.Context, successors []primitive.ObjectID) (primitive.ObjectID, primitive.ObjectID, error) {
+	var overLimitUserID, overLimitSubscriptionID primitive.ObjectID
+	for _, successorID := range successors {
+		successor, err := s.FindUserByID(ctx, successorID)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return primitive.NilObjectID, primitive.NilObjectID, err
+		}
+		if successor.CurrentSubscriptionID.IsZero() {
+			continue
+		}
+
+		_, err = s.IncrementSubscriptionUtilization(ctx, successor.CurrentSubscriptionID, "eventsCreated", "maxEvents")
+		if err == nil {
+			return successorID, successor.CurrentSubscriptionID, nil
+		}
+		if err != ERR_PLAN_NOT_FOUND_OR_LIMIT_EXCEEDED {
+			return primitive.NilObjectID, primitive.NilObjectID, err
+		}
+		if overLimitUserID.IsZero() {
+			overLimitUserID, overLimitSubscriptionID = successorID, successor.CurrentSubscriptionID
+		}
+	}
+
+	if overLimitUserID.IsZero() {
+		return successors[0], primitive.NilObjectID, nil
+	}
+
+	result, err := s.Database.Collection(SUBSCRIPTION_COLLECTION).UpdateOne(ctx,
+		bson.M{"_id": overLimitSubscriptionID, "status": models.SubscriptionStatusActive},
+		bson.M{"$inc": bson.M{"utilization.eventsCreated": 1}},
+	)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, err
+	}
+	if result.MatchedCount == 0 {
+		return overLimitUserID, primitive.NilObjectID, nil
+	}
+	return overLimitUserID, overLimitSubscriptionID, nil
+}
+
+// deleteEventAndDependents deletes an event along with its forms, their responses, its pipelines and their runs, email templates, secrets, invitations,
+// ownership transfers, schedule and the emails sent for it. API keys limited to the event are revoked. The audit log is kept.
+func (s *Service) deleteEventAndDependents(ctx context.Context, eventID primitive.ObjectID) error {
+	formIDs, err := s.Database.Collection("forms").Distinct(ctx, "_id", bson.M{"eventID": eventID})
+	if err != nil {
+		return err
+	}
+	if len(formIDs) > 0 {
+		if _, err := s.Database.Collection("responses").DeleteMany(ctx, bson.M{"formID": bson.M{"$in": formIDs}}); err != nil {
+			return err
+		}
+	}
+
+	pipelineIDs, err := s.Database.Collection("pipeline_configs").Distinct(ctx, "_id", bson.M{"eventID": eventID})
+	if err != nil {
+		return err
+	}
+	if len(pipelineIDs) > 0 {
+		if _, err := s.Database.Collection("pipeline_runs").DeleteMany(ctx, bson.M{"pipelineID": bson.M{"$in": pipelineIDs}}); err != nil {
+			return err
+		}
+	}
+
+	for _, collection := range []string{"forms", "pipeline_configs", "email_templates", "event_secrets", EVENT_INVITATION_COLLECTION, EVENT_OWNERSHIP_TRANSFER_COLLECTION, SCHEDULE_ITEM_COLLECTION, SENT_EMAIL_COLLECTION} {
+		if _, err := s.Database.Collection(collection).DeleteMany(ctx, bson.M{"eventID": eventID}); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.Database.Collection(API_KEY_COLLECTION).UpdateMany(ctx,
+		bson.M{"eventID": eventID, "revokedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revokedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	// The event goes last so a failed run can find its dependents again
+	_, err = s.Database.Collection("events").DeleteOne(ctx, bson.M{"_id": eventID})
+	return err
+}
+
+// handOverOrganizationEvents makes an owner of the event's organization the creator of every organization event the user
+// created. The organization's subscription already pays for them so no quota moves.
+func (s *Service) handOverOrganizationEvents(ctx context.Context, userID primitive.ObjectID, report *models.AccountDeletionReport) error {
+	cursor, err := s.Database.Collection("events").Find(ctx, bson.M{"createdByID": userID, "organizationID": bson.M{"$exists": true}})
+	if err != nil {
+		return err
+	}
+	var events []models.Event
+	if err := cursor.All(ctx, &events); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		organization, err := s.GetOrganization(ctx, event.OrganizationID)
+		if err != nil {
+			return err
+		}
+
+		var ownerID primitive.ObjectID
+		for _, member := range organization.Members {
+			if member.UserID != userID && member.Role == models.OrganizationRoleOwner {
+				ownerID = member.UserID
+				break
+			}
+		}
+		if ownerID.IsZero() {
+			// removeUserFromOrganizations always leaves an owner, this catches one removed in the meantime
+			return ErrSoleOrganizationMember
+		}
+
+		owner := models.EventOrganizer{UserID: ownerID, Role: models.EventRoleOwner, AddedAt: time.Now()}
+		if _, err := s.AddOrganizerToEvent(ctx, event.ID, owner); err != nil {
+			return err
+		}
+		if _, err := s.UpdateEventOrganizer(ctx, event.ID, owner); err != nil {
+			return err
+		}
+
+		result, err := s.Database.Collection("events").UpdateOne(ctx, bson.M{"_id": event.ID, "createdByID": userID}, bson.M{"$set": bson.M{"createdByID": ownerID}})
+		if err != nil {
+			return err
+		}
+		report.EventsTransferred += result.ModifiedCount
+	}
+	return nil
+}
+
+// scrubUserAuditEntries pseudonymizes the user in the audit log, the only time entries are changed. Their entries are kept so
+// an event's history still adds up, but under an ID that doesn't lead back to them, without the IP address and user agent
+// they were made from, and without the diffs that describe the user or hold their answers.
+func (s *Service) scrubUserAuditEntries(ctx context.Context, userID primitive.ObjectID, email string, responseIDs []interface{}) (int64, error) {
+	pseudonymID := primitive.NewObjectID()
+	withoutRequestDetails := bson.M{"ip": "", "userAgent": ""}
+	withoutDiff := bson.M{"diff": ""}
+
+	type auditUpdate struct {
+		filter bson.M
+		update bson.M
+	}
+	updates := []auditUpdate{
+		{bson.M{"actorID": userID}, bson.M{"$set": bson.M{"actorID": pseudonymID}, "$unset": withoutRequestDetails}},
+		{bson.M{"impersonatedByID": userID}, bson.M{"$set": bson.M{"impersonatedByID": pseudonymID}, "$unset": withoutRequestDetails}},
+		{
+			bson.M{"targetType": bson.M{"$in": []models.AuditTargetType{models.AuditTargetUser, models.AuditTargetOrganizer}}, "targetID": userID.Hex()},
+			bson.M{"$set": bson.M{"targetID": pseudonymID.Hex()}, "$unset": withoutDiff},
+		},
+		{
+			bson.M{"targetType": models.AuditTargetTransfer, "$or": []bson.M{{"diff.owner.before": userID.Hex()}, {"diff.owner.after": userID.Hex()}}},
+			bson.M{"$unset": withoutDiff},
+		},
+	}
+	if email != "" {
+		updates = append(updates, auditUpdate{bson.M{"targetType": models.AuditTargetInvitation, "diff.email.after": strings.ToLower(email)}, bson.M{"$unset": withoutDiff}})
+	}
+	if len(responseIDs) > 0 {
+		targetIDs := make([]string, 0, len(responseIDs))
+		for _, responseID := range responseIDs {
+			if id, ok := responseID.(primitive.ObjectID); ok {
+				targetIDs = append(targetIDs, id.Hex())
+			}
+		}
+		updates = append(updates, auditUpdate{bson.M{"targetType": models.AuditTargetResponse, "targetID": bson.M{"$in": targetIDs}}, bson.M{"$unset": withoutDiff}})
+	}
+
+	var scrubbed int64
+	for _, u := range updates {
+		result, err := s.Database.Collection(AUDIT_LOG_COLLECTION).UpdateMany(ctx, u.filter, u.update)
+		if err != nil {
+			return scrubbed, err
+		}
+		scrubbed += result.ModifiedCount
+	}
+	return scrubbed, nil
+}
+
+// removeUserFromOrganizations takes the user out of every organization, organizations they were the last owner of
+// are handed to an admin or failing that any other member
+func (s *Service) removeUserFromOrganizations(ctx context.Context, userID primitive.ObjectID, report *models.AccountDeletionReport) error {
+	cursor, err := s.Database.Collection(ORGANIZATION_COLLECTION).Find(ctx, bson.M{
+		"members": bson.M{"$elemMatch": bson.M{"userID": userID, "role": models.OrganizationRoleOwner}},
+	})
+	if err != nil {
+		return err
+	}
+	var ownedOrganizations []models.Organization
+	if err := cursor.All(ctx, &ownedOrganizations); err != nil {
+		return err
+	}
+
+	for _, organization := range ownedOrganizations {
+		var successor *models.OrganizationMember
+		hasOtherOwner := false
+		for i, member := range organization.Members {
+			if member.UserID == userID {
+				continue
+			}
+			if member.Role == models.OrganizationRoleOwner {
+				hasOtherOwner = true
+				break
+			}
+			if successor == nil || (member.Role == models.OrganizationRoleAdmin && successor.Role != models.OrganizationRoleAdmin) {
+				successor = &organization.Members[i]
+			}
+		}
+
+		if hasOtherOwner {
+			continue
+		}
+		if successor == nil {
+			// deleteUser refuses to delete the only member of an organization, this catches one created in the meantime
+			return ErrSoleOrganizationMember
+		}
+
+		successor.Role = models.OrganizationRoleOwner
+		if _, err := s.UpdateOrganizationMember(ctx, organization.ID, *successor); err != nil {
+			return err
+		}
+	}
+
+	result, err := s.Database.Collection(ORGANIZATION_COLLECTION).UpdateMany(ctx,
+		bson.M{"members.userID": userID},
+		bson.M{"$pull": bson.M{"members": bson.M{"userID": userID}}},
+	)
+	if err != nil {
+		return err
+	}
+	report.OrganizationMembershipsRemoved = result.ModifiedCount
+	return nil
+}
@@ -0,0 +1,340 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// mockDocument converts a model into the document a mock find returns for it
+func mockDocument(t *mtest.T, v interface{}) bson.D {
+	raw, err := bson.Marshal(v)
+	require.NoError(t, err)
+	var doc bson.D
+	require.NoError(t, bson.Unmarshal(raw, &doc))
+	return doc
+}
+
+// mockFind is the response to a find that returns the documents in a single batch
+func mockFind(t *mtest.T, collection string, docs ...interface{}) bson.D {
+	batch := make([]bson.D, 0, len(docs))
+	for _, doc := range docs {
+		batch = append(batch, mockDocument(t, doc))
+	}
+	return mtest.CreateCursorResponse(0, "test."+collection, mtest.FirstBatch, batch...)
+}
+
+// mockUpdated is the response to an update or delete that matched n documents
+func mockUpdated(n int) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+}
+
+// startedUpdate returns the first update statement of the i-th command the mock deployment received
+func startedUpdate(mt *mtest.T, i int) bson.Raw {
+	return mt.GetAllStartedEvents()[i].Command.Lookup("updates").Array().Index(0).Value().Document()
+}
+
+var mockCommandError = mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted"})
+
+func TestEventSuccessors(t *testing.T) {
+	userID := primitive.NewObjectID()
+	admin := primitive.NewObjectID()
+	viewer := primitive.NewObjectID()
+	legacy := primitive.NewObjectID()
+
+	event := &models.Event{
+		CreatedByID:  userID,
+		OrganizerIDs: []primitive.ObjectID{userID, viewer, legacy, admin},
+		Organizers: []models.EventOrganizer{
+			{UserID: viewer, Role: models.EventRoleViewer},
+			{UserID: admin, Role: models.EventRoleAdmin},
+		},
+	}
+
+	// Organizers from before roles existed count as admins, so they're tried with the admins
+	assert.Equal(t, []primitive.ObjectID{legacy, admin, viewer}, eventSuccessors(event, userID))
+	assert.Empty(t, eventSuccessors(&models.Event{OrganizerIDs: []primitive.ObjectID{userID}}, userID))
+}
+
+func TestChargeEventSuccessor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	full := models.User{ID: primitive.NewObjectID(), CurrentSubscriptionID: primitive.NewObjectID()}
+	withRoom := models.User{ID: primitive.NewObjectID(), CurrentSubscriptionID: primitive.NewObjectID()}
+	withoutSubscription := models.User{ID: primitive.NewObjectID()}
+
+	mt.Run("Charges the first successor with room", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mockFind(mt, "users", full), mockUpdated(0),
+			mockFind(mt, "users", withRoom), mockUpdated(1),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		successorID, subscriptionID, err := s.chargeEventSuccessor(context.Background(), []primitive.ObjectID{full.ID, withRoom.ID})
+		assert.NoError(mt, err)
+		assert.Equal(mt, withRoom.ID, successorID)
+		assert.Equal(mt, withRoom.CurrentSubscriptionID, subscriptionID)
+	})
+
+	mt.Run("Goes over the limit rather than deleting the event", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mockFind(mt, "users", withoutSubscription),
+			mockFind(mt, "users", full), mockUpdated(0),
+			mockUpdated(1),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		successorID, subscriptionID, err := s.chargeEventSuccessor(context.Background(), []primitive.ObjectID{withoutSubscription.ID, full.ID})
+		assert.NoError(mt, err)
+		assert.Equal(mt, full.ID, successorID)
+		assert.Equal(mt, full.CurrentSubscriptionID, subscriptionID)
+
+		update := startedUpdate(mt, 3)
+		assert.Equal(mt, full.CurrentSubscriptionID, update.Lookup("q", "_id").ObjectID())
+		_, err = update.LookupErr("q", "$expr")
+		assert.Error(mt, err, "the limit isn't checked the second time")
+	})
+
+	mt.Run("Nobody can be charged", func(mt *mtest.T) {
+		mt.AddMockResponses(mockFind(mt, "users", withoutSubscription))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		successorID, subscriptionID, err := s.chargeEventSuccessor(context.Background(), []primitive.ObjectID{withoutSubscription.ID})
+		assert.NoError(mt, err)
+		assert.Equal(mt, withoutSubscription.ID, successorID)
+		assert.True(mt, subscriptionID.IsZero())
+	})
+}
+
+func TestDeleteUserData(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	userID := primitive.NewObjectID()
+	successor := models.User{ID: primitive.NewObjectID(), CurrentSubscriptionID: primitive.NewObjectID()}
+	event := models.Event{
+		ID:           primitive.NewObjectID(),
+		CreatedByID:  userID,
+		OrganizerIDs: []primitive.ObjectID{userID, successor.ID},
+		Organizers:   []models.EventOrganizer{{UserID: userID, Role: models.EventRoleOwner}, {UserID: successor.ID, Role: models.EventRoleAdmin}},
+	}
+
+	mt.Run("Deletes the account last", func(mt *mtest.T) {
+		responseID := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mockFind(mt, "events"),
+			mockUpdated(2),
+			mockFind(mt, ORGANIZATION_COLLECTION),
+			mockUpdated(0),
+			mockFind(mt, "events"),
+			mockUpdated(0),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{responseID}}),
+			// The audit log
+			mockUpdated(3), mockUpdated(0), mockUpdated(1), mockUpdated(0), mockUpdated(0), mockUpdated(1),
+			mockUpdated(1), mockUpdated(0), mockUpdated(0), mockUpdated(0), mockUpdated(1), mockUpdated(2), mockUpdated(1), mockUpdated(1), mockUpdated(1),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		report, err := s.DeleteUserData(context.Background(), userID, "User@Example.com")
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(2), report.OrganizerMembershipsRemoved)
+		assert.Equal(mt, int64(5), report.AuditEntriesScrubbed)
+		assert.Equal(mt, int64(1), report.ResponsesDeleted)
+		assert.Equal(mt, int64(2), report.SessionsDeleted)
+
+		assert.Equal(mt, [][2]string{
+			{"find", "events"},
+			{"update", "events"},
+			{"find", ORGANIZATION_COLLECTION},
+			{"update", ORGANIZATION_COLLECTION},
+			{"find", "events"},
+			{"update", EVENT_OWNERSHIP_TRANSFER_COLLECTION},
+			{"distinct", "responses"},
+			{"update", AUDIT_LOG_COLLECTION},
+			{"update", AUDIT_LOG_COLLECTION},
+			{"update", AUDIT_LOG_COLLECTION},
+			{"update", AUDIT_LOG_COLLECTION},
+			{"update", AUDIT_LOG_COLLECTION},
+			{"update", AUDIT_LOG_COLLECTION},
+			{"delete", "responses"},
+			{"update", SCHEDULE_ITEM_COLLECTION},
+			{"update", "forms"},
+			{"delete", SENT_EMAIL_COLLECTION},
+			{"update", SUBSCRIPTION_COLLECTION},
+			{"delete", SESSION_COLLECTION},
+			{"delete", API_KEY_COLLECTION},
+			{"delete", USER_TOKEN_COLLECTION},
+			{"delete", "users"},
+		}, startedCommands(mt))
+
+		// Their answers are only kept out of the audit log of the responses they submitted
+		responses := startedUpdate(mt, 12)
+		assert.Equal(mt, string(models.AuditTargetResponse), responses.Lookup("q", "targetType").StringValue())
+		assert.Equal(mt, bson.A{responseID.Hex()}, mockArray(mt, responses.Lookup("q", "targetID", "$in")))
+	})
+
+	mt.Run("Hands a created event to another organizer and charges them for it", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mockFind(mt, "events", event),
+			mockFind(mt, "users", successor), mockUpdated(1),
+			mockUpdated(1),
+			mockUpdated(1),
+			mockUpdated(1),
+			// Stop the job at the next step, a run that fails is run again
+			mockCommandError,
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		report, err := s.DeleteUserData(context.Background(), userID, "")
+		assert.Error(mt, err)
+		assert.Equal(mt, int64(1), report.EventsTransferred)
+
+		handover := startedUpdate(mt, 3)
+		assert.Equal(mt, event.ID, handover.Lookup("q", "_id").ObjectID())
+		assert.Equal(mt, successor.ID, handover.Lookup("u", "$set", "createdByID").ObjectID())
+
+		// The event no longer counts against the deleted user's plan
+		release := startedUpdate(mt, 4)
+		assert.Equal(mt, userID, release.Lookup("q", "userId").ObjectID())
+		assert.Equal(mt, int32(-1), release.Lookup("u", "$inc", "utilization.eventsCreated").Int32())
+
+		owner := startedUpdate(mt, 5)
+		assert.Equal(mt, successor.ID, owner.Lookup("u", "$set", "organizers.$", "userID").ObjectID())
+		assert.Equal(mt, string(models.EventRoleOwner), owner.Lookup("u", "$set", "organizers.$", "role").StringValue())
+	})
+
+	mt.Run("Gives the quota back when the handover fails", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mockFind(mt, "events", event),
+			mockFind(mt, "users", successor), mockUpdated(1),
+			mockCommandError,
+			mockUpdated(1),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		_, err := s.DeleteUserData(context.Background(), userID, "")
+		assert.Error(mt, err)
+
+		// Otherwise running the job again would charge the successor twice
+		refund := startedUpdate(mt, 4)
+		assert.Equal(mt, successor.CurrentSubscriptionID, refund.Lookup("q", "_id").ObjectID())
+		assert.Equal(mt, int32(-1), refund.Lookup("u", "$inc", "utilization.eventsCreated").Int32())
+		assert.Len(mt, startedCommands(mt), 5, "the job stops at the failed handover")
+	})
+}
+
+// mockArray decodes a BSON array value for comparing with bson.A
+func mockArray(t *mtest.T, value bson.RawValue) bson.A {
+	var array bson.A
+	require.NoError(t, value.Unmarshal(&array))
+	return array
+}
+
+func TestHandOverOrganizationEvents(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	userID := primitive.NewObjectID()
+	otherOwnerID := primitive.NewObjectID()
+	organization := models.Organization{
+		ID: primitive.NewObjectID(),
+		Members: []models.OrganizationMember{
+			{UserID: userID, Role: models.OrganizationRoleOwner},
+			{UserID: primitive.NewObjectID(), Role: models.OrganizationRoleAdmin},
+			{UserID: otherOwnerID, Role: models.OrganizationRoleOwner},
+		},
+	}
+	event := models.Event{ID: primitive.NewObjectID(), CreatedByID: userID, OrganizationID: organization.ID}
+
+	mt.Run("An owner of the organization becomes the creator", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mockFind(mt, "events", event),
+			mockFind(mt, ORGANIZATION_COLLECTION, organization),
+			mockUpdated(1),
+			mockUpdated(1),
+			mockUpdated(1),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		var report models.AccountDeletionReport
+		assert.NoError(mt, s.handOverOrganizationEvents(context.Background(), userID, &report))
+		assert.Equal(mt, int64(1), report.EventsTransferred)
+
+		added := startedUpdate(mt, 2)
+		assert.Equal(mt, otherOwnerID, added.Lookup("u", "$addToSet", "organizerIDs").ObjectID())
+
+		creator := startedUpdate(mt, 4)
+		assert.Equal(mt, userID, creator.Lookup("q", "createdByID").ObjectID())
+		assert.Equal(mt, otherOwnerID, creator.Lookup("u", "$set", "createdByID").ObjectID())
+	})
+
+	mt.Run("Nobody left to own it", func(mt *mtest.T) {
+		alone := organization
+		alone.Members = organization.Members[:2]
+		mt.AddMockResponses(mockFind(mt, "events", event), mockFind(mt, ORGANIZATION_COLLECTION, alone))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		var report models.AccountDeletionReport
+		assert.Equal(mt, ErrSoleOrganizationMember, s.handOverOrganizationEvents(context.Background(), userID, &report))
+	})
+}
+
+func TestScrubUserAuditEntries(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	userID := primitive.NewObjectID()
+
+	mt.Run("Pseudonymizes the user", func(mt *mtest.T) {
+		mt.AddMockResponses(mockUpdated(4), mockUpdated(1), mockUpdated(2), mockUpdated(0), mockUpdated(1))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		scrubbed, err := s.scrubUserAuditEntries(context.Background(), userID, "User@Example.com", nil)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(8), scrubbed)
+		assert.Len(mt, mt.GetAllStartedEvents(), 5, "there are no responses to scrub")
+
+		actor := startedUpdate(mt, 0)
+		assert.Equal(mt, userID, actor.Lookup("q", "actorID").ObjectID())
+		pseudonymID := actor.Lookup("u", "$set", "actorID").ObjectID()
+		assert.NotEqual(mt, userID, pseudonymID)
+		for _, field := range []string{"ip", "userAgent"} {
+			_, err := actor.LookupErr("u", "$unset", field)
+			assert.NoError(mt, err, field)
+		}
+
+		impersonator := startedUpdate(mt, 1)
+		assert.Equal(mt, pseudonymID, impersonator.Lookup("u", "$set", "impersonatedByID").ObjectID(), "the same pseudonym is used throughout")
+
+		target := startedUpdate(mt, 2)
+		assert.Equal(mt, userID.Hex(), target.Lookup("q", "targetID").StringValue())
+		assert.Equal(mt, pseudonymID.Hex(), target.Lookup("u", "$set", "targetID").StringValue())
+		_, err = target.LookupErr("u", "$unset", "diff")
+		assert.NoError(mt, err)
+
+		invitation := startedUpdate(mt, 4)
+		assert.Equal(mt, "user@example.com", invitation.Lookup("q", "diff.email.after").StringValue(), "invitations are stored lowercase")
+	})
+
+	mt.Run("Stops at the first failure", func(mt *mtest.T) {
+		mt.AddMockResponses(mockUpdated(1), mockCommandError)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		_, err := s.scrubUserAuditEntries(context.Background(), userID, "", nil)
+		assert.Error(mt, err)
+		assert.Len(mt, mt.GetAllStartedEvents(), 2)
+	})
+}
+
+func TestStartAccountDeletion(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("A completed job isn't run again", func(mt *mtest.T) {
+		mt.AddMockResponses(mockUpdated(0))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		result, err := s.StartAccountDeletion(context.Background(), primitive.NewObjectID())
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(0), result.MatchedCount)
+		assert.Equal(mt, string(models.AccountDeletionCompleted), startedUpdate(mt, 0).Lookup("q", "status", "$ne").StringValue())
+	})
+}
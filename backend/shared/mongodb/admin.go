@@ -0,0 +1,87 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* PLATFORM ADMIN
+*
+* Queries across every user and event, only for the /admin routes
+ */
+
+// ListUsers returns the users matching the filter, along with how many match in total for paging
+func (s *Service) ListUsers(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.User, int64, error) {
+	collection := s.Database.Collection("users")
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []models.User{}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// SetUserDisabled disables or re-enables a user's account, disabled users can't log in
+func (s *Service) SetUserDisabled(ctx context.Context, userID primitive.ObjectID, disabled bool) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{"disabled": true, "disabledAt": time.Now()}}
+	if !disabled {
+		update = bson.M{"$unset": bson.M{"disabled": "", "disabledAt": ""}}
+	}
+	return s.Database.Collection("users").UpdateByID(ctx, userID, update)
+}
+
+// ListEvents returns whole events matching the filter, including who organizes them, along with how many match in total
+func (s *Service) ListEvents(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.Event, int64, error) {
+	collection := s.Database.Collection("events")
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.Event{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// UpdateSubscription changes the plan, status and limits of a subscription, its utilization is left alone
+func (s *Service) UpdateSubscription(ctx context.Context, subscriptionID primitive.ObjectID, subscription models.Subscription) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{
+		"planId": subscription.PlanID,
+		"status": subscription.Status,
+		"limits": subscription.Limits,
+	}}
+	return s.Database.Collection(SUBSCRIPTION_COLLECTION).UpdateByID(ctx, subscriptionID, update)
+}
+
+// UpdatePlanLimits changes the limits of a plan, subscriptions copy the limits when they're created so existing ones aren't affected
+func (s *Service) UpdatePlanLimits(ctx context.Context, planID primitive.ObjectID, limits models.PlanLimits) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{"limits": limits}}
+	return s.Database.Collection(PLAN_COLLECTION).UpdateByID(ctx, planID, update)
+}
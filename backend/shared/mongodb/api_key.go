@@ -0,0 +1,82 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* API KEYS
+*
+ */
+
+const (
+	API_KEY_COLLECTION = "api_keys"
+
+	// apiKeyLastUsedResolution limits how often lastUsed is written so busy scripts don't cause a write per request
+	apiKeyLastUsedResolution = time.Minute
+)
+
+// CreateAPIKey stores a new API key
+func (s *Service) CreateAPIKey(ctx context.Context, key models.APIKey) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(API_KEY_COLLECTION).InsertOne(ctx, key)
+}
+
+// GetAPIKeyByHash retrieves the API key with the hash, callers must check IsActive
+func (s *Service) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := s.Database.Collection(API_KEY_COLLECTION).FindOne(ctx, bson.M{"keyHash": keyHash}).Decode(&key)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListUserAPIKeys lists the API keys of a user that haven't been revoked, newest first
+func (s *Service) ListUserAPIKeys(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error) {
+	filter := bson.M{"userID": userID, "revokedAt": bson.M{"$exists": false}}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := s.Database.Collection(API_KEY_COLLECTION).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of a user's API keys, nothing matches if the key isn't theirs or is already revoked
+func (s *Service) RevokeAPIKey(ctx context.Context, userID primitive.ObjectID, keyID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": keyID, "userID": userID, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now()}}
+	return s.Database.Collection(API_KEY_COLLECTION).UpdateOne(ctx, filter, update)
+}
+
+// RevokeAllUserAPIKeys revokes every API key of a user
+func (s *Service) RevokeAllUserAPIKeys(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"userID": userID, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now()}}
+	return s.Database.Collection(API_KEY_COLLECTION).UpdateMany(ctx, filter, update)
+}
+
+// MarkAPIKeyUsed updates when the key was last used, at most once per apiKeyLastUsedResolution
+func (s *Service) MarkAPIKeyUsed(ctx context.Context, keyID primitive.ObjectID) error {
+	now := time.Now()
+	filter := bson.M{"_id": keyID, "$or": []bson.M{
+		{"lastUsed": bson.M{"$exists": false}},
+		{"lastUsed": bson.M{"$lt": now.Add(-apiKeyLastUsedResolution)}},
+	}}
+	_, err := s.Database.Collection(API_KEY_COLLECTION).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lastUsed": now}})
+	return err
+}
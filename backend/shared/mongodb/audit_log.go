@@ -0,0 +1,46 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* AUDIT LOG
+*
+ */
+
+const (
+	AUDIT_LOG_COLLECTION = "audit_log"
+)
+
+// CreateAuditEntry appends to the audit log, there's deliberately no way to change or remove entries
+func (s *Service) CreateAuditEntry(ctx context.Context, entry models.AuditEntry) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(AUDIT_LOG_COLLECTION).InsertOne(ctx, entry)
+}
+
+// ListAuditEntries returns the audit log entries matching the filter, along with how many match in total for paging
+func (s *Service) ListAuditEntries(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.AuditEntry, int64, error) {
+	collection := s.Database.Collection(AUDIT_LOG_COLLECTION)
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	entries := []models.AuditEntry{}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,39 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* CALENDAR FEEDS
+*
+ */
+
+// SetUserCalendarFeedToken replaces the token of the user's calendar feed, the old feed URL stops working.
+// An empty hash turns the feed off.
+func (s *Service) SetUserCalendarFeedToken(ctx context.Context, userId primitive.ObjectID, tokenHash string) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{"calendarFeedTokenHash": tokenHash}}
+	if tokenHash == "" {
+		update = bson.M{"$unset": bson.M{"calendarFeedTokenHash": ""}}
+	}
+	return s.Database.Collection("users").UpdateOne(ctx, bson.M{"_id": userId}, update)
+}
+
+// FindUserByCalendarFeedToken finds the user whose calendar feed has the token
+func (s *Service) FindUserByCalendarFeedToken(ctx context.Context, tokenHash string) (*models.User, error) {
+	if tokenHash == "" {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	var user models.User
+	err := s.Database.Collection("users").FindOne(ctx, bson.M{"calendarFeedTokenHash": tokenHash}).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
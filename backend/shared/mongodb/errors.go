@@ -14,4 +14,13 @@ var (
 
 	// ErrUserNotAuthorized is returned when the user does not have admin permission to modify the document
 	ErrUserNotAuthorized = errors.New("user is not authorized to modify the document")
+
+	// ErrSessionNotActive is returned when a login session has been revoked, has expired, or its refresh token was already used
+	ErrSessionNotActive = errors.New("session is not active")
+
+	// ErrNoSubscription is returned when the user or organization paying for something doesn't have a subscription
+	ErrNoSubscription = errors.New("no subscription")
+
+	// ErrSoleOrganizationMember is returned when deleting an account would leave an organization without any members
+	ErrSoleOrganizationMember = errors.New("user is the only member of an organization")
 )
@@ -0,0 +1,82 @@
+package mongodb
+
+import (
+	"context"
+	"shared/logger"
+	"shared/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+/*
+* EVENT CLONING
+*
+ */
+
+// EventCopy is a cloned event along with the forms, email templates and pipelines copied into it, every ID is already set
+type EventCopy struct {
+	Event          models.Event
+	Forms          []models.FormStructure
+	EmailTemplates []models.EmailTemplate
+	Pipelines      []models.PipelineConfiguration
+}
+
+// InsertEventCopy inserts a cloned event and everything copied into it.
+// We can't rely on transactions being available, so if anything fails whatever was already inserted is removed again.
+func (s *Service) InsertEventCopy(ctx context.Context, eventCopy EventCopy) error {
+	if _, err := s.Database.Collection("events").InsertOne(ctx, eventCopy.Event); err != nil {
+		return err
+	}
+
+	err := func() error {
+		if len(eventCopy.Forms) > 0 {
+			docs := make([]interface{}, len(eventCopy.Forms))
+			for i, form := range eventCopy.Forms {
+				docs[i] = form
+			}
+			if _, err := s.Database.Collection("forms").InsertMany(ctx, docs); err != nil {
+				return err
+			}
+		}
+
+		if len(eventCopy.EmailTemplates) > 0 {
+			docs := make([]interface{}, len(eventCopy.EmailTemplates))
+			for i, emailTemplate := range eventCopy.EmailTemplates {
+				docs[i] = emailTemplate
+			}
+			if _, err := s.Database.Collection("email_templates").InsertMany(ctx, docs); err != nil {
+				return err
+			}
+		}
+
+		if len(eventCopy.Pipelines) > 0 {
+			docs := make([]interface{}, len(eventCopy.Pipelines))
+			for i, pipeline := range eventCopy.Pipelines {
+				docs[i] = pipeline
+			}
+			if _, err := s.Database.Collection("pipeline_configs").InsertMany(ctx, docs); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}()
+	if err != nil {
+		s.removeEventCopy(ctx, eventCopy)
+	}
+	return err
+}
+
+// removeEventCopy undoes a partly inserted copy, only what's in the copy is removed
+func (s *Service) removeEventCopy(ctx context.Context, eventCopy EventCopy) {
+	filter := bson.M{"eventID": eventCopy.Event.ID}
+	for _, collection := range []string{"pipeline_configs", "email_templates", "forms"} {
+		if _, err := s.Database.Collection(collection).DeleteMany(ctx, filter); err != nil {
+			logger.Error("Failed to remove partly cloned "+collection, err)
+		}
+	}
+
+	if _, err := s.Database.Collection("events").DeleteOne(ctx, bson.M{"_id": eventCopy.Event.ID}); err != nil {
+		logger.Error("Failed to remove partly cloned event", err)
+	}
+}
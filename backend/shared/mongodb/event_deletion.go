@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* EVENT DELETION
+*
+ */
+
+// deleteEventInTransaction runs deleteEventAndDependents in a transaction so an event is never left half deleted.
+// Standalone servers can't run transactions, there the steps run on their own in an order that's safe to run again.
+func (s *Service) deleteEventInTransaction(ctx context.Context, eventID primitive.ObjectID) error {
+	supported, err := s.supportsTransactions(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		return s.deleteEventAndDependents(ctx, eventID)
+	}
+
+	session, err := s.Client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, s.deleteEventAndDependents(sessionCtx, eventID)
+	})
+	return err
+}
+
+// supportsTransactions checks if the server is a replica set member or a mongos, the only servers that can run transactions
+func (s *Service) supportsTransactions(ctx context.Context) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := s.Database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
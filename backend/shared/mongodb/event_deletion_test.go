@@ -0,0 +1,107 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSupportsTransactions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name     string
+		hello    bson.D
+		expected bool
+	}{
+		{"Replica set", bson.D{{Key: "setName", Value: "rs0"}}, true},
+		{"Sharded", bson.D{{Key: "msg", Value: "isdbgrid"}}, true},
+		{"Standalone", bson.D{}, false},
+	}
+
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(tt.hello...))
+			s := &Service{Client: mt.Client, Database: mt.DB}
+
+			supported, err := s.supportsTransactions(context.Background())
+			assert.NoError(mt, err)
+			assert.Equal(mt, tt.expected, supported)
+		})
+	}
+}
+
+func TestDeleteEventAndDependents(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	eventID := primitive.NewObjectID()
+	deleted := mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1})
+
+	mt.Run("Deletes the event last", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			// The forms and their responses, then the pipelines and their runs
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{primitive.NewObjectID()}}),
+			deleted,
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{primitive.NewObjectID()}}),
+			deleted,
+			// Everything stored by event ID
+			deleted, deleted, deleted, deleted, deleted, deleted, deleted, deleted,
+			// Revoking the API keys, then the event itself
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			deleted,
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		assert.NoError(mt, s.deleteEventAndDependents(context.Background(), eventID))
+		assert.Equal(mt, [][2]string{
+			{"distinct", "forms"},
+			{"delete", "responses"},
+			{"distinct", "pipeline_configs"},
+			{"delete", "pipeline_runs"},
+			{"delete", "forms"},
+			{"delete", "pipeline_configs"},
+			{"delete", "email_templates"},
+			{"delete", "event_secrets"},
+			{"delete", EVENT_INVITATION_COLLECTION},
+			{"delete", EVENT_OWNERSHIP_TRANSFER_COLLECTION},
+			{"delete", SCHEDULE_ITEM_COLLECTION},
+			{"delete", SENT_EMAIL_COLLECTION},
+			{"update", API_KEY_COLLECTION},
+			{"delete", "events"},
+		}, startedCommands(mt))
+	})
+
+	mt.Run("Skips responses and runs when there are no forms or pipelines", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+			deleted, deleted, deleted, deleted, deleted, deleted, deleted, deleted,
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			deleted,
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		assert.NoError(mt, s.deleteEventAndDependents(context.Background(), eventID))
+		for _, command := range startedCommands(mt) {
+			assert.NotEqual(mt, "responses", command[1])
+			assert.NotEqual(mt, "pipeline_runs", command[1])
+		}
+	})
+
+	mt.Run("Keeps the event when a dependent can't be deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "values", Value: bson.A{}}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted"}),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		assert.Error(mt, s.deleteEventAndDependents(context.Background(), eventID))
+		for _, command := range startedCommands(mt) {
+			assert.NotEqual(mt, "events", command[1], "the event is how a failed deletion finds its dependents again")
+		}
+	})
+}
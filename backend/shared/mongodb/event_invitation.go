@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* EVENT INVITATIONS
+*
+ */
+
+const (
+	EVENT_INVITATION_COLLECTION = "event_invitations"
+)
+
+// pendingEventInvitationFilter matches invitations that can still be accepted
+func pendingEventInvitationFilter() bson.M {
+	return bson.M{"status": models.EventInvitationPending, "expiresAt": bson.M{"$gt": time.Now()}}
+}
+
+// CreateEventInvitation stores a new invitation, any pending invitation to the same email for the event is revoked
+func (s *Service) CreateEventInvitation(ctx context.Context, invitation models.EventInvitation) (*mongo.InsertOneResult, error) {
+	_, err := s.Database.Collection(EVENT_INVITATION_COLLECTION).UpdateMany(ctx,
+		bson.M{"eventID": invitation.EventID, "email": invitation.Email, "status": models.EventInvitationPending},
+		bson.M{"$set": bson.M{"status": models.EventInvitationRevoked}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Database.Collection(EVENT_INVITATION_COLLECTION).InsertOne(ctx, invitation)
+}
+
+// ListPendingEventInvitations lists the invitations for an event that haven't been accepted, revoked or expired, newest first
+func (s *Service) ListPendingEventInvitations(ctx context.Context, eventID primitive.ObjectID) ([]models.EventInvitation, error) {
+	filter := pendingEventInvitationFilter()
+	filter["eventID"] = eventID
+	return s.listEventInvitations(ctx, filter)
+}
+
+// ListPendingEventInvitationsByEmail lists every pending invitation sent to an email
+func (s *Service) ListPendingEventInvitationsByEmail(ctx context.Context, email string) ([]models.EventInvitation, error) {
+	filter := pendingEventInvitationFilter()
+	filter["email"] = email
+	return s.listEventInvitations(ctx, filter)
+}
+
+func (s *Service) listEventInvitations(ctx context.Context, filter bson.M) ([]models.EventInvitation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := s.Database.Collection(EVENT_INVITATION_COLLECTION).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invitations := []models.EventInvitation{}
+	if err := cursor.All(ctx, &invitations); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// GetPendingEventInvitationByToken returns a pending invitation by the hash of its token.
+// Returns mongo.ErrNoDocuments if the invitation doesn't exist, was accepted or revoked, or has expired.
+func (s *Service) GetPendingEventInvitationByToken(ctx context.Context, tokenHash string) (*models.EventInvitation, error) {
+	filter := pendingEventInvitationFilter()
+	filter["tokenHash"] = tokenHash
+
+	var invitation models.EventInvitation
+	err := s.Database.Collection(EVENT_INVITATION_COLLECTION).FindOne(ctx, filter).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// AcceptEventInvitation atomically marks a pending invitation as accepted by the user.
+// Returns mongo.ErrNoDocuments if it can no longer be accepted.
+func (s *Service) AcceptEventInvitation(ctx context.Context, invitationID primitive.ObjectID, userID primitive.ObjectID) (*models.EventInvitation, error) {
+	filter := pendingEventInvitationFilter()
+	filter["_id"] = invitationID
+	update := bson.M{"$set": bson.M{"status": models.EventInvitationAccepted, "acceptedAt": time.Now(), "acceptedByID": userID}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var invitation models.EventInvitation
+	err := s.Database.Collection(EVENT_INVITATION_COLLECTION).FindOneAndUpdate(ctx, filter, update, opts).Decode(&invitation)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ReopenEventInvitation puts an accepted invitation back to pending, for when the user couldn't be added to the event after accepting it
+func (s *Service) ReopenEventInvitation(ctx context.Context, invitationID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": invitationID, "status": models.EventInvitationAccepted}
+	update := bson.M{
+		"$set":   bson.M{"status": models.EventInvitationPending},
+		"$unset": bson.M{"acceptedAt": "", "acceptedByID": ""},
+	}
+	return s.Database.Collection(EVENT_INVITATION_COLLECTION).UpdateOne(ctx, filter, update)
+}
+
+// RevokeEventInvitation revokes a pending invitation, nothing matches if it's not pending or belongs to another event
+func (s *Service) RevokeEventInvitation(ctx context.Context, eventID primitive.ObjectID, invitationID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": invitationID, "eventID": eventID, "status": models.EventInvitationPending}
+	update := bson.M{"$set": bson.M{"status": models.EventInvitationRevoked}}
+	return s.Database.Collection(EVENT_INVITATION_COLLECTION).UpdateOne(ctx, filter, update)
+}
@@ -0,0 +1,257 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* EVENT LISTING
+*
+* The options ListEventsMetadata takes, including the cursors the public event directory pages with
+ */
+
+// EventSort is the order events are listed in, ties are broken by when the event was created
+type EventSort string
+
+const (
+	EventSortStartTime     EventSort = "startTime"  // Soonest first, events without a start time come first
+	EventSortStartTimeDesc EventSort = "-startTime" // Latest first, events without a start time come last
+	EventSortName          EventSort = "name"
+	EventSortNewest        EventSort = "newest" // Most recently created first
+)
+
+// IsValidEventSort checks if the sort exists
+func IsValidEventSort(sort EventSort) bool {
+	switch sort {
+	case EventSortStartTime, EventSortStartTimeDesc, EventSortName, EventSortNewest:
+		return true
+	}
+	return false
+}
+
+// ErrInvalidEventCursor is returned when a cursor wasn't made by ListEventsMetadata with the same sort
+var ErrInvalidEventCursor = errors.New("invalid cursor")
+
+// EventListOptions filters, sorts and pages ListEventsMetadata, the zero value lists every event
+type EventListOptions struct {
+	IDs            []primitive.ObjectID // An empty but non-nil slice matches nothing
+	OrganizerID    primitive.ObjectID
+	OrganizationID primitive.ObjectID
+	Status         models.EventStatus
+	Visibility     models.EventVisibility
+
+	StartsAfter  time.Time // Inclusive, compared to the event's start time
+	StartsBefore time.Time // Inclusive, compared to the event's start time
+	Timezone     string
+	Tags         []string // Events with any of the tags
+
+	LocationType models.EventLocationType
+	Country      string // Case insensitive
+	City         string // Case insensitive
+	Near         *models.Coordinates
+	RadiusKm     float64 // How far from Near events can be
+	Search       string  // Full-text search on the name and description
+
+	Sort   EventSort // Defaults to EventSortNewest
+	Cursor string    // The next cursor from the previous page
+	Limit  int64     // 0 lists every event, with no next cursor
+}
+
+// earthRadiusKm converts distances to the radians $centerSphere takes
+const earthRadiusKm = 6378.1
+
+// caseInsensitiveMatch matches the whole of value ignoring case
+func caseInsensitiveMatch(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}
+}
+
+// eventCursor is the position of the last event of a page, Value is its sort field
+type eventCursor struct {
+	Sort  EventSort          `bson:"s"`
+	Value interface{}        `bson:"v,omitempty"`
+	ID    primitive.ObjectID `bson:"i"`
+}
+
+func (o EventListOptions) sortField() (string, int) {
+	switch o.Sort {
+	case EventSortStartTime:
+		return "metadata.startTime", 1
+	case EventSortStartTimeDesc:
+		return "metadata.startTime", -1
+	case EventSortName:
+		return "metadata.name", 1
+	default:
+		return "", -1
+	}
+}
+
+func (o EventListOptions) filter() (bson.M, error) {
+	conditions := []bson.M{}
+
+	if o.IDs != nil {
+		conditions = append(conditions, bson.M{"_id": bson.M{"$in": o.IDs}})
+	}
+	if !o.OrganizerID.IsZero() {
+		conditions = append(conditions, bson.M{"organizerIDs": o.OrganizerID})
+	}
+	if !o.OrganizationID.IsZero() {
+		conditions = append(conditions, bson.M{"organizationID": o.OrganizationID})
+	}
+	if o.Status != "" {
+		conditions = append(conditions, eventStatusFilter(o.Status))
+	}
+	if o.Visibility != "" {
+		conditions = append(conditions, bson.M{"metadata.visibility": o.Visibility})
+	}
+
+	startTime := bson.M{}
+	if !o.StartsAfter.IsZero() {
+		startTime["$gte"] = o.StartsAfter
+	}
+	if !o.StartsBefore.IsZero() {
+		startTime["$lte"] = o.StartsBefore
+	}
+	if len(startTime) > 0 {
+		conditions = append(conditions, bson.M{"metadata.startTime": startTime})
+	}
+
+	if o.Timezone != "" {
+		conditions = append(conditions, bson.M{"metadata.timezone": o.Timezone})
+	}
+	if o.LocationType != "" {
+		conditions = append(conditions, bson.M{"metadata.venue.type": o.LocationType})
+	}
+	if o.Country != "" {
+		conditions = append(conditions, bson.M{"metadata.venue.address.country": caseInsensitiveMatch(o.Country)})
+	}
+	if o.City != "" {
+		conditions = append(conditions, bson.M{"metadata.venue.address.city": caseInsensitiveMatch(o.City)})
+	}
+	if o.Near != nil {
+		center := bson.A{o.Near.Longitude, o.Near.Latitude}
+		conditions = append(conditions, bson.M{"metadata.venue.coordinates": bson.M{
+			"$geoWithin": bson.M{"$centerSphere": bson.A{center, o.RadiusKm / earthRadiusKm}},
+		}})
+	}
+	if len(o.Tags) > 0 {
+		conditions = append(conditions, bson.M{"metadata.tags": bson.M{"$in": o.Tags}})
+	}
+	if o.Search != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": o.Search}})
+	}
+
+	if o.Cursor != "" {
+		after, err := o.afterCursor()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, after)
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}, nil
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
+// afterCursor matches the events that come after the cursor in the sort order.
+// Mongo sorts missing values before everything else, so events without the field need handling on their own.
+func (o EventListOptions) afterCursor() (bson.M, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err != nil {
+		return nil, ErrInvalidEventCursor
+	}
+
+	var cursor eventCursor
+	if err := bson.Unmarshal(raw, &cursor); err != nil || cursor.Sort != o.Sort || cursor.ID.IsZero() {
+		return nil, ErrInvalidEventCursor
+	}
+
+	field, direction := o.sortField()
+	if field == "" {
+		return bson.M{"_id": bson.M{"$lt": cursor.ID}}, nil
+	}
+
+	if direction == 1 {
+		if cursor.Value == nil {
+			return bson.M{"$or": []bson.M{
+				{field: nil, "_id": bson.M{"$gt": cursor.ID}},
+				{field: bson.M{"$ne": nil}},
+			}}, nil
+		}
+		return bson.M{"$or": []bson.M{
+			{field: bson.M{"$gt": cursor.Value}},
+			{field: cursor.Value, "_id": bson.M{"$gt": cursor.ID}},
+		}}, nil
+	}
+
+	if cursor.Value == nil {
+		return bson.M{field: nil, "_id": bson.M{"$lt": cursor.ID}}, nil
+	}
+	return bson.M{"$or": []bson.M{
+		{field: bson.M{"$lt": cursor.Value}},
+		{field: cursor.Value, "_id": bson.M{"$lt": cursor.ID}},
+		{field: nil},
+	}}, nil
+}
+
+func (o EventListOptions) findOptions() *options.FindOptions {
+	opts := options.Find()
+
+	field, direction := o.sortField()
+	if field == "" {
+		opts.SetSort(bson.D{{Key: "_id", Value: direction}})
+	} else {
+		opts.SetSort(bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}})
+	}
+
+	if o.Limit > 0 {
+		// One extra tells us if there's another page
+		opts.SetLimit(o.Limit + 1)
+	}
+	return opts
+}
+
+// nextCursor returns the cursor of the page after the one ending with event
+func (o EventListOptions) nextCursor(event models.Event) (string, error) {
+	cursor := eventCursor{Sort: o.Sort, ID: event.ID}
+	switch o.Sort {
+	case EventSortStartTime, EventSortStartTimeDesc:
+		if !event.Metadata.StartTime.IsZero() {
+			cursor.Value = event.Metadata.StartTime
+		}
+	case EventSortName:
+		cursor.Value = event.Metadata.Name
+	}
+
+	raw, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// EnsureEventIndexes creates the indexes listing events relies on, it's safe to call when they already exist
+func (s *Service) EnsureEventIndexes(ctx context.Context) error {
+	_, err := s.Database.Collection("events").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// Full-text search of the event directory
+			Keys:    bson.D{{Key: "metadata.name", Value: "text"}, {Key: "metadata.description", Value: "text"}},
+			Options: options.Index().SetName("metadata_text").SetWeights(bson.M{"metadata.name": 5, "metadata.description": 1}),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "metadata.visibility", Value: 1}, {Key: "metadata.startTime", Value: 1}, {Key: "_id", Value: 1}},
+		},
+	})
+	return err
+}
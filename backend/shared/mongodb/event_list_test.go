@@ -0,0 +1,168 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"shared/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEventListCursor(t *testing.T) {
+	id := primitive.NewObjectID()
+	startTime := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	startValue := primitive.NewDateTimeFromTime(startTime) // Times come back out of the cursor as BSON dates
+
+	scheduled := models.Event{ID: id, Metadata: models.EventMetadata{Name: "Hackathon", StartTime: startTime}}
+	unscheduled := models.Event{ID: id, Metadata: models.EventMetadata{Name: "Hackathon"}}
+
+	tests := []struct {
+		name     string
+		sort     EventSort
+		event    models.Event
+		expected bson.M
+	}{
+		{
+			"Newest",
+			EventSortNewest,
+			scheduled,
+			bson.M{"_id": bson.M{"$lt": id}},
+		},
+		{
+			"Name ties on _id",
+			EventSortName,
+			scheduled,
+			bson.M{"$or": []bson.M{
+				{"metadata.name": bson.M{"$gt": "Hackathon"}},
+				{"metadata.name": "Hackathon", "_id": bson.M{"$gt": id}},
+			}},
+		},
+		{
+			"Start time ties on _id",
+			EventSortStartTime,
+			scheduled,
+			bson.M{"$or": []bson.M{
+				{"metadata.startTime": bson.M{"$gt": startValue}},
+				{"metadata.startTime": startValue, "_id": bson.M{"$gt": id}},
+			}},
+		},
+		{
+			"Start time without one comes before every scheduled event",
+			EventSortStartTime,
+			unscheduled,
+			bson.M{"$or": []bson.M{
+				{"metadata.startTime": nil, "_id": bson.M{"$gt": id}},
+				{"metadata.startTime": bson.M{"$ne": nil}},
+			}},
+		},
+		{
+			"Start time descending keeps the unscheduled events for last",
+			EventSortStartTimeDesc,
+			scheduled,
+			bson.M{"$or": []bson.M{
+				{"metadata.startTime": bson.M{"$lt": startValue}},
+				{"metadata.startTime": startValue, "_id": bson.M{"$lt": id}},
+				{"metadata.startTime": nil},
+			}},
+		},
+		{
+			"Start time descending without one only has unscheduled events left",
+			EventSortStartTimeDesc,
+			unscheduled,
+			bson.M{"metadata.startTime": nil, "_id": bson.M{"$lt": id}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := EventListOptions{Sort: tt.sort}.nextCursor(tt.event)
+			assert.NoError(t, err)
+
+			after, err := EventListOptions{Sort: tt.sort, Cursor: cursor}.afterCursor()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, after)
+		})
+	}
+}
+
+func TestEventListCursorInvalid(t *testing.T) {
+	nameCursor, err := EventListOptions{Sort: EventSortName}.nextCursor(models.Event{ID: primitive.NewObjectID()})
+	assert.NoError(t, err)
+
+	noID, _ := bson.Marshal(eventCursor{Sort: EventSortName})
+
+	tests := []struct {
+		name   string
+		sort   EventSort
+		cursor string
+	}{
+		{"Not base64", EventSortName, "not a cursor!"},
+		{"Not BSON", EventSortName, base64.RawURLEncoding.EncodeToString([]byte("garbage"))},
+		{"Different sort", EventSortStartTime, nameCursor},
+		{"No ID", EventSortName, base64.RawURLEncoding.EncodeToString(noID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EventListOptions{Sort: tt.sort, Cursor: tt.cursor}.filter()
+			assert.Equal(t, ErrInvalidEventCursor, err)
+		})
+	}
+}
+
+func TestEventListFilter(t *testing.T) {
+	organizerID := primitive.NewObjectID()
+	radiusKm := 50.0
+
+	tests := []struct {
+		name     string
+		options  EventListOptions
+		expected bson.M
+	}{
+		{"Everything", EventListOptions{}, bson.M{}},
+		{
+			"No IDs matches nothing",
+			EventListOptions{IDs: []primitive.ObjectID{}},
+			bson.M{"$and": []bson.M{{"_id": bson.M{"$in": []primitive.ObjectID{}}}}},
+		},
+		{
+			"Near",
+			EventListOptions{Near: &models.Coordinates{Longitude: -0.1276, Latitude: 51.5072}, RadiusKm: radiusKm},
+			bson.M{"$and": []bson.M{{"metadata.venue.coordinates": bson.M{
+				"$geoWithin": bson.M{"$centerSphere": bson.A{bson.A{-0.1276, 51.5072}, radiusKm / earthRadiusKm}},
+			}}}},
+		},
+		{
+			"Search",
+			EventListOptions{Search: "robotics"},
+			bson.M{"$and": []bson.M{{"$text": bson.M{"$search": "robotics"}}}},
+		},
+		{
+			"Case insensitive city and country",
+			EventListOptions{Country: "united kingdom", City: "St. Albans"},
+			bson.M{"$and": []bson.M{
+				{"metadata.venue.address.country": primitive.Regex{Pattern: "^united kingdom$", Options: "i"}},
+				{"metadata.venue.address.city": primitive.Regex{Pattern: `^St\. Albans$`, Options: "i"}},
+			}},
+		},
+		{
+			"Organizer and published",
+			EventListOptions{OrganizerID: organizerID, Status: models.EventStatusPublished},
+			bson.M{"$and": []bson.M{
+				{"organizerIDs": organizerID},
+				{"status": bson.M{"$in": []interface{}{models.EventStatusPublished, nil}}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.options.filter()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* EVENT OWNERSHIP TRANSFERS
+*
+ */
+
+const (
+	EVENT_OWNERSHIP_TRANSFER_COLLECTION = "event_ownership_transfers"
+)
+
+// CreateEventOwnershipTransfer stores a new transfer request, any pending request for the event is cancelled
+func (s *Service) CreateEventOwnershipTransfer(ctx context.Context, transfer models.EventOwnershipTransfer) (*mongo.InsertOneResult, error) {
+	_, err := s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).UpdateMany(ctx,
+		bson.M{"eventID": transfer.EventID, "status": models.EventOwnershipTransferPending},
+		bson.M{"$set": bson.M{"status": models.EventOwnershipTransferCancelled, "respondedAt": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).InsertOne(ctx, transfer)
+}
+
+// GetPendingEventOwnershipTransfer returns the event's unexpired pending transfer.
+// Returns mongo.ErrNoDocuments if there isn't one.
+func (s *Service) GetPendingEventOwnershipTransfer(ctx context.Context, eventID primitive.ObjectID) (*models.EventOwnershipTransfer, error) {
+	filter := bson.M{"eventID": eventID, "status": models.EventOwnershipTransferPending, "expiresAt": bson.M{"$gt": time.Now()}}
+
+	var transfer models.EventOwnershipTransfer
+	err := s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).FindOne(ctx, filter).Decode(&transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ListEventOwnershipTransfers lists every transfer request made for an event, newest first
+func (s *Service) ListEventOwnershipTransfers(ctx context.Context, eventID primitive.ObjectID) ([]models.EventOwnershipTransfer, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "requestedAt", Value: -1}})
+	cursor, err := s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).Find(ctx, bson.M{"eventID": eventID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	transfers := []models.EventOwnershipTransfer{}
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+// FinishEventOwnershipTransfer atomically moves a pending, unexpired transfer to its final status.
+// Returns mongo.ErrNoDocuments if the transfer is no longer pending.
+func (s *Service) FinishEventOwnershipTransfer(ctx context.Context, transferID primitive.ObjectID, status models.EventOwnershipTransferStatus, fromSubscriptionID primitive.ObjectID, toSubscriptionID primitive.ObjectID) (*models.EventOwnershipTransfer, error) {
+	filter := bson.M{"_id": transferID, "status": models.EventOwnershipTransferPending, "expiresAt": bson.M{"$gt": time.Now()}}
+	set := bson.M{"status": status, "respondedAt": time.Now()}
+	if !fromSubscriptionID.IsZero() {
+		set["fromSubscriptionID"] = fromSubscriptionID
+	}
+	if !toSubscriptionID.IsZero() {
+		set["toSubscriptionID"] = toSubscriptionID
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var transfer models.EventOwnershipTransfer
+	err := s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).FindOneAndUpdate(ctx, filter, bson.M{"$set": set}, opts).Decode(&transfer)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// ReopenEventOwnershipTransfer puts an accepted transfer back to pending, for when the event couldn't be handed over after accepting it
+func (s *Service) ReopenEventOwnershipTransfer(ctx context.Context, transferID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.Database.Collection(EVENT_OWNERSHIP_TRANSFER_COLLECTION).UpdateOne(ctx,
+		bson.M{"_id": transferID, "status": models.EventOwnershipTransferAccepted},
+		bson.M{
+			"$set":   bson.M{"status": models.EventOwnershipTransferPending},
+			"$unset": bson.M{"respondedAt": "", "fromSubscriptionID": "", "toSubscriptionID": ""},
+		},
+	)
+}
+
+// TransferEventOwnership makes newOwner the event's creator and owner and gives previousOwner the role they're passed.
+// Nothing matches if previousOwner no longer owns the event or newOwner is no longer an organizer.
+// It's a single update so a failure can't leave the creator moved without the roles, which would stop the transfer being reopened.
+func (s *Service) TransferEventOwnership(ctx context.Context, eventID primitive.ObjectID, previousOwner models.EventOrganizer, newOwner models.EventOrganizer) (*mongo.UpdateResult, error) {
+	// Both entries are replaced rather than updated in place, organizers added before roles existed don't have one
+	otherOrganizers := bson.M{"$filter": bson.M{
+		"input": bson.M{"$ifNull": bson.A{"$organizers", bson.A{}}},
+		"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this.userID", bson.A{previousOwner.UserID, newOwner.UserID}}}}},
+	}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"createdByID": newOwner.UserID,
+		"organizers":  bson.M{"$concatArrays": bson.A{otherOrganizers, bson.M{"$literal": bson.A{newOwner, previousOwner}}}},
+	}}}}
+
+	return s.Database.Collection("events").UpdateOne(ctx,
+		bson.M{"_id": eventID, "createdByID": previousOwner.UserID, "organizerIDs": newOwner.UserID},
+		update,
+	)
+}
@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestReopenEventOwnershipTransfer(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	transferID := primitive.NewObjectID()
+
+	mt.Run("Only reopens an accepted transfer", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		result, err := s.ReopenEventOwnershipTransfer(context.Background(), transferID)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(1), result.MatchedCount)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, transferID, update.Lookup("q", "_id").ObjectID())
+		assert.Equal(mt, string(models.EventOwnershipTransferAccepted), update.Lookup("q", "status").StringValue())
+		assert.Equal(mt, string(models.EventOwnershipTransferPending), update.Lookup("u", "$set", "status").StringValue())
+
+		// The subscriptions are picked again when the transfer is next accepted
+		for _, field := range []string{"respondedAt", "fromSubscriptionID", "toSubscriptionID"} {
+			_, err := update.LookupErr("u", "$unset", field)
+			assert.NoError(mt, err, field)
+		}
+	})
+
+	mt.Run("Nothing matches once the transfer has moved on", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		result, err := s.ReopenEventOwnershipTransfer(context.Background(), transferID)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(0), result.MatchedCount)
+	})
+}
+
+func TestTransferEventOwnership(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	eventID := primitive.NewObjectID()
+	previousOwner := models.EventOrganizer{UserID: primitive.NewObjectID(), Role: models.EventRoleAdmin}
+	newOwner := models.EventOrganizer{UserID: primitive.NewObjectID(), Role: models.EventRoleOwner}
+
+	mt.Run("Moves the creator and both roles in one update", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		result, err := s.TransferEventOwnership(context.Background(), eventID, previousOwner, newOwner)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(1), result.MatchedCount)
+		assert.Len(mt, mt.GetAllStartedEvents(), 1)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, previousOwner.UserID, update.Lookup("q", "createdByID").ObjectID())
+		assert.Equal(mt, newOwner.UserID, update.Lookup("q", "organizerIDs").ObjectID())
+
+		set := update.Lookup("u").Array().Index(0).Value().Document().Lookup("$set").Document()
+		assert.Equal(mt, newOwner.UserID, set.Lookup("createdByID").ObjectID())
+		organizers := set.Lookup("organizers", "$concatArrays").Array().Index(1).Value().Document().Lookup("$literal").Array()
+		assert.Equal(mt, newOwner.UserID, organizers.Index(0).Value().Document().Lookup("userID").ObjectID())
+		assert.Equal(mt, string(models.EventRoleOwner), organizers.Index(0).Value().Document().Lookup("role").StringValue())
+		assert.Equal(mt, previousOwner.UserID, organizers.Index(1).Value().Document().Lookup("userID").ObjectID())
+		assert.Equal(mt, string(models.EventRoleAdmin), organizers.Index(1).Value().Document().Lookup("role").StringValue())
+	})
+
+	mt.Run("Nothing changes once the event has moved on", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		result, err := s.TransferEventOwnership(context.Background(), eventID, previousOwner, newOwner)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(0), result.MatchedCount)
+		assert.Len(mt, mt.GetAllStartedEvents(), 1)
+	})
+}
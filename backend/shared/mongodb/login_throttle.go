@@ -0,0 +1,78 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* LOGIN THROTTLES
+*
+ */
+
+const (
+	LOGIN_THROTTLE_COLLECTION = "login_throttles"
+)
+
+// GetLoginThrottle returns the failed login count for an account or IP address.
+// Returns mongo.ErrNoDocuments if there haven't been any failures.
+func (s *Service) GetLoginThrottle(ctx context.Context, kind models.LoginThrottleKind, key string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := s.Database.Collection(LOGIN_THROTTLE_COLLECTION).FindOne(ctx, bson.M{"kind": kind, "key": key}).Decode(&throttle)
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RecordFailedLogin counts a failed login and returns the updated count. The count starts again
+// once there hasn't been a failure for the length of window, unless logins are locked.
+func (s *Service) RecordFailedLogin(ctx context.Context, kind models.LoginThrottleKind, key string, window time.Duration) (*models.LoginThrottle, error) {
+	now := time.Now()
+	collection := s.Database.Collection(LOGIN_THROTTLE_COLLECTION)
+
+	_, err := collection.DeleteOne(ctx, bson.M{
+		"kind":          kind,
+		"key":           key,
+		"lastFailureAt": bson.M{"$lt": now.Add(-window)},
+		"$or":           []bson.M{{"lockedUntil": bson.M{"$exists": false}}, {"lockedUntil": bson.M{"$lt": now}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$inc":         bson.M{"failures": 1},
+		"$set":         bson.M{"lastFailureAt": now},
+		"$setOnInsert": bson.M{"firstFailureAt": now},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var throttle models.LoginThrottle
+	err = collection.FindOneAndUpdate(ctx, bson.M{"kind": kind, "key": key}, update, opts).Decode(&throttle)
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// LockLogins locks logins until the given time. Nothing matches if logins are already locked,
+// so callers can tell when a lockout starts.
+func (s *Service) LockLogins(ctx context.Context, kind models.LoginThrottleKind, key string, until time.Time) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"kind": kind,
+		"key":  key,
+		"$or":  []bson.M{{"lockedUntil": bson.M{"$exists": false}}, {"lockedUntil": bson.M{"$lte": time.Now()}}},
+	}
+	return s.Database.Collection(LOGIN_THROTTLE_COLLECTION).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lockedUntil": until}})
+}
+
+// ClearLoginThrottle forgets the failed logins of an account or IP address, lifting any lockout
+func (s *Service) ClearLoginThrottle(ctx context.Context, kind models.LoginThrottleKind, key string) (*mongo.DeleteResult, error) {
+	return s.Database.Collection(LOGIN_THROTTLE_COLLECTION).DeleteOne(ctx, bson.M{"kind": kind, "key": key})
+}
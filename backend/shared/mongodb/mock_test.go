@@ -0,0 +1,15 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// startedCommands lists the command name and collection of every command the mock deployment received
+func startedCommands(mt *mtest.T) [][2]string {
+	commands := [][2]string{}
+	for _, event := range mt.GetAllStartedEvents() {
+		collection, _ := event.Command.Lookup(event.CommandName).StringValueOK()
+		commands = append(commands, [2]string{event.CommandName, collection})
+	}
+	return commands
+}
@@ -0,0 +1,60 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* OIDC
+*
+ */
+
+const (
+	OIDC_LOGIN_STATE_COLLECTION = "oidc_login_states"
+)
+
+// CreateOIDCLoginState stores the state of an OIDC login that was just started
+func (s *Service) CreateOIDCLoginState(ctx context.Context, state models.OIDCLoginState) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(OIDC_LOGIN_STATE_COLLECTION).InsertOne(ctx, state)
+}
+
+// ConsumeOIDCLoginState atomically removes and returns an unexpired login state so it can only be used once.
+// Returns mongo.ErrNoDocuments if the state doesn't exist, was already used, or has expired.
+func (s *Service) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error) {
+	filter := bson.M{"stateHash": stateHash, "expiresAt": bson.M{"$gt": time.Now()}}
+
+	var state models.OIDCLoginState
+	err := s.Database.Collection(OIDC_LOGIN_STATE_COLLECTION).FindOneAndDelete(ctx, filter).Decode(&state)
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// FindUserByOIDCIdentity finds the user linked to an account at an OIDC provider
+func (s *Service) FindUserByOIDCIdentity(ctx context.Context, issuer string, subject string) (*models.User, error) {
+	filter := bson.M{"oidcIdentities": bson.M{"$elemMatch": bson.M{"issuer": issuer, "subject": subject}}}
+
+	var user models.User
+	err := s.Database.Collection("users").FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// LinkUserOIDCIdentity links an OIDC provider account to a user, linking the same identity twice is a no-op
+func (s *Service) LinkUserOIDCIdentity(ctx context.Context, userId primitive.ObjectID, identity models.OIDCIdentity) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"_id":            userId,
+		"oidcIdentities": bson.M{"$not": bson.M{"$elemMatch": bson.M{"issuer": identity.Issuer, "subject": identity.Subject}}},
+	}
+	update := bson.M{"$push": bson.M{"oidcIdentities": identity}}
+	return s.Database.Collection("users").UpdateOne(ctx, filter, update)
+}
@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* ORGANIZATIONS
+*
+ */
+
+const (
+	ORGANIZATION_COLLECTION = "organizations"
+)
+
+func (s *Service) CreateOrganization(ctx context.Context, organization models.Organization) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(ORGANIZATION_COLLECTION).InsertOne(ctx, organization)
+}
+
+func (s *Service) GetOrganization(ctx context.Context, organizationID primitive.ObjectID) (*models.Organization, error) {
+	var organization models.Organization
+	err := s.Database.Collection(ORGANIZATION_COLLECTION).FindOne(ctx, bson.M{"_id": organizationID}).Decode(&organization)
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+// ListUserOrganizations lists the organizations the user is a member of, oldest first
+func (s *Service) ListUserOrganizations(ctx context.Context, userID primitive.ObjectID) ([]models.Organization, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := s.Database.Collection(ORGANIZATION_COLLECTION).Find(ctx, bson.M{"members.userID": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	organizations := []models.Organization{}
+	if err := cursor.All(ctx, &organizations); err != nil {
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// CountOrganizationsCreatedBy counts the organizations the user created that haven't been deleted
+func (s *Service) CountOrganizationsCreatedBy(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return s.Database.Collection(ORGANIZATION_COLLECTION).CountDocuments(ctx, bson.M{"createdByID": userID})
+}
+
+func (s *Service) UpdateOrganizationName(ctx context.Context, organizationID primitive.ObjectID, name string) (*mongo.UpdateResult, error) {
+	return s.Database.Collection(ORGANIZATION_COLLECTION).UpdateByID(ctx, organizationID, bson.M{"$set": bson.M{"name": name}})
+}
+
+// AddOrganizationMember adds a member with their role, nothing matches if they're already a member
+func (s *Service) AddOrganizationMember(ctx context.Context, organizationID primitive.ObjectID, member models.OrganizationMember) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": organizationID, "members.userID": bson.M{"$ne": member.UserID}}
+	return s.Database.Collection(ORGANIZATION_COLLECTION).UpdateOne(ctx, filter, bson.M{"$push": bson.M{"members": member}})
+}
+
+// UpdateOrganizationMember replaces an existing member's role
+func (s *Service) UpdateOrganizationMember(ctx context.Context, organizationID primitive.ObjectID, member models.OrganizationMember) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": organizationID, "members.userID": member.UserID}
+	return s.Database.Collection(ORGANIZATION_COLLECTION).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"members.$": member}})
+}
+
+func (s *Service) RemoveOrganizationMember(ctx context.Context, organizationID primitive.ObjectID, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	update := bson.M{"$pull": bson.M{"members": bson.M{"userID": userID}}}
+	return s.Database.Collection(ORGANIZATION_COLLECTION).UpdateByID(ctx, organizationID, update)
+}
+
+// DeleteOrganization deletes an organization and cancels its subscription, it's up to the caller to make sure it owns no events
+func (s *Service) DeleteOrganization(ctx context.Context, organizationID primitive.ObjectID) (*mongo.DeleteResult, error) {
+	_, err := s.Database.Collection(SUBSCRIPTION_COLLECTION).UpdateMany(ctx,
+		bson.M{"organizationId": organizationID, "status": bson.M{"$ne": models.SubscriptionStatusCancelled}},
+		bson.M{"$set": bson.M{"status": models.SubscriptionStatusCancelled}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Database.Collection(ORGANIZATION_COLLECTION).DeleteOne(ctx, bson.M{"_id": organizationID})
+}
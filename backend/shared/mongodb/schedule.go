@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* EVENT SCHEDULE
+*
+ */
+
+const (
+	SCHEDULE_ITEM_COLLECTION = "schedule_items"
+)
+
+// CreateScheduleItem adds a session to an event's schedule
+func (s *Service) CreateScheduleItem(ctx context.Context, item models.ScheduleItem) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(SCHEDULE_ITEM_COLLECTION).InsertOne(ctx, item)
+}
+
+// GetScheduleItem retrieves a session of an event's schedule
+func (s *Service) GetScheduleItem(ctx context.Context, eventID primitive.ObjectID, itemID primitive.ObjectID) (*models.ScheduleItem, error) {
+	var item models.ScheduleItem
+	err := s.Database.Collection(SCHEDULE_ITEM_COLLECTION).FindOne(ctx, bson.M{"_id": itemID, "eventID": eventID}).Decode(&item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ListScheduleItems lists an event's schedule in the order the sessions start
+func (s *Service) ListScheduleItems(ctx context.Context, eventID primitive.ObjectID) ([]models.ScheduleItem, error) {
+	return s.listScheduleItems(ctx, bson.M{"eventID": eventID})
+}
+
+// ListUserScheduleItems lists every session the user registered for across all events
+func (s *Service) ListUserScheduleItems(ctx context.Context, userID primitive.ObjectID) ([]models.ScheduleItem, error) {
+	return s.listScheduleItems(ctx, bson.M{"registeredUserIDs": userID})
+}
+
+func (s *Service) listScheduleItems(ctx context.Context, filter bson.M) ([]models.ScheduleItem, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "startTime", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := s.Database.Collection(SCHEDULE_ITEM_COLLECTION).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []models.ScheduleItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// UpdateScheduleItem replaces the details of a session, who registered for it is left alone
+func (s *Service) UpdateScheduleItem(ctx context.Context, item models.ScheduleItem) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{
+		"title":         item.Title,
+		"description":   item.Description,
+		"startTime":     item.StartTime,
+		"endTime":       item.EndTime,
+		"location":      item.Location,
+		"track":         item.Track,
+		"speaker":       item.Speaker,
+		"lastUpdatedAt": item.LastUpdatedAt,
+	}}
+	return s.Database.Collection(SCHEDULE_ITEM_COLLECTION).UpdateOne(ctx, bson.M{"_id": item.ID, "eventID": item.EventID}, update)
+}
+
+// DeleteScheduleItem removes a session from an event's schedule
+func (s *Service) DeleteScheduleItem(ctx context.Context, eventID primitive.ObjectID, itemID primitive.ObjectID) (*mongo.DeleteResult, error) {
+	return s.Database.Collection(SCHEDULE_ITEM_COLLECTION).DeleteOne(ctx, bson.M{"_id": itemID, "eventID": eventID})
+}
+
+// SetScheduleItemRegistration registers or unregisters the user for a session, registering twice does nothing
+func (s *Service) SetScheduleItemRegistration(ctx context.Context, eventID primitive.ObjectID, itemID primitive.ObjectID, userID primitive.ObjectID, registered bool) (*mongo.UpdateResult, error) {
+	update := bson.M{"$addToSet": bson.M{"registeredUserIDs": userID}}
+	if !registered {
+		update = bson.M{"$pull": bson.M{"registeredUserIDs": userID}}
+	}
+	return s.Database.Collection(SCHEDULE_ITEM_COLLECTION).UpdateOne(ctx, bson.M{"_id": itemID, "eventID": eventID}, update)
+}
@@ -0,0 +1,40 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* SENT EMAILS
+*
+ */
+
+const (
+	SENT_EMAIL_COLLECTION = "sent_emails"
+)
+
+// RecordSentEmail stores a record of an email that was sent
+func (s *Service) RecordSentEmail(ctx context.Context, email models.SentEmail) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(SENT_EMAIL_COLLECTION).InsertOne(ctx, email)
+}
+
+// ListSentEmails lists the emails sent to an address, newest first
+func (s *Service) ListSentEmails(ctx context.Context, to string) ([]models.SentEmail, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "sentAt", Value: -1}})
+	cursor, err := s.Database.Collection(SENT_EMAIL_COLLECTION).Find(ctx, bson.M{"to": to}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	emails := []models.SentEmail{}
+	if err := cursor.All(ctx, &emails); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
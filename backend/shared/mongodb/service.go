@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log"
 	"reflect"
+	"shared/logger"
 	"shared/models"
 	"shared/utils"
 	"time"
@@ -23,18 +24,31 @@ import (
 // MongoService defines the interface for interacting with MongoDB.
 type MongoService interface {
 	FindUserByEmail(ctx context.Context, email string) (*models.User, error)
+	FindUserByID(ctx context.Context, userId primitive.ObjectID) (*models.User, error)
 	InsertUser(ctx context.Context, user models.User) (*mongo.InsertOneResult, error)
 	GetUserDetails(ctx context.Context, userId primitive.ObjectID) (*models.User, error)
 	DeleteUserByEmail(ctx context.Context, email string) (*mongo.DeleteResult, error)
 	UpdateUserDetails(ctx context.Context, userId primitive.ObjectID, updatedUserDetails models.User) error
 	UpdateUser(ctx context.Context, userId primitive.ObjectID, user models.User) (*mongo.UpdateResult, error)
+	UpdateUserPassword(ctx context.Context, userId primitive.ObjectID, passwordHash string) error
+	UpdateUserEmail(ctx context.Context, userId primitive.ObjectID, email string) error
+	MarkUserEmailVerified(ctx context.Context, userId primitive.ObjectID, email string) (*mongo.UpdateResult, error)
+	MarkLegacyUsersEmailVerified(ctx context.Context) (*mongo.UpdateResult, error)
 	CreateEvent(ctx context.Context, event models.Event) (*mongo.InsertOneResult, error)
-	DeleteEvent(ctx *gin.Context, eventID primitive.ObjectID) (*mongo.DeleteResult, error)
+	DeleteEvent(ctx *gin.Context, eventID primitive.ObjectID) (*models.Event, error)
 	GetEvent(ctx *gin.Context, eventID primitive.ObjectID) (*models.Event, error)
 	UpdateEventMetadata(ctx *gin.Context, eventID primitive.ObjectID, metadata models.EventMetadata) (*mongo.UpdateResult, error)
-	ListEventsMetadata(ctx context.Context, filter bson.M) ([]models.Event, error)
-	AddOrganizerToEvent(ctx context.Context, eventID primitive.ObjectID, organizerID primitive.ObjectID) (*mongo.UpdateResult, error)
+	InsertEventCopy(ctx context.Context, eventCopy EventCopy) error
+	SetEventStatus(ctx context.Context, eventID primitive.ObjectID, from []models.EventStatus, to models.EventStatus) (*mongo.UpdateResult, error)
+	ListEventsMetadata(ctx context.Context, opts EventListOptions) ([]models.Event, string, error)
+	EnsureEventIndexes(ctx context.Context) error
+	AddOrganizerToEvent(ctx context.Context, eventID primitive.ObjectID, organizer models.EventOrganizer) (*mongo.UpdateResult, error)
+	UpdateEventOrganizer(ctx context.Context, eventID primitive.ObjectID, organizer models.EventOrganizer) (*mongo.UpdateResult, error)
 	RemoveOrganizerFromEvent(ctx context.Context, eventID primitive.ObjectID, organizerID primitive.ObjectID) (*mongo.UpdateResult, error)
+	UpdateEventSettings(ctx context.Context, eventID primitive.ObjectID, settings models.EventSettings) (*mongo.UpdateResult, error)
+	GetEventSettings(ctx context.Context, eventID primitive.ObjectID) (*models.EventSettings, error)
+	GetEventVenue(ctx context.Context, eventID primitive.ObjectID) (*models.EventVenue, error)
+	FindEventByID(ctx context.Context, eventID primitive.ObjectID) (*models.Event, error)
 	CreateSource(ctx context.Context, source models.SelectorSource) (*mongo.InsertOneResult, error)
 	UpdateSource(ctx context.Context, source models.SelectorSource, sourceID primitive.ObjectID) (*mongo.UpdateResult, error)
 	GetSourceByName(ctx context.Context, name string) (*models.SelectorSource, error)
@@ -44,15 +58,16 @@ type MongoService interface {
 	CreateForm(ctx context.Context, form models.FormStructure) (*mongo.InsertOneResult, error)
 	UpdateForm(ctx context.Context, form models.FormStructure, formID primitive.ObjectID) (*mongo.UpdateResult, error)
 	AddAllowedSubmitter(ctx context.Context, formID primitive.ObjectID, submitter models.FormAllowedSubmitter) (*mongo.UpdateResult, error)
-	DeleteForm(ctx context.Context, formID primitive.ObjectID) (*mongo.DeleteResult, error)
+	DeleteForm(ctx context.Context, formID primitive.ObjectID) (*mongo.UpdateResult, error)
 	CreatePipeline(ctx context.Context, pipeline models.PipelineConfiguration) (*mongo.InsertOneResult, error)
 	UpdatePipeline(ctx context.Context, pipeline models.PipelineConfiguration, pipelineID primitive.ObjectID) (*mongo.UpdateResult, error)
 	GetPipeline(ctx context.Context, pipelineID primitive.ObjectID) (*models.PipelineConfiguration, error)
 	ListPipelines(ctx context.Context, filter bson.M) ([]models.PipelineConfiguration, error)
-	DeletePipeline(ctx context.Context, pipelineID primitive.ObjectID) (*mongo.DeleteResult, error)
+	DeletePipeline(ctx context.Context, pipelineID primitive.ObjectID) (*mongo.UpdateResult, error)
 	ListResponses(ctx context.Context, filter bson.M, options *options.FindOptions) ([]models.FormResponse, error)
 	CreateResponse(ctx context.Context, response models.FormResponse) (*mongo.InsertOneResult, error)
 	UpdateResponse(ctx context.Context, response models.FormResponse, responseID primitive.ObjectID) (*mongo.UpdateResult, error)
+	CheckInResponse(ctx context.Context, formID primitive.ObjectID, responseID primitive.ObjectID) (*mongo.UpdateResult, error)
 	DeleteResponse(ctx context.Context, responseID primitive.ObjectID) (*mongo.DeleteResult, error)
 	CreatePipelineRun(ctx context.Context, pipelineRun models.PipelineRun) (*mongo.InsertOneResult, error)
 	GetPipelineRun(ctx context.Context, filter bson.M) (*models.PipelineRun, error)
@@ -61,7 +76,7 @@ type MongoService interface {
 	ListEmailTemplates(ctx context.Context, filter bson.M) ([]models.EmailTemplate, error)
 	CreateEmailTemplate(ctx context.Context, emailTemplate models.EmailTemplate) (*mongo.InsertOneResult, error)
 	UpdateEmailTemplate(ctx context.Context, emailTemplate models.EmailTemplate, emailTemplateID primitive.ObjectID) (*mongo.UpdateResult, error)
-	DeleteEmailTemplate(ctx context.Context, emailTemplateID primitive.ObjectID) (*mongo.DeleteResult, error)
+	DeleteEmailTemplate(ctx context.Context, emailTemplateID primitive.ObjectID) (*mongo.UpdateResult, error)
 	GetEmailTemplate(ctx context.Context, emailTemplateID primitive.ObjectID) (*models.EmailTemplate, error)
 	GetEventSecrets(ctx context.Context, filter bson.M, stripSecrets bool) (*models.EventSecrets, error)
 	CreateOrUpdateEventSecrets(ctx context.Context, secret models.EventSecrets) (*mongo.UpdateResult, error)
@@ -75,6 +90,123 @@ type MongoService interface {
 	GetSubscription(ctx context.Context, subscriptionID primitive.ObjectID) (*models.Subscription, error)
 	IncrementSubscriptionUtilization(ctx context.Context, subscriptionID primitive.ObjectID, utilizationKey string, limitKey string) (*mongo.UpdateResult, error)
 	DecrementSubscriptionEventUtilization(ctx context.Context, subscriptionID primitive.ObjectID, eventID primitive.ObjectID) (*mongo.UpdateResult, error)
+	GetEventSubscription(ctx context.Context, eventID primitive.ObjectID) (*models.Subscription, error)
+
+	// Sessions
+	CreateUserSession(ctx context.Context, session models.UserSession) (*mongo.InsertOneResult, error)
+	GetUserSession(ctx context.Context, sessionID primitive.ObjectID) (*models.UserSession, error)
+	GetUserSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.UserSession, error)
+	RotateUserSessionRefreshToken(ctx context.Context, sessionID primitive.ObjectID, oldRefreshTokenHash string, newRefreshTokenHash string, expiresAt time.Time) (*mongo.UpdateResult, error)
+	RevokeUserSession(ctx context.Context, sessionID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RevokeAllUserSessions(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RevokeOtherUserSessions(ctx context.Context, userID primitive.ObjectID, keepSessionID primitive.ObjectID) (*mongo.UpdateResult, error)
+
+	// User Tokens
+	CreateUserToken(ctx context.Context, token models.UserToken) (*mongo.InsertOneResult, error)
+	ConsumeUserToken(ctx context.Context, tokenHash string, tokenType models.UserTokenType) (*models.UserToken, error)
+	InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType) (*mongo.UpdateResult, error)
+	InvalidateAllUserTokens(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error)
+	GetUserToken(ctx context.Context, tokenHash string, tokenType models.UserTokenType) (*models.UserToken, error)
+	RecordFailedUserTokenAttempt(ctx context.Context, tokenID primitive.ObjectID, maxAttempts int) error
+	CountRecentUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType, since time.Time) (int64, error)
+
+	// API Keys
+	CreateAPIKey(ctx context.Context, key models.APIKey) (*mongo.InsertOneResult, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListUserAPIKeys(ctx context.Context, userID primitive.ObjectID) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID primitive.ObjectID, keyID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RevokeAllUserAPIKeys(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error)
+	MarkAPIKeyUsed(ctx context.Context, keyID primitive.ObjectID) error
+
+	// Two Factor
+	SetUserTwoFactorPendingSecret(ctx context.Context, userId primitive.ObjectID, secret string) error
+	EnableUserTwoFactor(ctx context.Context, userId primitive.ObjectID, secret string, recoveryCodeHashes []string, usedStep int64) (*mongo.UpdateResult, error)
+	DisableUserTwoFactor(ctx context.Context, userId primitive.ObjectID) error
+	SetUserTwoFactorRecoveryCodes(ctx context.Context, userId primitive.ObjectID, recoveryCodeHashes []string) error
+	UseUserTwoFactorStep(ctx context.Context, userId primitive.ObjectID, step int64) (*mongo.UpdateResult, error)
+	ConsumeUserTwoFactorRecoveryCode(ctx context.Context, userId primitive.ObjectID, recoveryCodeHash string) (*mongo.UpdateResult, error)
+
+	// OIDC
+	CreateOIDCLoginState(ctx context.Context, state models.OIDCLoginState) (*mongo.InsertOneResult, error)
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (*models.OIDCLoginState, error)
+	FindUserByOIDCIdentity(ctx context.Context, issuer string, subject string) (*models.User, error)
+	LinkUserOIDCIdentity(ctx context.Context, userId primitive.ObjectID, identity models.OIDCIdentity) (*mongo.UpdateResult, error)
+
+	// Account Deletion
+	CreateAccountDeletion(ctx context.Context, deletion models.AccountDeletion) (*mongo.InsertOneResult, error)
+	GetAccountDeletion(ctx context.Context, deletionID primitive.ObjectID) (*models.AccountDeletion, error)
+	GetAccountDeletionByStatusToken(ctx context.Context, statusTokenHash string) (*models.AccountDeletion, error)
+	StartAccountDeletion(ctx context.Context, deletionID primitive.ObjectID) (*mongo.UpdateResult, error)
+	FinishAccountDeletion(ctx context.Context, deletionID primitive.ObjectID, report models.AccountDeletionReport, jobErr error) error
+	DeleteUserData(ctx context.Context, userID primitive.ObjectID, email string) (models.AccountDeletionReport, error)
+
+	// Sent Emails
+	RecordSentEmail(ctx context.Context, email models.SentEmail) (*mongo.InsertOneResult, error)
+	ListSentEmails(ctx context.Context, to string) ([]models.SentEmail, error)
+
+	// Event Invitations
+	CreateEventInvitation(ctx context.Context, invitation models.EventInvitation) (*mongo.InsertOneResult, error)
+	ListPendingEventInvitations(ctx context.Context, eventID primitive.ObjectID) ([]models.EventInvitation, error)
+	ListPendingEventInvitationsByEmail(ctx context.Context, email string) ([]models.EventInvitation, error)
+	GetPendingEventInvitationByToken(ctx context.Context, tokenHash string) (*models.EventInvitation, error)
+	AcceptEventInvitation(ctx context.Context, invitationID primitive.ObjectID, userID primitive.ObjectID) (*models.EventInvitation, error)
+	ReopenEventInvitation(ctx context.Context, invitationID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RevokeEventInvitation(ctx context.Context, eventID primitive.ObjectID, invitationID primitive.ObjectID) (*mongo.UpdateResult, error)
+
+	// Event Ownership Transfers
+	CreateEventOwnershipTransfer(ctx context.Context, transfer models.EventOwnershipTransfer) (*mongo.InsertOneResult, error)
+	GetPendingEventOwnershipTransfer(ctx context.Context, eventID primitive.ObjectID) (*models.EventOwnershipTransfer, error)
+	ListEventOwnershipTransfers(ctx context.Context, eventID primitive.ObjectID) ([]models.EventOwnershipTransfer, error)
+	FinishEventOwnershipTransfer(ctx context.Context, transferID primitive.ObjectID, status models.EventOwnershipTransferStatus, fromSubscriptionID primitive.ObjectID, toSubscriptionID primitive.ObjectID) (*models.EventOwnershipTransfer, error)
+	ReopenEventOwnershipTransfer(ctx context.Context, transferID primitive.ObjectID) (*mongo.UpdateResult, error)
+	TransferEventOwnership(ctx context.Context, eventID primitive.ObjectID, previousOwner models.EventOrganizer, newOwner models.EventOrganizer) (*mongo.UpdateResult, error)
+
+	// Organizations
+	CreateOrganization(ctx context.Context, organization models.Organization) (*mongo.InsertOneResult, error)
+	GetOrganization(ctx context.Context, organizationID primitive.ObjectID) (*models.Organization, error)
+	ListUserOrganizations(ctx context.Context, userID primitive.ObjectID) ([]models.Organization, error)
+	CountOrganizationsCreatedBy(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	UpdateOrganizationName(ctx context.Context, organizationID primitive.ObjectID, name string) (*mongo.UpdateResult, error)
+	AddOrganizationMember(ctx context.Context, organizationID primitive.ObjectID, member models.OrganizationMember) (*mongo.UpdateResult, error)
+	UpdateOrganizationMember(ctx context.Context, organizationID primitive.ObjectID, member models.OrganizationMember) (*mongo.UpdateResult, error)
+	RemoveOrganizationMember(ctx context.Context, organizationID primitive.ObjectID, userID primitive.ObjectID) (*mongo.UpdateResult, error)
+	DeleteOrganization(ctx context.Context, organizationID primitive.ObjectID) (*mongo.DeleteResult, error)
+
+	// Login Throttles
+	GetLoginThrottle(ctx context.Context, kind models.LoginThrottleKind, key string) (*models.LoginThrottle, error)
+	RecordFailedLogin(ctx context.Context, kind models.LoginThrottleKind, key string, window time.Duration) (*models.LoginThrottle, error)
+	LockLogins(ctx context.Context, kind models.LoginThrottleKind, key string, until time.Time) (*mongo.UpdateResult, error)
+	ClearLoginThrottle(ctx context.Context, kind models.LoginThrottleKind, key string) (*mongo.DeleteResult, error)
+
+	// Audit Log
+	CreateAuditEntry(ctx context.Context, entry models.AuditEntry) (*mongo.InsertOneResult, error)
+	ListAuditEntries(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.AuditEntry, int64, error)
+
+	// Platform Admin
+	ListUsers(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.User, int64, error)
+	SetUserDisabled(ctx context.Context, userID primitive.ObjectID, disabled bool) (*mongo.UpdateResult, error)
+	ListEvents(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.Event, int64, error)
+	UpdateSubscription(ctx context.Context, subscriptionID primitive.ObjectID, subscription models.Subscription) (*mongo.UpdateResult, error)
+	UpdatePlanLimits(ctx context.Context, planID primitive.ObjectID, limits models.PlanLimits) (*mongo.UpdateResult, error)
+
+	// Calendar Feeds
+	SetUserCalendarFeedToken(ctx context.Context, userId primitive.ObjectID, tokenHash string) (*mongo.UpdateResult, error)
+	FindUserByCalendarFeedToken(ctx context.Context, tokenHash string) (*models.User, error)
+
+	// Event Schedule
+	CreateScheduleItem(ctx context.Context, item models.ScheduleItem) (*mongo.InsertOneResult, error)
+	GetScheduleItem(ctx context.Context, eventID primitive.ObjectID, itemID primitive.ObjectID) (*models.ScheduleItem, error)
+	ListScheduleItems(ctx context.Context, eventID primitive.ObjectID) ([]models.ScheduleItem, error)
+	ListUserScheduleItems(ctx context.Context, userID primitive.ObjectID) ([]models.ScheduleItem, error)
+	UpdateScheduleItem(ctx context.Context, item models.ScheduleItem) (*mongo.UpdateResult, error)
+	DeleteScheduleItem(ctx context.Context, eventID primitive.ObjectID, itemID primitive.ObjectID) (*mongo.DeleteResult, error)
+	SetScheduleItemRegistration(ctx context.Context, eventID primitive.ObjectID, itemID primitive.ObjectID, userID primitive.ObjectID, registered bool) (*mongo.UpdateResult, error)
+
+	// Trash
+	RestoreForm(ctx context.Context, eventID primitive.ObjectID, formID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RestoreEmailTemplate(ctx context.Context, eventID primitive.ObjectID, emailTemplateID primitive.ObjectID) (*mongo.UpdateResult, error)
+	RestorePipeline(ctx context.Context, eventID primitive.ObjectID, pipelineID primitive.ObjectID) (*mongo.UpdateResult, error)
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (models.TrashPurgeReport, error)
 }
 
 // Service implements MongoService with a mongo.Client.
@@ -112,6 +244,16 @@ func (s *Service) FindUserByEmail(ctx context.Context, email string) (*models.Us
 	return &user, nil
 }
 
+// FindUserByID finds a user by their ID, unlike GetUserDetails this includes the private fields.
+func (s *Service) FindUserByID(ctx context.Context, userId primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	err := s.Database.Collection("users").FindOne(ctx, bson.M{"_id": userId}).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // InsertUser inserts a new user into the database.
 func (s *Service) InsertUser(ctx context.Context, user models.User) (*mongo.InsertOneResult, error) {
 	// First check if email is already registered
@@ -130,6 +272,7 @@ func (s *Service) GetUserDetails(ctx context.Context, userId primitive.ObjectID)
 	}
 	user.Birthday = time.Time{}
 	user.PasswordHash = ""
+	user.TwoFactor = models.UserTwoFactor{}
 
 	return &user, nil
 }
@@ -162,7 +305,7 @@ func (s *Service) UpdateEventMetadata(ctx *gin.Context, eventID primitive.Object
 		return nil, err
 	}
 
-	if !CanUserModifyEvent(ctx, s, authenticatedUser, event.ID, &event) {
+	if !UserHasEventCapability(ctx, s, authenticatedUser, event.ID, &event, models.CapabilityEventWrite) {
 		return nil, ErrUserNotAuthorized
 	}
 
@@ -171,64 +314,165 @@ func (s *Service) UpdateEventMetadata(ctx *gin.Context, eventID primitive.Object
 	return s.Database.Collection("events").UpdateOne(ctx, bson.M{"_id": eventID}, update)
 }
 
+// SetEventStatus moves an event to another status, nothing matches if the event's current status isn't one of from
+func (s *Service) SetEventStatus(ctx context.Context, eventID primitive.ObjectID, from []models.EventStatus, to models.EventStatus) (*mongo.UpdateResult, error) {
+	conditions := []bson.M{}
+	for _, status := range from {
+		conditions = append(conditions, eventStatusFilter(status))
+	}
+	filter := bson.M{"_id": eventID, "$or": conditions}
+
+	set := bson.M{"status": to}
+	if to == models.EventStatusPublished {
+		set["publishedAt"] = time.Now()
+	}
+	return s.Database.Collection("events").UpdateOne(ctx, filter, bson.M{"$set": set})
+}
+
+// eventStatusFilter matches events with the status, events from before statuses existed count as published
+func eventStatusFilter(status models.EventStatus) bson.M {
+	if status == models.EventStatusPublished {
+		return bson.M{"status": bson.M{"$in": []interface{}{status, nil}}}
+	}
+	return bson.M{"status": status}
+}
+
 type EventMetadataWithID struct {
 	ID       primitive.ObjectID `json:"id"`
 	Metadata models.EventMetadata
 }
 
-// ListEventsMetadata retrieves events based on a filter
-func (s *Service) ListEventsMetadata(ctx context.Context, filter bson.M) ([]models.Event, error) {
+// ListEventsMetadata retrieves the events matching the options, along with the cursor of the next page.
+// The cursor is empty once there are no more events.
+func (s *Service) ListEventsMetadata(ctx context.Context, opts EventListOptions) ([]models.Event, string, error) {
 	var events []models.Event
 
-	cursor, err := s.Database.Collection("events").Find(ctx, filter)
+	if opts.Sort == "" {
+		opts.Sort = EventSortNewest
+	}
+
+	filter, err := opts.filter()
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	cursor, err := s.Database.Collection("events").Find(ctx, filter, opts.findOptions())
+	if err != nil {
+		return nil, "", err
 	}
 	defer cursor.Close(ctx)
 
 	for cursor.Next(ctx) {
 		var event models.Event
 		if err := cursor.Decode(&event); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		// We re-create the event here because we don't want to return the organizer IDs or hidden fields
+		event.Metadata.StripPrivate()
 		events = append(events, models.Event{
-			ID:       event.ID,
-			Metadata: event.Metadata,
+			ID:          event.ID,
+			Metadata:    event.Metadata,
+			Status:      event.GetStatus(),
+			PublishedAt: event.PublishedAt,
 		})
 	}
 
 	if err := cursor.Err(); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// If events is null then return an empty slice instead
 	if events == nil {
-		return []models.Event{}, nil
+		return []models.Event{}, "", nil
+	}
+
+	next := ""
+	if opts.Limit > 0 && int64(len(events)) > opts.Limit {
+		events = events[:opts.Limit]
+		if next, err = opts.nextCursor(events[len(events)-1]); err != nil {
+			return nil, "", err
+		}
 	}
 
-	return events, nil
+	return events, next, nil
 }
 
-func (s *Service) AddOrganizerToEvent(ctx context.Context, eventID primitive.ObjectID, organizerID primitive.ObjectID) (*mongo.UpdateResult, error) {
+// AddOrganizerToEvent adds an organizer with their role, nothing matches if they're already an organizer
+func (s *Service) AddOrganizerToEvent(ctx context.Context, eventID primitive.ObjectID, organizer models.EventOrganizer) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": eventID, "organizerIDs": bson.M{"$ne": organizer.UserID}}
 	update := bson.M{
-		"$addToSet": bson.M{"organizerIDs": organizerID},
+		"$addToSet": bson.M{"organizerIDs": organizer.UserID},
+		"$push":     bson.M{"organizers": organizer},
 	}
 
+	return s.Database.Collection("events").UpdateOne(ctx, filter, update)
+}
+
+// UpdateEventOrganizer replaces an existing organizer's role
+func (s *Service) UpdateEventOrganizer(ctx context.Context, eventID primitive.ObjectID, organizer models.EventOrganizer) (*mongo.UpdateResult, error) {
+	result, err := s.Database.Collection("events").UpdateOne(ctx,
+		bson.M{"_id": eventID, "organizers.userID": organizer.UserID},
+		bson.M{"$set": bson.M{"organizers.$": organizer}},
+	)
+	if err != nil || result.MatchedCount > 0 {
+		return result, err
+	}
+
+	// Organizers added before roles existed only have their ID stored
+	return s.Database.Collection("events").UpdateOne(ctx,
+		bson.M{"_id": eventID, "organizerIDs": organizer.UserID, "organizers.userID": bson.M{"$ne": organizer.UserID}},
+		bson.M{"$push": bson.M{"organizers": organizer}},
+	)
+}
+
+// UpdateEventSettings replaces the organizer-only settings of an event, start from GetEventSettings to keep the ones not being changed
+func (s *Service) UpdateEventSettings(ctx context.Context, eventID primitive.ObjectID, settings models.EventSettings) (*mongo.UpdateResult, error) {
+	update := bson.M{"$set": bson.M{"settings": settings}}
 	return s.Database.Collection("events").UpdateByID(ctx, eventID, update)
 }
 
+// GetEventSettings returns the organizer-only settings of an event, it's up to the caller to only share what applicants should see
+func (s *Service) GetEventSettings(ctx context.Context, eventID primitive.ObjectID) (*models.EventSettings, error) {
+	var event models.Event
+	opts := options.FindOne().SetProjection(bson.M{"settings": 1})
+	if err := s.Database.Collection("events").FindOne(ctx, bson.M{"_id": eventID}, opts).Decode(&event); err != nil {
+		return nil, err
+	}
+	return &event.Settings, nil
+}
+
+// GetEventVenue returns just the event's venue including its meeting URL, nil if it doesn't have one.
+// GetEvent hides the meeting URL from everyone but organizers, this is for showing it to participants.
+func (s *Service) GetEventVenue(ctx context.Context, eventID primitive.ObjectID) (*models.EventVenue, error) {
+	var event models.Event
+	opts := options.FindOne().SetProjection(bson.M{"metadata.venue": 1})
+	if err := s.Database.Collection("events").FindOne(ctx, bson.M{"_id": eventID}, opts).Decode(&event); err != nil {
+		return nil, err
+	}
+	return event.Metadata.Venue, nil
+}
+
+// FindEventByID finds an event by its ID, unlike GetEvent it doesn't check who's asking so it's for internal use only.
+func (s *Service) FindEventByID(ctx context.Context, eventID primitive.ObjectID) (*models.Event, error) {
+	var event models.Event
+	if err := s.Database.Collection("events").FindOne(ctx, bson.M{"_id": eventID}).Decode(&event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
 func (s *Service) RemoveOrganizerFromEvent(ctx context.Context, eventID primitive.ObjectID, organizerID primitive.ObjectID) (*mongo.UpdateResult, error) {
 	update := bson.M{
-		"$pull": bson.M{"organizerIDs": organizerID},
+		"$pull": bson.M{"organizerIDs": organizerID, "organizers": bson.M{"userID": organizerID}},
 	}
 
 	return s.Database.Collection("events").UpdateByID(ctx, eventID, update)
 }
 
-// DeleteEvent deletes an event by its ID
-func (s *Service) DeleteEvent(ctx *gin.Context, eventID primitive.ObjectID) (*mongo.DeleteResult, error) {
+// DeleteEvent deletes an event along with everything that belongs to it and gives the event back to the quota of the
+// subscription it was billed to. Only the owner can delete the event. Returns the deleted event.
+func (s *Service) DeleteEvent(ctx *gin.Context, eventID primitive.ObjectID) (*models.Event, error) {
 	authenticatedUser, ok := utils.GetUserFromContext(ctx, true)
 	if !ok {
 		return nil, ErrUserNotAuthenticated
@@ -241,13 +485,28 @@ func (s *Service) DeleteEvent(ctx *gin.Context, eventID primitive.ObjectID) (*mo
 		return nil, err
 	}
 
-	// Ensure the user is the creator of the event
-	if authenticatedUser.ID != event.CreatedByID {
+	// Only the owner can delete the event
+	if !IsUserEventOwner(ctx, s, authenticatedUser, &event) {
 		return nil, ErrUserNotAuthorized
 	}
 
-	// Delete the event
-	return s.Database.Collection("events").DeleteOne(ctx, bson.M{"_id": eventID})
+	// Looked up first since who pays for the event can't be found once it's gone
+	subscription, subscriptionErr := s.GetEventSubscription(ctx, eventID)
+
+	if err := s.deleteEventInTransaction(ctx, eventID); err != nil {
+		return nil, err
+	}
+
+	// The event is already gone so failing to give back the quota isn't worth failing the request over
+	if subscriptionErr != nil {
+		if subscriptionErr != ErrNoSubscription {
+			logger.Error("Failed to get the subscription of a deleted event", subscriptionErr)
+		}
+	} else if _, err := s.DecrementSubscriptionEventUtilization(ctx, subscription.ID, eventID); err != nil {
+		logger.Error("Failed to give back the quota of a deleted event", err)
+	}
+
+	return &event, nil
 }
 
 // GetEvent retrieves an event by its ID
@@ -267,20 +526,23 @@ func (s *Service) GetEvent(ctx *gin.Context, eventID primitive.ObjectID) (*model
 	}
 
 	// If the user is not an organizer then return the metadata
-	if !isAuthenticated || !CanUserModifyEvent(ctx, s, authenticatedUser, event.ID, &event) {
+	if !isAuthenticated || !UserHasEventCapability(ctx, s, authenticatedUser, event.ID, &event, models.CapabilityEventRead) {
+		event.Metadata.StripPrivate()
 		return &models.Event{
-			ID:       event.ID,
-			Metadata: event.Metadata,
+			ID:          event.ID,
+			Metadata:    event.Metadata,
+			Status:      event.GetStatus(),
+			PublishedAt: event.PublishedAt,
 		}, nil
 	}
 
 	return &event, nil
 }
 
-// GetEventForms returns a list of all of the events forms
+// ListForms returns the forms matching the filter, forms in the trash are left out unless the filter says otherwise
 func (s *Service) ListForms(ctx context.Context, filter bson.M) ([]models.FormStructure, error) {
 	var forms []models.FormStructure
-	cursor, err := s.Database.Collection("forms").Find(ctx, filter)
+	cursor, err := s.Database.Collection("forms").Find(ctx, withoutDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -333,6 +595,56 @@ func (s *Service) UpdateUser(ctx context.Context, userId primitive.ObjectID, use
 	return s.Database.Collection("users").UpdateOne(ctx, filter, update)
 }
 
+// UpdateUserPassword replaces the password hash of a user
+func (s *Service) UpdateUserPassword(ctx context.Context, userId primitive.ObjectID, passwordHash string) error {
+	update := bson.M{"$set": bson.M{"passwordHash": passwordHash}}
+	result, err := s.Database.Collection("users").UpdateOne(ctx, bson.M{"_id": userId}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("no user found with that ID")
+	}
+
+	return nil
+}
+
+// UpdateUserEmail changes the email of a user, the new email is unverified until they confirm it
+func (s *Service) UpdateUserEmail(ctx context.Context, userId primitive.ObjectID, email string) error {
+	existingUser, err := s.FindUserByEmail(ctx, email)
+	if err == nil && existingUser.ID != userId {
+		return ErrUserAlreadyExists
+	}
+
+	update := bson.M{"$set": bson.M{"email": email, "emailVerified": false}}
+	result, err := s.Database.Collection("users").UpdateOne(ctx, bson.M{"_id": userId}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("no user found with that ID")
+	}
+
+	return nil
+}
+
+// MarkUserEmailVerified marks the email of a user as verified, only if it is still the email that was verified
+func (s *Service) MarkUserEmailVerified(ctx context.Context, userId primitive.ObjectID, email string) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": userId, "email": email}
+	update := bson.M{"$set": bson.M{"emailVerified": true}}
+	return s.Database.Collection("users").UpdateOne(ctx, filter, update)
+}
+
+// MarkLegacyUsersEmailVerified marks users from before email verification existed as verified, so they keep access to
+// everything that needs a verified email. Users created since always have the field, so it's safe to run on every start.
+func (s *Service) MarkLegacyUsersEmailVerified(ctx context.Context) (*mongo.UpdateResult, error) {
+	filter := bson.M{"emailVerified": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"emailVerified": true}}
+	return s.Database.Collection("users").UpdateMany(ctx, filter, update)
+}
+
 // GetSourceByName retrieves a SelectorSource by its name
 func (s *Service) GetSourceByName(ctx context.Context, name string) (*models.SelectorSource, error) {
 	var source models.SelectorSource
@@ -395,7 +707,7 @@ func (s *Service) ListSelectorSources(ctx context.Context) ([]models.SelectorSou
 // GetForm retrieves a form by its ID
 func (s *Service) GetForm(ctx context.Context, formID primitive.ObjectID, stripSecrets bool) (*models.FormStructure, error) {
 	var form models.FormStructure
-	err := s.Database.Collection("forms").FindOne(ctx, bson.M{"_id": formID}).Decode(&form)
+	err := s.Database.Collection("forms").FindOne(ctx, withoutDeleted(bson.M{"_id": formID})).Decode(&form)
 	if err != nil {
 		return nil, err
 	}
@@ -445,11 +757,9 @@ func (s *Service) AddAllowedSubmitter(ctx context.Context, formID primitive.Obje
 	return result, nil
 }
 
-// DeleteForm deletes a form by its ID
-func (s *Service) DeleteForm(ctx context.Context, formID primitive.ObjectID) (*mongo.DeleteResult, error) {
-	filter := bson.M{"_id": formID}
-	// TODO: Delete all submissions for this form
-	return s.Database.Collection("forms").DeleteOne(ctx, filter)
+// DeleteForm moves a form to its event's trash, its responses are kept until the form is purged
+func (s *Service) DeleteForm(ctx context.Context, formID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.moveToTrash(ctx, "forms", formID)
 }
 
 // CreatePipeline creates a new pipeline
@@ -489,18 +799,18 @@ func (s *Service) UpdatePipeline(ctx context.Context, pipeline models.PipelineCo
 // GetPipeline retrieves a pipeline by its ID
 func (s *Service) GetPipeline(ctx context.Context, pipelineID primitive.ObjectID) (*models.PipelineConfiguration, error) {
 	var pipeline models.PipelineConfiguration
-	err := s.Database.Collection("pipeline_configs").FindOne(ctx, bson.M{"_id": pipelineID}).Decode(&pipeline)
+	err := s.Database.Collection("pipeline_configs").FindOne(ctx, withoutDeleted(bson.M{"_id": pipelineID})).Decode(&pipeline)
 	if err != nil {
 		return nil, err
 	}
 	return &pipeline, nil
 }
 
-// ListPipelines retrieves pipelines based on a filter
+// ListPipelines retrieves pipelines based on a filter, pipelines in the trash are left out unless the filter says otherwise
 func (s *Service) ListPipelines(ctx context.Context, filter bson.M) ([]models.PipelineConfiguration, error) {
 	var pipelines []models.PipelineConfiguration
 
-	cursor, err := s.Database.Collection("pipeline_configs").Find(ctx, filter)
+	cursor, err := s.Database.Collection("pipeline_configs").Find(ctx, withoutDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -527,10 +837,9 @@ func (s *Service) ListPipelines(ctx context.Context, filter bson.M) ([]models.Pi
 	return pipelines, nil
 }
 
-// DeletePipeline deletes a pipeline by its ID
-func (s *Service) DeletePipeline(ctx context.Context, pipelineID primitive.ObjectID) (*mongo.DeleteResult, error) {
-	filter := bson.M{"_id": pipelineID}
-	return s.Database.Collection("pipeline_configs").DeleteOne(ctx, filter)
+// DeletePipeline moves a pipeline to its event's trash, it stops running straight away
+func (s *Service) DeletePipeline(ctx context.Context, pipelineID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.moveToTrash(ctx, "pipeline_configs", pipelineID)
 }
 
 // ListResponses retrieves responses based on a filter
@@ -583,6 +892,13 @@ func (s *Service) UpdateResponse(ctx context.Context, response models.FormRespon
 	return s.Database.Collection("responses").UpdateOne(ctx, filter, update)
 }
 
+// CheckInResponse records that the applicant who submitted the response has arrived.
+// Nothing matches if the response isn't for the form or was already checked in.
+func (s *Service) CheckInResponse(ctx context.Context, formID primitive.ObjectID, responseID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": responseID, "formID": formID, "checkedInAt": bson.M{"$exists": false}}
+	return s.Database.Collection("responses").UpdateOne(ctx, filter, bson.M{"$set": bson.M{"checkedInAt": time.Now()}})
+}
+
 // DeleteResponse
 func (s *Service) DeleteResponse(ctx context.Context, responseID primitive.ObjectID) (*mongo.DeleteResult, error) {
 	filter := bson.M{"_id": responseID}
@@ -645,11 +961,11 @@ func (s *Service) ListPipelineRuns(ctx context.Context, filter bson.M, options *
 	return pipelineRuns, nil
 }
 
-// ListEmailTemplates retrieves email templates based on a filter
+// ListEmailTemplates retrieves email templates based on a filter, templates in the trash are left out unless the filter says otherwise
 func (s *Service) ListEmailTemplates(ctx context.Context, filter bson.M) ([]models.EmailTemplate, error) {
 	var emailTemplates []models.EmailTemplate
 
-	cursor, err := s.Database.Collection("email_templates").Find(ctx, filter)
+	cursor, err := s.Database.Collection("email_templates").Find(ctx, withoutDeleted(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -695,16 +1011,16 @@ func (s *Service) UpdateEmailTemplate(ctx context.Context, emailTemplate models.
 	return s.Database.Collection("email_templates").UpdateOne(ctx, filter, update)
 }
 
-// DeleteEmailTemplate deletes an email template by its ID
-func (s *Service) DeleteEmailTemplate(ctx context.Context, emailTemplateID primitive.ObjectID) (*mongo.DeleteResult, error) {
-	return s.Database.Collection("email_templates").DeleteOne(ctx, bson.M{"_id": emailTemplateID})
+// DeleteEmailTemplate moves an email template to its event's trash
+func (s *Service) DeleteEmailTemplate(ctx context.Context, emailTemplateID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.moveToTrash(ctx, "email_templates", emailTemplateID)
 }
 
 // GetEmailTemplate retrieves an email template by its ID
 func (s *Service) GetEmailTemplate(ctx context.Context, emailTemplateID primitive.ObjectID) (*models.EmailTemplate, error) {
 	var emailTemplate models.EmailTemplate
 
-	err := s.Database.Collection("email_templates").FindOne(ctx, bson.M{"_id": emailTemplateID}).Decode(&emailTemplate)
+	err := s.Database.Collection("email_templates").FindOne(ctx, withoutDeleted(bson.M{"_id": emailTemplateID})).Decode(&emailTemplate)
 	if err != nil {
 		return nil, err
 	}
@@ -964,9 +1280,11 @@ func (s *Service) DecrementSubscriptionEventUtilization(ctx context.Context, sub
 		"$inc": bson.M{"utilization.eventsCreated": -1},
 	}
 
+	// Never goes below zero, in case the event was never counted
 	filter := bson.M{
-		"_id":    subscriptionID,
-		"status": models.SubscriptionStatusActive,
+		"_id":                       subscriptionID,
+		"status":                    models.SubscriptionStatusActive,
+		"utilization.eventsCreated": bson.M{"$gt": 0},
 	}
 
 	result, err := collection.UpdateOne(ctx, filter, update)
@@ -980,3 +1298,33 @@ func (s *Service) DecrementSubscriptionEventUtilization(ctx context.Context, sub
 
 	return result, nil
 }
+
+// GetEventSubscription returns the subscription an event is billed to, its organization's if one owns it and otherwise its owner's.
+// Returns ErrNoSubscription if whoever pays for the event doesn't have one.
+func (s *Service) GetEventSubscription(ctx context.Context, eventID primitive.ObjectID) (*models.Subscription, error) {
+	// Read directly since GetEvent hides who owns the event from applicants
+	var event models.Event
+	if err := s.Database.Collection("events").FindOne(ctx, bson.M{"_id": eventID}).Decode(&event); err != nil {
+		return nil, err
+	}
+
+	var subscriptionID primitive.ObjectID
+	if !event.OrganizationID.IsZero() {
+		organization, err := s.GetOrganization(ctx, event.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+		subscriptionID = organization.CurrentSubscriptionID
+	} else {
+		owner, err := s.FindUserByID(ctx, event.CreatedByID)
+		if err != nil {
+			return nil, err
+		}
+		subscriptionID = owner.CurrentSubscriptionID
+	}
+
+	if subscriptionID.IsZero() {
+		return nil, ErrNoSubscription
+	}
+	return s.GetSubscription(ctx, subscriptionID)
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMarkUserEmailVerified(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Only verifies the email the link was sent to", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+		userID := primitive.NewObjectID()
+
+		result, err := s.MarkUserEmailVerified(context.Background(), userID, "old@example.com")
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(0), result.MatchedCount)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, userID, update.Lookup("q", "_id").ObjectID())
+		assert.Equal(mt, "old@example.com", update.Lookup("q", "email").StringValue())
+		assert.True(mt, update.Lookup("u", "$set", "emailVerified").Boolean())
+	})
+}
+
+func TestMarkLegacyUsersEmailVerified(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Only users from before verification existed", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}, bson.E{Key: "nModified", Value: 3}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		result, err := s.MarkLegacyUsersEmailVerified(context.Background())
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(3), result.ModifiedCount)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		// Users who signed up since always have the field, so an unverified one is never marked verified
+		assert.False(mt, update.Lookup("q", "emailVerified", "$exists").Boolean())
+		assert.True(mt, update.Lookup("u", "$set", "emailVerified").Boolean())
+		assert.True(mt, update.Lookup("multi").Boolean())
+	})
+}
+
+func TestCheckInResponse(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Only checks in once, on the form in the URL", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+		formID := primitive.NewObjectID()
+		responseID := primitive.NewObjectID()
+
+		result, err := s.CheckInResponse(context.Background(), formID, responseID)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(1), result.MatchedCount)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, responseID, update.Lookup("q", "_id").ObjectID())
+		assert.Equal(mt, formID, update.Lookup("q", "formID").ObjectID())
+		assert.False(mt, update.Lookup("q", "checkedInAt", "$exists").Boolean())
+		_, err = update.LookupErr("u", "$set", "checkedInAt")
+		assert.NoError(mt, err)
+		_, err = update.LookupErr("u", "$set", "data")
+		assert.Error(mt, err, "the answers are left alone")
+	})
+}
@@ -0,0 +1,101 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* SESSIONS
+*
+ */
+
+const (
+	SESSION_COLLECTION = "sessions"
+)
+
+// CreateUserSession creates a new login session
+func (s *Service) CreateUserSession(ctx context.Context, session models.UserSession) (*mongo.InsertOneResult, error) {
+	return s.Database.Collection(SESSION_COLLECTION).InsertOne(ctx, session)
+}
+
+// GetUserSession retrieves a session by its ID
+func (s *Service) GetUserSession(ctx context.Context, sessionID primitive.ObjectID) (*models.UserSession, error) {
+	var session models.UserSession
+	err := s.Database.Collection(SESSION_COLLECTION).FindOne(ctx, bson.M{"_id": sessionID}).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetUserSessionByRefreshToken retrieves the session a refresh token hash belongs to.
+// This also matches the previous refresh token of a session so that callers can detect reuse of a rotated token.
+func (s *Service) GetUserSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (*models.UserSession, error) {
+	var session models.UserSession
+	filter := bson.M{"$or": []bson.M{
+		{"refreshTokenHash": refreshTokenHash},
+		{"previousRefreshTokenHash": refreshTokenHash},
+	}}
+
+	err := s.Database.Collection(SESSION_COLLECTION).FindOne(ctx, filter).Decode(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateUserSessionRefreshToken swaps the refresh token of an active session, only if the current token is still oldRefreshTokenHash.
+// This prevents two concurrent refreshes with the same token from both succeeding.
+func (s *Service) RotateUserSessionRefreshToken(ctx context.Context, sessionID primitive.ObjectID, oldRefreshTokenHash string, newRefreshTokenHash string, expiresAt time.Time) (*mongo.UpdateResult, error) {
+	filter := bson.M{
+		"_id":              sessionID,
+		"refreshTokenHash": oldRefreshTokenHash,
+		"revokedAt":        bson.M{"$exists": false},
+		"expiresAt":        bson.M{"$gt": time.Now()},
+	}
+
+	update := bson.M{"$set": bson.M{
+		"refreshTokenHash":         newRefreshTokenHash,
+		"previousRefreshTokenHash": oldRefreshTokenHash,
+		"lastRefreshedAt":          time.Now(),
+		"expiresAt":                expiresAt,
+	}}
+
+	result, err := s.Database.Collection(SESSION_COLLECTION).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, ErrSessionNotActive
+	}
+
+	return result, nil
+}
+
+// RevokeUserSession revokes a single session, any access or refresh tokens issued for it will stop working
+func (s *Service) RevokeUserSession(ctx context.Context, sessionID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": sessionID, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now()}}
+	return s.Database.Collection(SESSION_COLLECTION).UpdateOne(ctx, filter, update)
+}
+
+// RevokeAllUserSessions revokes every active session of a user
+func (s *Service) RevokeAllUserSessions(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"userID": userID, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now()}}
+	return s.Database.Collection(SESSION_COLLECTION).UpdateMany(ctx, filter, update)
+}
+
+// RevokeOtherUserSessions revokes every active session of a user except for keepSessionID
+func (s *Service) RevokeOtherUserSessions(ctx context.Context, userID primitive.ObjectID, keepSessionID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"userID": userID, "_id": bson.M{"$ne": keepSessionID}, "revokedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revokedAt": time.Now()}}
+	return s.Database.Collection(SESSION_COLLECTION).UpdateMany(ctx, filter, update)
+}
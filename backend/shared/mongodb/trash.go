@@ -0,0 +1,116 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* TRASH
+*
+* Deleted forms, email templates and pipelines stay in their event's trash, where they can be restored, until they're purged
+ */
+
+// IncludeDeleted goes under "isDeleted" in a list filter to match items whether they're in the trash or not
+var IncludeDeleted = bson.M{"$in": bson.A{true, false, nil}}
+
+// withoutDeleted leaves items in the trash out of the filter, unless the filter already says which to match
+func withoutDeleted(filter bson.M) bson.M {
+	if _, ok := filter["isDeleted"]; ok {
+		return filter
+	}
+
+	withoutDeletedFilter := bson.M{"isDeleted": bson.M{"$ne": true}}
+	for key, value := range filter {
+		withoutDeletedFilter[key] = value
+	}
+	return withoutDeletedFilter
+}
+
+// moveToTrash marks an item as deleted, items already in the trash keep the time they were first deleted
+func (s *Service) moveToTrash(ctx context.Context, collection string, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": id, "isDeleted": bson.M{"$ne": true}}
+	update := bson.M{"$set": bson.M{"isDeleted": true, "deletedAt": time.Now()}}
+	return s.Database.Collection(collection).UpdateOne(ctx, filter, update)
+}
+
+// restoreFromTrash takes an item of the event back out of the trash
+func (s *Service) restoreFromTrash(ctx context.Context, collection string, eventID primitive.ObjectID, id primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": id, "eventID": eventID, "isDeleted": true}
+	update := bson.M{
+		"$set":   bson.M{"isDeleted": false, "lastUpdatedAt": time.Now()},
+		"$unset": bson.M{"deletedAt": ""},
+	}
+	return s.Database.Collection(collection).UpdateOne(ctx, filter, update)
+}
+
+// RestoreForm takes a form of the event out of the trash, MatchedCount is 0 if it isn't in the trash
+func (s *Service) RestoreForm(ctx context.Context, eventID primitive.ObjectID, formID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.restoreFromTrash(ctx, "forms", eventID, formID)
+}
+
+// RestoreEmailTemplate takes an email template of the event out of the trash, MatchedCount is 0 if it isn't in the trash
+func (s *Service) RestoreEmailTemplate(ctx context.Context, eventID primitive.ObjectID, emailTemplateID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.restoreFromTrash(ctx, "email_templates", eventID, emailTemplateID)
+}
+
+// RestorePipeline takes a pipeline of the event out of the trash, MatchedCount is 0 if it isn't in the trash
+func (s *Service) RestorePipeline(ctx context.Context, eventID primitive.ObjectID, pipelineID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	return s.restoreFromTrash(ctx, "pipeline_configs", eventID, pipelineID)
+}
+
+// PurgeTrash permanently deletes the forms, email templates and pipelines deleted before the cutoff along with the responses to
+// those forms and the runs of those pipelines. Dependents go first so a purge that fails part way can just be run again.
+func (s *Service) PurgeTrash(ctx context.Context, deletedBefore time.Time) (models.TrashPurgeReport, error) {
+	report := models.TrashPurgeReport{}
+	expired := bson.M{"isDeleted": true, "deletedAt": bson.M{"$lt": deletedBefore}}
+
+	formIDs, err := s.Database.Collection("forms").Distinct(ctx, "_id", expired)
+	if err != nil {
+		return report, err
+	}
+	if len(formIDs) > 0 {
+		responsesResult, err := s.Database.Collection("responses").DeleteMany(ctx, bson.M{"formID": bson.M{"$in": formIDs}})
+		if err != nil {
+			return report, err
+		}
+		report.ResponsesPurged = responsesResult.DeletedCount
+
+		formsResult, err := s.Database.Collection("forms").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": formIDs}})
+		if err != nil {
+			return report, err
+		}
+		report.FormsPurged = formsResult.DeletedCount
+	}
+
+	pipelineIDs, err := s.Database.Collection("pipeline_configs").Distinct(ctx, "_id", expired)
+	if err != nil {
+		return report, err
+	}
+	if len(pipelineIDs) > 0 {
+		runsResult, err := s.Database.Collection("pipeline_runs").DeleteMany(ctx, bson.M{"pipelineID": bson.M{"$in": pipelineIDs}})
+		if err != nil {
+			return report, err
+		}
+		report.PipelineRunsPurged = runsResult.DeletedCount
+
+		pipelinesResult, err := s.Database.Collection("pipeline_configs").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": pipelineIDs}})
+		if err != nil {
+			return report, err
+		}
+		report.PipelinesPurged = pipelinesResult.DeletedCount
+	}
+
+	templatesResult, err := s.Database.Collection("email_templates").DeleteMany(ctx, expired)
+	if err != nil {
+		return report, err
+	}
+	report.EmailTemplatesPurged = templatesResult.DeletedCount
+
+	return report, nil
+}
@@ -0,0 +1,37 @@
+package mongodb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWithoutDeleted(t *testing.T) {
+	eventID := primitive.NewObjectID()
+
+	tests := []struct {
+		name     string
+		filter   bson.M
+		expected bson.M
+	}{
+		{"Empty", bson.M{}, bson.M{"isDeleted": bson.M{"$ne": true}}},
+		{"Nil", nil, bson.M{"isDeleted": bson.M{"$ne": true}}},
+		{"Keeps the filter", bson.M{"eventID": eventID}, bson.M{"eventID": eventID, "isDeleted": bson.M{"$ne": true}}},
+		{"Only the trash", bson.M{"eventID": eventID, "isDeleted": true}, bson.M{"eventID": eventID, "isDeleted": true}},
+		{"Include deleted", bson.M{"eventID": eventID, "isDeleted": IncludeDeleted}, bson.M{"eventID": eventID, "isDeleted": IncludeDeleted}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, withoutDeleted(tt.filter))
+		})
+	}
+
+	t.Run("Doesn't change the caller's filter", func(t *testing.T) {
+		filter := bson.M{"eventID": eventID}
+		withoutDeleted(filter)
+		assert.Equal(t, bson.M{"eventID": eventID}, filter)
+	})
+}
@@ -0,0 +1,74 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+/*
+* TWO FACTOR
+*
+ */
+
+// SetUserTwoFactorPendingSecret starts 2FA enrollment, the secret isn't used for login until EnableUserTwoFactor
+func (s *Service) SetUserTwoFactorPendingSecret(ctx context.Context, userId primitive.ObjectID, secret string) error {
+	filter := bson.M{"_id": userId, "twoFactor.enabled": bson.M{"$ne": true}}
+	update := bson.M{"$set": bson.M{"twoFactor.pendingSecret": secret}}
+	result, err := s.Database.Collection("users").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("no user found with that ID or two-factor authentication is already enabled")
+	}
+
+	return nil
+}
+
+// EnableUserTwoFactor finishes enrollment, it only matches if the secret is still the user's pending secret
+func (s *Service) EnableUserTwoFactor(ctx context.Context, userId primitive.ObjectID, secret string, recoveryCodeHashes []string, usedStep int64) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": userId, "twoFactor.pendingSecret": secret}
+	update := bson.M{"$set": bson.M{"twoFactor": bson.M{
+		"enabled":            true,
+		"enabledAt":          time.Now(),
+		"secret":             secret,
+		"recoveryCodeHashes": recoveryCodeHashes,
+		"lastUsedStep":       usedStep,
+	}}}
+	return s.Database.Collection("users").UpdateOne(ctx, filter, update)
+}
+
+// DisableUserTwoFactor turns off 2FA and removes the secret and recovery codes
+func (s *Service) DisableUserTwoFactor(ctx context.Context, userId primitive.ObjectID) error {
+	update := bson.M{"$set": bson.M{"twoFactor": bson.M{"enabled": false}}}
+	_, err := s.Database.Collection("users").UpdateOne(ctx, bson.M{"_id": userId}, update)
+	return err
+}
+
+// SetUserTwoFactorRecoveryCodes replaces the user's recovery codes
+func (s *Service) SetUserTwoFactorRecoveryCodes(ctx context.Context, userId primitive.ObjectID, recoveryCodeHashes []string) error {
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true}
+	update := bson.M{"$set": bson.M{"twoFactor.recoveryCodeHashes": recoveryCodeHashes}}
+	_, err := s.Database.Collection("users").UpdateOne(ctx, filter, update)
+	return err
+}
+
+// UseUserTwoFactorStep atomically records a TOTP time step as used, nothing matches if the step was already used
+func (s *Service) UseUserTwoFactorStep(ctx context.Context, userId primitive.ObjectID, step int64) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true, "twoFactor.lastUsedStep": bson.M{"$lt": step}}
+	update := bson.M{"$set": bson.M{"twoFactor.lastUsedStep": step}}
+	return s.Database.Collection("users").UpdateOne(ctx, filter, update)
+}
+
+// ConsumeUserTwoFactorRecoveryCode atomically removes a recovery code, nothing matches if the code isn't valid
+func (s *Service) ConsumeUserTwoFactorRecoveryCode(ctx context.Context, userId primitive.ObjectID, recoveryCodeHash string) (*mongo.UpdateResult, error) {
+	filter := bson.M{"_id": userId, "twoFactor.enabled": true, "twoFactor.recoveryCodeHashes": recoveryCodeHash}
+	update := bson.M{"$pull": bson.M{"twoFactor.recoveryCodeHashes": recoveryCodeHash}}
+	return s.Database.Collection("users").UpdateOne(ctx, filter, update)
+}
@@ -10,8 +10,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// CanUserModifyPipeline checks if the user provided can modify a pipeline.
-func CanUserModifyPipeline(c *gin.Context, m MongoService, u *models.User, pipelineID primitive.ObjectID, pipelineObject *models.PipelineConfiguration) bool {
+// UserHasPipelineCapability checks if the user provided has the capability on the pipeline's event.
+func UserHasPipelineCapability(c *gin.Context, m MongoService, u *models.User, pipelineID primitive.ObjectID, pipelineObject *models.PipelineConfiguration, capability models.EventCapability) bool {
 	if u == nil {
 		return false
 	}
@@ -24,11 +24,11 @@ func CanUserModifyPipeline(c *gin.Context, m MongoService, u *models.User, pipel
 		}
 		pipeline = p
 	}
-	return CanUserModifyEvent(c, m, u, pipeline.EventID, nil)
+	return UserHasEventCapability(c, m, u, pipeline.EventID, nil, capability)
 }
 
-// CanUserModifyEmailTemplate checks if the user provided can modify an email template.
-func CanUserModifyEmailTemplate(c *gin.Context, m MongoService, u *models.User, templateID primitive.ObjectID, template *models.EmailTemplate) bool {
+// UserHasEmailTemplateCapability checks if the user provided has the capability on the email template's event.
+func UserHasEmailTemplateCapability(c *gin.Context, m MongoService, u *models.User, templateID primitive.ObjectID, template *models.EmailTemplate, capability models.EventCapability) bool {
 	if u == nil {
 		return false
 	}
@@ -41,11 +41,11 @@ func CanUserModifyEmailTemplate(c *gin.Context, m MongoService, u *models.User,
 		}
 		emailTemplate = t
 	}
-	return CanUserModifyEvent(c, m, u, emailTemplate.EventID, nil)
+	return UserHasEventCapability(c, m, u, emailTemplate.EventID, nil, capability)
 }
 
-// CanUserModifyForm checks if the user provided can modify a form.
-func CanUserModifyForm(c *gin.Context, m MongoService, u *models.User, formID primitive.ObjectID, formObject *models.FormStructure) bool {
+// UserHasFormCapability checks if the user provided has the capability on the form's event.
+func UserHasFormCapability(c *gin.Context, m MongoService, u *models.User, formID primitive.ObjectID, formObject *models.FormStructure, capability models.EventCapability) bool {
 	if u == nil {
 		return false
 	}
@@ -60,19 +60,20 @@ func CanUserModifyForm(c *gin.Context, m MongoService, u *models.User, formID pr
 		form = f
 	}
 
-	return CanUserModifyEvent(c, m, u, form.EventID, nil)
+	return UserHasEventCapability(c, m, u, form.EventID, nil, capability)
 }
 
-// CanUserModify event checks if the given user and event can manipulate an event
-// If eventObject is nil, we will retrieve a new object from mongo, otherwise we use it.
-func CanUserModifyEvent(c *gin.Context, m MongoService, u *models.User, eventID primitive.ObjectID, eventObject *models.Event) bool {
+// UserHasEventCapability checks if the given user is an organizer of the event whose role has the capability.
+// If eventObject is nil, we will retrieve a new object from mongo, otherwise we use it. It must come from FindEventByID rather than
+// GetEvent, which leaves out the organizers for users without event:read.
+func UserHasEventCapability(c *gin.Context, m MongoService, u *models.User, eventID primitive.ObjectID, eventObject *models.Event, capability models.EventCapability) bool {
 	if u == nil {
 		return false
 	}
 
 	event := eventObject
 	if event == nil {
-		e, err := m.GetEvent(c, eventID)
+		e, err := m.FindEventByID(c, eventID)
 		if err != nil {
 			return false
 		}
@@ -80,26 +81,90 @@ func CanUserModifyEvent(c *gin.Context, m MongoService, u *models.User, eventID
 		event = e
 	}
 
-	var found = false
-	for _, organizerID := range event.OrganizerIDs {
-		if organizerID == u.ID {
-			found = true
-			break
+	// API keys scoped to an event can't touch any other event
+	if apiKey, ok := utils.GetAPIKeyFromContext(c); ok && apiKey.IsEventScoped() && apiKey.EventID != event.ID {
+		return false
+	}
+
+	organizer, ok := GetEventOrganizer(c, m, u, event)
+	if !ok {
+		return false
+	}
+
+	return organizer.HasCapability(capability)
+}
+
+// IsUserEventOwner checks if the user is the owner of the event, some actions like deleting the event are only theirs
+func IsUserEventOwner(c *gin.Context, m MongoService, u *models.User, event *models.Event) bool {
+	if u == nil {
+		return false
+	}
+
+	if apiKey, ok := utils.GetAPIKeyFromContext(c); ok && apiKey.IsEventScoped() && apiKey.EventID != event.ID {
+		return false
+	}
+
+	organizer, ok := GetEventOrganizer(c, m, u, event)
+	return ok && organizer.Role == models.EventRoleOwner
+}
+
+// GetEventOrganizer returns the user's role on the event. Owners and admins of the organization that owns the event
+// are its owners too, even if they were never added as one of its organizers.
+func GetEventOrganizer(c *gin.Context, m MongoService, u *models.User, event *models.Event) (models.EventOrganizer, bool) {
+	if !event.OrganizationID.IsZero() {
+		organization, err := m.GetOrganization(c, event.OrganizationID)
+		if err == nil {
+			if member, ok := organization.GetMember(u.ID); ok && member.CanManage() {
+				return models.EventOrganizer{UserID: u.ID, Role: models.EventRoleOwner}, true
+			}
 		}
 	}
 
-	return found
+	return event.GetOrganizer(u.ID)
 }
 
-func IsUserEmailInWhitelist(c *gin.Context, whitelist []models.FormAllowedSubmitter) (bool, string) {
+// CanUserAccessFormResponses checks if the user has the capability on a form's responses.
+// On top of the capability the event can require organizers to have 2FA enabled, a message for the user is returned when access is denied.
+func CanUserAccessFormResponses(c *gin.Context, m MongoService, u *models.User, form *models.FormStructure, capability models.EventCapability) (bool, string) {
+	if u == nil {
+		return false, "You do not have permission to access this form"
+	}
+
+	event, err := m.FindEventByID(c, form.EventID)
+	if err != nil || !UserHasEventCapability(c, m, u, form.EventID, event, capability) {
+		return false, "You do not have permission to access this form"
+	}
+
+	if event.Settings.RequireOrganizerTwoFactor {
+		user, err := m.FindUserByID(c, u.ID)
+		if err != nil || !user.TwoFactor.Enabled {
+			return false, "This event requires organizers to enable two-factor authentication before accessing responses"
+		}
+	}
+
+	return true, ""
+}
+
+// IsUserEmailInWhitelist checks if the authenticated user's verified email is allowed by the whitelist.
+// The email is read from the database rather than the token so that changed or unverified emails are never trusted.
+func IsUserEmailInWhitelist(c *gin.Context, m MongoService, whitelist []models.FormAllowedSubmitter) (bool, string) {
 	authenticatedUser, ok := utils.GetUserFromContext(c, true)
 	if !ok {
 		return false, "You must be logged in to view this form"
 	}
 
+	user, err := m.FindUserByID(c, authenticatedUser.ID)
+	if err != nil {
+		return false, "You must be logged in to view this form"
+	}
+
 	hasExpired := false
 	for _, allowedSubmitter := range whitelist {
-		if allowedSubmitter.Email == authenticatedUser.Email {
+		if allowedSubmitter.Email == user.Email {
+			if !user.EmailVerified {
+				return false, "You must verify your email address before you can access this form"
+			}
+
 			if allowedSubmitter.ExpiresAt.IsZero() || allowedSubmitter.ExpiresAt.After(time.Now()) {
 				return true, ""
 			} else {
@@ -0,0 +1,112 @@
+package mongodb
+
+import (
+	"context"
+	"net/http/httptest"
+	"shared/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockPermissionService only implements the event and organization lookups the permission checks need
+type mockPermissionService struct {
+	MongoService
+	events        map[primitive.ObjectID]models.Event
+	organizations map[primitive.ObjectID]models.Organization
+}
+
+func (m *mockPermissionService) FindEventByID(ctx context.Context, eventID primitive.ObjectID) (*models.Event, error) {
+	event, ok := m.events[eventID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &event, nil
+}
+
+// GetEvent leaves out the organizers like the real one does for users without event:read
+func (m *mockPermissionService) GetEvent(c *gin.Context, eventID primitive.ObjectID) (*models.Event, error) {
+	event, ok := m.events[eventID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	event.Organizers = nil
+	event.OrganizerIDs = nil
+	return &event, nil
+}
+
+func (m *mockPermissionService) GetOrganization(ctx context.Context, organizationID primitive.ObjectID) (*models.Organization, error) {
+	organization, ok := m.organizations[organizationID]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &organization, nil
+}
+
+func TestUserHasEventCapability(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owner := &models.User{ID: primitive.NewObjectID()}
+	secretsWriter := &models.User{ID: primitive.NewObjectID()}
+	viewer := &models.User{ID: primitive.NewObjectID()}
+	orgAdmin := &models.User{ID: primitive.NewObjectID()}
+	stranger := &models.User{ID: primitive.NewObjectID()}
+
+	organizationID := primitive.NewObjectID()
+	event := models.Event{
+		ID:             primitive.NewObjectID(),
+		CreatedByID:    owner.ID,
+		OrganizationID: organizationID,
+		OrganizerIDs:   []primitive.ObjectID{owner.ID, secretsWriter.ID, viewer.ID},
+		Organizers: []models.EventOrganizer{
+			{UserID: owner.ID, Role: models.EventRoleOwner},
+			// A custom role without event:read, so GetEvent wouldn't return the organizers to them
+			{UserID: secretsWriter.ID, Role: models.EventRoleCustom, Capabilities: []models.EventCapability{models.CapabilitySecretsWrite}},
+			{UserID: viewer.ID, Role: models.EventRoleViewer},
+		},
+	}
+	m := &mockPermissionService{
+		events: map[primitive.ObjectID]models.Event{event.ID: event},
+		organizations: map[primitive.ObjectID]models.Organization{organizationID: {
+			ID:      organizationID,
+			Members: []models.OrganizationMember{{UserID: orgAdmin.ID, Role: models.OrganizationRoleAdmin}},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		user       *models.User
+		capability models.EventCapability
+		expected   bool
+	}{
+		{"Owner can do everything", owner, models.CapabilityOrganizersWrite, true},
+		{"Custom role without event:read has its capability", secretsWriter, models.CapabilitySecretsWrite, true},
+		{"Custom role without event:read can't read the event", secretsWriter, models.CapabilityEventRead, false},
+		{"Viewer can read", viewer, models.CapabilityResponsesRead, true},
+		{"Viewer can't write", viewer, models.CapabilityResponsesWrite, false},
+		{"Organization admin owns the event", orgAdmin, models.CapabilitySecretsWrite, true},
+		{"Non organizer", stranger, models.CapabilityEventRead, false},
+		{"No user", nil, models.CapabilityEventRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			assert.Equal(t, tt.expected, UserHasEventCapability(c, m, tt.user, event.ID, nil, tt.capability))
+		})
+	}
+
+	t.Run("Unknown event", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		assert.False(t, UserHasEventCapability(c, m, owner, primitive.NewObjectID(), nil, models.CapabilityEventRead))
+	})
+
+	t.Run("API key scoped to another event", func(t *testing.T) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("apiKey", &models.APIKey{EventID: primitive.NewObjectID()})
+		assert.False(t, UserHasEventCapability(c, m, owner, event.ID, nil, models.CapabilityEventRead))
+	})
+}
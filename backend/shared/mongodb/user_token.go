@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+/*
+* USER TOKENS
+*
+ */
+
+const (
+	USER_TOKEN_COLLECTION = "user_tokens"
+)
+
+// CreateUserToken stores a new single-use token, any unused tokens of the same type for the user are invalidated
+func (s *Service) CreateUserToken(ctx context.Context, token models.UserToken) (*mongo.InsertOneResult, error) {
+	_, err := s.Database.Collection(USER_TOKEN_COLLECTION).UpdateMany(ctx,
+		bson.M{"userID": token.UserID, "type": token.Type, "usedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"expiresAt": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Database.Collection(USER_TOKEN_COLLECTION).InsertOne(ctx, token)
+}
+
+// ConsumeUserToken atomically marks an unused, unexpired token as used and returns it.
+// Returns mongo.ErrNoDocuments if the token doesn't exist, was already used, or has expired.
+func (s *Service) ConsumeUserToken(ctx context.Context, tokenHash string, tokenType models.UserTokenType) (*models.UserToken, error) {
+	filter := bson.M{
+		"tokenHash": tokenHash,
+		"type":      tokenType,
+		"usedAt":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+	update := bson.M{"$set": bson.M{"usedAt": time.Now()}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token models.UserToken
+	err := s.Database.Collection(USER_TOKEN_COLLECTION).FindOneAndUpdate(ctx, filter, update, opts).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// InvalidateUserTokens expires every unused token of a type for a user
+func (s *Service) InvalidateUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType) (*mongo.UpdateResult, error) {
+	filter := bson.M{"userID": userID, "type": tokenType, "usedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"expiresAt": time.Now()}}
+	return s.Database.Collection(USER_TOKEN_COLLECTION).UpdateMany(ctx, filter, update)
+}
+
+// InvalidateAllUserTokens expires every unused token of any type for a user
+func (s *Service) InvalidateAllUserTokens(ctx context.Context, userID primitive.ObjectID) (*mongo.UpdateResult, error) {
+	filter := bson.M{"userID": userID, "usedAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"expiresAt": time.Now()}}
+	return s.Database.Collection(USER_TOKEN_COLLECTION).UpdateMany(ctx, filter, update)
+}
+
+// GetUserToken returns an unused, unexpired token without consuming it, for flows that allow a few attempts.
+// Returns mongo.ErrNoDocuments if the token doesn't exist, was already used, or has expired.
+func (s *Service) GetUserToken(ctx context.Context, tokenHash string, tokenType models.UserTokenType) (*models.UserToken, error) {
+	filter := bson.M{
+		"tokenHash": tokenHash,
+		"type":      tokenType,
+		"usedAt":    bson.M{"$exists": false},
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}
+
+	var token models.UserToken
+	err := s.Database.Collection(USER_TOKEN_COLLECTION).FindOne(ctx, filter).Decode(&token)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// CountRecentUserTokens counts the tokens of a type created for a user since the given time, used or not
+func (s *Service) CountRecentUserTokens(ctx context.Context, userID primitive.ObjectID, tokenType models.UserTokenType, since time.Time) (int64, error) {
+	filter := bson.M{"userID": userID, "type": tokenType, "createdAt": bson.M{"$gt": since}}
+	return s.Database.Collection(USER_TOKEN_COLLECTION).CountDocuments(ctx, filter)
+}
+
+// RecordFailedUserTokenAttempt counts a failed attempt against a token, the token is expired once maxAttempts is reached
+func (s *Service) RecordFailedUserTokenAttempt(ctx context.Context, tokenID primitive.ObjectID, maxAttempts int) error {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var token models.UserToken
+	err := s.Database.Collection(USER_TOKEN_COLLECTION).FindOneAndUpdate(ctx, bson.M{"_id": tokenID}, bson.M{"$inc": bson.M{"attempts": 1}}, opts).Decode(&token)
+	if err != nil {
+		return err
+	}
+
+	if token.Attempts >= maxAttempts {
+		_, err = s.Database.Collection(USER_TOKEN_COLLECTION).UpdateByID(ctx, tokenID, bson.M{"$set": bson.M{"expiresAt": time.Now()}})
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package mongodb
+
+import (
+	"context"
+	"shared/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestConsumeUserToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Only matches an unused, unexpired token", func(mt *mtest.T) {
+		userID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{
+			{Key: "_id", Value: primitive.NewObjectID()},
+			{Key: "userID", Value: userID},
+			{Key: "type", Value: models.UserTokenPasswordReset},
+		}}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		before := time.Now()
+		token, err := s.ConsumeUserToken(context.Background(), "hash", models.UserTokenPasswordReset)
+		assert.NoError(mt, err)
+		assert.Equal(mt, userID, token.UserID)
+
+		command := mt.GetStartedEvent().Command
+		query := command.Lookup("query").Document()
+		assert.Equal(mt, "hash", query.Lookup("tokenHash").StringValue())
+		assert.Equal(mt, string(models.UserTokenPasswordReset), query.Lookup("type").StringValue())
+		assert.False(mt, query.Lookup("usedAt", "$exists").Boolean(), "a used token can't be used again")
+		assert.False(mt, query.Lookup("expiresAt", "$gt").Time().Before(before.Truncate(time.Millisecond)), "an expired token can't be used")
+
+		_, err = command.LookupErr("update", "$set", "usedAt")
+		assert.NoError(mt, err, "the token is marked used in the same write that finds it")
+	})
+
+	mt.Run("No token matched", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		s := &Service{Client: mt.Client, Database: mt.DB}
+
+		_, err := s.ConsumeUserToken(context.Background(), "hash", models.UserTokenPasswordReset)
+		assert.Equal(mt, mongo.ErrNoDocuments, err)
+	})
+}
+
+func TestCreateUserToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Expires the user's other unused tokens first", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			mtest.CreateSuccessResponse(),
+		)
+		s := &Service{Client: mt.Client, Database: mt.DB}
+		userID := primitive.NewObjectID()
+
+		_, err := s.CreateUserToken(context.Background(), models.UserToken{UserID: userID, Type: models.UserTokenPasswordReset, TokenHash: "hash"})
+		assert.NoError(mt, err)
+		assert.Equal(mt, [][2]string{{"update", USER_TOKEN_COLLECTION}, {"insert", USER_TOKEN_COLLECTION}}, startedCommands(mt))
+
+		update := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(mt, userID, update.Lookup("q", "userID").ObjectID())
+		assert.Equal(mt, string(models.UserTokenPasswordReset), update.Lookup("q", "type").StringValue())
+		assert.True(mt, update.Lookup("multi").Boolean())
+	})
+}
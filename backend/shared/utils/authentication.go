@@ -2,6 +2,9 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log"
 	"net/http"
@@ -15,7 +18,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var jwtSecret []byte
+var accessTokenTTL time.Duration
 
 func init() {
 	apiConfig, err := config.GetAPIConfig()
@@ -23,81 +26,140 @@ func init() {
 		log.Fatalf("Error getting API config: %v", err)
 	}
 
-	if apiConfig.JWT_SECRET_TOKEN == "" || apiConfig.JWT_SECRET_TOKEN == "secret_please_change" {
-		log.Println("[WARNING] JWT_SECRET_TOKEN is not set or is set to a default value. Generating a random secret key, this will cause all existing JWT tokens to be invalid and will not work on lambda or multi-instance deployments.")
-		jwtSecret = generateRandomSecret(32)
-	} else {
-		jwtSecret = []byte(apiConfig.JWT_SECRET_TOKEN)
-	}
+	accessTokenTTL = apiConfig.ACCESS_TOKEN_TTL
 }
 
-// GenerateJWT generates a JWT token for the given user
-func GenerateJWT(user *models.User) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+// GenerateJWT generates a short-lived access token for the given user, tied to the login session it was issued for
+func GenerateJWT(user *models.User, sessionID primitive.ObjectID) (string, error) {
+	ring, err := getKeyRing()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(ring.active.Method, jwt.MapClaims{
 		"id":        user.ID.Hex(),
+		"sid":       sessionID.Hex(),
 		"email":     user.Email,
 		"firstName": user.FirstName,
 		"lastName":  user.LastName,
-		"exp":       time.Now().Add(time.Hour * 72).Unix(),
+		"iat":       now.Unix(),
+		"exp":       now.Add(accessTokenTTL).Unix(),
 	})
+	token.Header["kid"] = ring.active.ID
 
-	return token.SignedString(jwtSecret)
+	return token.SignedString(ring.active.Signer)
 }
 
 // VerifyJWT validates a JWT token and returns the user information if it's valid
 func VerifyJWT(tokenString string) (*models.User, error) {
+	user, _, err := VerifyJWTWithSession(tokenString)
+	return user, err
+}
+
+// VerifyJWTWithSession validates a JWT token and returns the user information and the ID of the session the token was issued for.
+// The session ID is the zero value for tokens that were not issued for a session, callers should treat those as invalid.
+func VerifyJWTWithSession(tokenString string) (*models.User, primitive.ObjectID, error) {
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
+		ring, err := getKeyRing()
+		if err != nil {
+			return nil, err
 		}
-		return jwtSecret, nil
-	})
+
+		// Any key in the ring is accepted so tokens signed before a rotation stay valid until they expire
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ring.keys[kid]
+		if !ok || key.Method.Alg() != token.Method.Alg() {
+			return nil, errors.New("unknown signing key")
+		}
+		return key.Signer.Public(), nil
+	}, jwt.WithValidMethods(jwtSigningMethods), jwt.WithExpirationRequired())
 
 	if err != nil {
-		return nil, err
+		return nil, primitive.NilObjectID, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, err := hexToObjectID(claims["id"].(string))
+		userIDStr, _ := claims["id"].(string)
+		userID, err := hexToObjectID(userIDStr)
 		if err != nil {
-			return nil, err
+			return nil, primitive.NilObjectID, err
 		}
+
+		sessionID := primitive.NilObjectID
+		if sessionIDStr, ok := claims["sid"].(string); ok {
+			sessionID, err = hexToObjectID(sessionIDStr)
+			if err != nil {
+				return nil, primitive.NilObjectID, err
+			}
+		}
+
+		email, _ := claims["email"].(string)
+		firstName, _ := claims["firstName"].(string)
+		lastName, _ := claims["lastName"].(string)
 		return &models.User{
 			ID:        userID,
-			Email:     claims["email"].(string),
-			FirstName: claims["firstName"].(string),
-			LastName:  claims["lastName"].(string),
-		}, nil
+			Email:     email,
+			FirstName: firstName,
+			LastName:  lastName,
+		}, sessionID, nil
 	} else {
-		return nil, errors.New("invalid token")
+		return nil, primitive.NilObjectID, errors.New("invalid token")
+	}
+}
+
+// GetSessionIDFromContext retrieves the ID of the login session the request was authenticated with
+func GetSessionIDFromContext(c *gin.Context) (primitive.ObjectID, bool) {
+	sessionID, exists := c.Get("sessionID")
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+
+	id, ok := sessionID.(primitive.ObjectID)
+	if !ok || id.IsZero() {
+		return primitive.NilObjectID, false
+	}
+
+	return id, true
+}
+
+// GetAPIKeyFromContext retrieves the API key the request was authenticated with, if it wasn't authenticated with a JWT
+func GetAPIKeyFromContext(c *gin.Context) (*models.APIKey, bool) {
+	apiKey, exists := c.Get("apiKey")
+	if !exists {
+		return nil, false
 	}
+
+	key, ok := apiKey.(*models.APIKey)
+	return key, ok && key != nil
 }
 
-// GetUserFromContext retrieves the authenticated user from the Gin context
+// GetImpersonatorIDFromContext retrieves the platform admin impersonating the user, if the request was made with an impersonated session
+func GetImpersonatorIDFromContext(c *gin.Context) (primitive.ObjectID, bool) {
+	impersonatedByID, exists := c.Get("impersonatedByID")
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+
+	id, ok := impersonatedByID.(primitive.ObjectID)
+	if !ok || id.IsZero() {
+		return primitive.NilObjectID, false
+	}
+
+	return id, true
+}
+
+// GetUserFromContext retrieves the authenticated user from the Gin context.
+// The user is set by the authentication middlewares for both JWTs and API keys, which also check the token's session hasn't been revoked.
+// Routes that are optionally authenticated need OptionalAuthMiddleware, the Authorization header is never read here.
 func GetUserFromContext(c *gin.Context, writeResponse bool) (*models.User, bool) {
-	badTokenString := "Invalid or expired token"
-	var user interface{}
 	user, exists := c.Get("user")
 	if !exists {
-		// Lets also try to pull from the Authorization header, this condition hits for when a function is optionally authenticated
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			if writeResponse {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": badTokenString})
-			}
-			return nil, false
-		}
-
-		// Verify the JWT token
-		var err error
-		user, err = VerifyJWT(authHeader)
-		if err != nil {
-			if writeResponse {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": badTokenString})
-			}
-			return nil, false
+		if writeResponse {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 		}
+		return nil, false
 	}
 
 	authenticatedUser, ok := user.(*models.User)
@@ -118,14 +180,37 @@ func GetUserFromContext(c *gin.Context, writeResponse bool) (*models.User, bool)
 	return authenticatedUser, true
 }
 
-// generateRandomSecret generates a random secret key of the given length
-func generateRandomSecret(length int) []byte {
-	b := make([]byte, length)
-	_, err := rand.Read(b)
+// GenerateSecureToken generates a random URL safe token with the given number of bytes of entropy.
+// Use this for any opaque token we hand out (refresh tokens, reset links, etc.) and only store the HashToken of it.
+func GenerateSecureToken(numBytes int) (string, error) {
+	b := make([]byte, numBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// APIKeyPrefix is the start of every personal API key, it's how we tell them apart from JWTs in the Authorization header
+const APIKeyPrefix = "aa_"
+
+// GenerateAPIKey generates a new personal API key
+func GenerateAPIKey() (string, error) {
+	token, err := GenerateSecureToken(32)
 	if err != nil {
-		log.Fatalf("Failed to generate random JWT secret: %v", err)
+		return "", err
 	}
-	return b
+	return APIKeyPrefix + token, nil
+}
+
+// IsAPIKey returns true if the token (with or without the Bearer prefix) is an API key rather than a JWT
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(token, "Bearer "), APIKeyPrefix)
+}
+
+// HashToken hashes an opaque token so that it can be stored and looked up without storing the token itself
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
 // hexToObjectID converts a hex string to a primitive.ObjectID
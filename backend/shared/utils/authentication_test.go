@@ -1,6 +1,10 @@
 package utils
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
 	"shared/models"
 	"testing"
 	"time"
@@ -18,7 +22,7 @@ func TestGenerateJWT(t *testing.T) {
 		LastName:  "Doe",
 	}
 
-	token, err := GenerateJWT(&user)
+	token, err := GenerateJWT(&user, primitive.NewObjectID())
 	assert.Nil(t, err)
 	assert.NotEmpty(t, token)
 }
@@ -31,7 +35,7 @@ func TestVerifyJWT(t *testing.T) {
 	}
 
 	// Generate a valid token
-	validToken, _ := GenerateJWT(&user)
+	validToken, _ := GenerateJWT(&user, primitive.NewObjectID())
 
 	// Generate an expired token
 	expiredToken := generateExpiredJWT(user)
@@ -47,6 +51,7 @@ func TestVerifyJWT(t *testing.T) {
 		{"Valid Token", validToken, true},
 		{"Expired Token", expiredToken, false},
 		{"Invalid Signature", invalidSigToken, false},
+		{"HMAC Signed", generateHMACToken(user), false},
 		{"Malformed Token", "malformed.token.string", false},
 	}
 
@@ -63,28 +68,43 @@ func TestVerifyJWT(t *testing.T) {
 }
 
 func generateExpiredJWT(user models.User) string {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	ring, _ := getKeyRing()
+	token := jwt.NewWithClaims(ring.active.Method, jwt.MapClaims{
 		"email":     user.Email,
 		"firstName": user.FirstName,
 		"lastName":  user.LastName,
 		"exp":       time.Now().Add(-72 * time.Hour).Unix(), // Set expiration to 72 hours in the past
 	})
+	token.Header["kid"] = ring.active.ID
 
-	tokenString, _ := token.SignedString(jwtSecret)
+	tokenString, _ := token.SignedString(ring.active.Signer)
 	return tokenString
 }
 
 func generateTokenWithInvalidSignature(user models.User) string {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	ring, _ := getKeyRing()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
 		"email":     user.Email,
 		"firstName": user.FirstName,
 		"lastName":  user.LastName,
 		"exp":       time.Now().Add(72 * time.Hour).Unix(),
 	})
+	token.Header["kid"] = ring.active.ID
+
+	// Use a different key to sign the token, claiming to be the active key
+	_, invalidKey, _ := ed25519.GenerateKey(rand.Reader)
+	tokenString, _ := token.SignedString(invalidKey)
+	return tokenString
+}
+
+func generateHMACToken(user models.User) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":    user.ID.Hex(),
+		"email": user.Email,
+		"exp":   time.Now().Add(72 * time.Hour).Unix(),
+	})
 
-	// Use a different secret to sign the token
-	invalidSecret := []byte("invalid_secret")
-	tokenString, _ := token.SignedString(invalidSecret)
+	tokenString, _ := token.SignedString([]byte("secret_please_change"))
 	return tokenString
 }
 
@@ -112,7 +132,49 @@ func TestGetUserFromContext(t *testing.T) {
 	assert.Equal(t, user.Email, retrievedUser.Email)
 }
 
-func TestGenerateRandomSecret(t *testing.T) {
-	secret := generateRandomSecret(32)
-	assert.Len(t, secret, 32)
+func TestGetUserFromContextIgnoresAuthorizationHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	// A valid JWT isn't enough on its own, its session has to be checked by a middleware first
+	token, err := GenerateJWT(&models.User{ID: primitive.NewObjectID(), Email: "test@example.com"}, primitive.NewObjectID())
+	assert.Nil(t, err)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+token)
+
+	user, exists := GetUserFromContext(c, false)
+	assert.False(t, exists)
+	assert.Nil(t, user)
+}
+
+func TestVerifyJWTWithSession(t *testing.T) {
+	user := models.User{
+		ID:        primitive.NewObjectID(),
+		Email:     "test@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+	}
+	sessionID := primitive.NewObjectID()
+
+	token, err := GenerateJWT(&user, sessionID)
+	assert.Nil(t, err)
+
+	verifiedUser, verifiedSessionID, err := VerifyJWTWithSession("Bearer " + token)
+	assert.Nil(t, err)
+	assert.Equal(t, user.ID, verifiedUser.ID)
+	assert.Equal(t, sessionID, verifiedSessionID)
+}
+
+func TestGenerateSecureToken(t *testing.T) {
+	token, err := GenerateSecureToken(32)
+	assert.Nil(t, err)
+
+	otherToken, err := GenerateSecureToken(32)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token, otherToken)
+
+	// The same token should always hash the same, and different tokens should not collide
+	assert.Equal(t, HashToken(token), HashToken(token))
+	assert.NotEqual(t, HashToken(token), HashToken(otherToken))
+	assert.NotContains(t, HashToken(token), token)
 }
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/smtp"
+	"shared/config"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrEmailNotConfigured is returned when trying to send a platform email without SMTP settings
+var ErrEmailNotConfigured = errors.New("platform SMTP settings are not configured")
+
+// EmailClient is a client for sending account emails from the platform itself
+type EmailClient struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+var emailClient *EmailClient
+
+func init() {
+	apiConfig, err := config.GetAPIConfig()
+	if err != nil {
+		log.Fatalf("Error getting API config: %v", err)
+	}
+	emailClient = &EmailClient{
+		Host:     apiConfig.SMTP_HOST,
+		Port:     apiConfig.SMTP_PORT,
+		Username: apiConfig.SMTP_USERNAME,
+		Password: apiConfig.SMTP_PASSWORD,
+		From:     apiConfig.SMTP_FROM,
+	}
+}
+
+// SendPlatformEmail sends a plain text email to a single recipient through the platform SMTP server
+func SendPlatformEmail(to string, subject string, body string) error {
+	if emailClient == nil || emailClient.Host == "" || emailClient.From == "" {
+		return ErrEmailNotConfigured
+	}
+
+	// Guard against header injection, these values can come from user input
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return errors.New("invalid email header value")
+	}
+
+	headers := "From: " + emailClient.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Date: " + time.Now().Format(time.RFC1123Z) + "\r\n" +
+		fmt.Sprintf("Message-ID: <%s@%s>\r\n", uuid.NewString(), emailClient.Host) +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=\"UTF-8\"\r\n"
+
+	message := []byte(headers + "\r\n" + body)
+
+	var auth smtp.Auth
+	if emailClient.Username != "" {
+		auth = smtp.PlainAuth("", emailClient.Username, emailClient.Password, emailClient.Host)
+	}
+
+	address := fmt.Sprintf("%s:%d", emailClient.Host, emailClient.Port)
+	if err := smtp.SendMail(address, auth, emailClient.From, []string{to}, message); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// WebsiteURL builds an absolute link to a page on the website
+func WebsiteURL(path string) string {
+	apiConfig, err := config.GetAPIConfig()
+	if err != nil {
+		return path
+	}
+	return strings.TrimSuffix(apiConfig.WEBSITE_URL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
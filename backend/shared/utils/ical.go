@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"fmt"
+	"shared/models"
+	"strings"
+	"time"
+)
+
+// CalendarEvent is a single VEVENT of an iCalendar file
+type CalendarEvent struct {
+	UID          string // Stays the same across updates so calendar apps replace the event rather than adding it again
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          time.Time // Optional
+	Organizer    string    // Email address, optional
+	Cancelled    bool
+	LastModified time.Time
+	Coordinates  *models.Coordinates
+}
+
+// Calendar is an iCalendar (RFC 5545) file, times are always written in UTC so no VTIMEZONE is needed
+type Calendar struct {
+	Name     string
+	Timezone string // IANA timezone calendar apps show the events in, optional
+	Events   []CalendarEvent
+}
+
+// CalendarContentType is the Content-Type of an encoded Calendar
+const CalendarContentType = "text/calendar; charset=utf-8"
+
+// icalTimeFormat is the UTC DATE-TIME format of RFC 5545
+const icalTimeFormat = "20060102T150405Z"
+
+// icalEscaper escapes TEXT values, RFC 5545 section 3.3.11
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// Encode writes the calendar as an iCalendar file, now is used as the DTSTAMP of every event
+func (cal Calendar) Encode(now time.Time) []byte {
+	var b strings.Builder
+	line := func(name string, value string) {
+		writeICalLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//ApplicantAtlas//ApplicantAtlas//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", icalEscaper.Replace(cal.Name))
+	}
+	if cal.Timezone != "" {
+		line("X-WR-TIMEZONE", cal.Timezone)
+	}
+
+	for _, event := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", event.UID)
+		line("DTSTAMP", now.UTC().Format(icalTimeFormat))
+		line("DTSTART", event.Start.UTC().Format(icalTimeFormat))
+		if !event.End.IsZero() {
+			line("DTEND", event.End.UTC().Format(icalTimeFormat))
+		}
+		line("SUMMARY", icalEscaper.Replace(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", icalEscaper.Replace(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", icalEscaper.Replace(event.Location))
+		}
+		if event.Coordinates != nil {
+			line("GEO", fmt.Sprintf("%f;%f", event.Coordinates.Latitude, event.Coordinates.Longitude))
+		}
+		if event.URL != "" {
+			line("URL", event.URL)
+		}
+		if event.Organizer != "" {
+			line("ORGANIZER", "mailto:"+event.Organizer)
+		}
+		if event.Cancelled {
+			line("STATUS", "CANCELLED")
+		} else {
+			line("STATUS", "CONFIRMED")
+		}
+		if !event.LastModified.IsZero() {
+			line("LAST-MODIFIED", event.LastModified.UTC().Format(icalTimeFormat))
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+// writeICalLine writes a content line, folding it so no line is longer than 75 octets without splitting a character
+func writeICalLine(b *strings.Builder, content string) {
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		// Don't cut in the middle of a multi-byte UTF-8 character
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74 // The leading space of a folded line counts towards its length
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
+
+// NewEventCalendarEvent returns the calendar entry of an event, or false if it doesn't have a start time yet.
+// The meeting URL is never included since calendar files get forwarded.
+func NewEventCalendarEvent(event *models.Event, url string) (CalendarEvent, bool) {
+	if event.Metadata.StartTime.IsZero() {
+		return CalendarEvent{}, false
+	}
+
+	calendarEvent := CalendarEvent{
+		UID:          event.ID.Hex() + "@applicantatlas",
+		Summary:      event.Metadata.Name,
+		Description:  event.Metadata.Description,
+		URL:          url,
+		Start:        event.Metadata.StartTime,
+		End:          event.Metadata.EndTime,
+		Organizer:    event.Metadata.ContactEmail,
+		Cancelled:    event.GetStatus() == models.EventStatusCancelled,
+		LastModified: event.Metadata.LastUpdatedAt,
+	}
+	if calendarEvent.URL == "" {
+		calendarEvent.URL = event.Metadata.Website
+	}
+
+	if venue := event.Metadata.Venue; venue != nil {
+		calendarEvent.Location = VenueLocation(venue)
+		calendarEvent.Coordinates = venue.Coordinates
+	}
+
+	return calendarEvent, true
+}
+
+// NewScheduleItemCalendarEvent returns the calendar entry of a session of the event's schedule
+func NewScheduleItemCalendarEvent(item *models.ScheduleItem, event *models.Event) CalendarEvent {
+	location := []string{}
+	if item.Location != "" {
+		location = append(location, item.Location)
+	}
+	if event.Metadata.Venue != nil {
+		if venueLocation := VenueLocation(event.Metadata.Venue); venueLocation != "" {
+			location = append(location, venueLocation)
+		}
+	}
+
+	description := item.Description
+	if item.Speaker != "" {
+		description = strings.TrimSpace("Speaker: " + item.Speaker + "\n\n" + description)
+	}
+
+	return CalendarEvent{
+		UID:          item.ID.Hex() + "@applicantatlas",
+		Summary:      event.Metadata.Name + ": " + item.Title,
+		Description:  description,
+		Location:     strings.Join(location, ", "),
+		Start:        item.StartTime,
+		End:          item.EndTime,
+		Cancelled:    event.GetStatus() == models.EventStatusCancelled,
+		LastModified: item.LastUpdatedAt,
+	}
+}
+
+// VenueLocation describes where a venue is in a single line, e.g. "Main Hall, 1 Campus Rd, Springfield, USA"
+func VenueLocation(venue *models.EventVenue) string {
+	parts := []string{}
+	if venue.Name != "" {
+		parts = append(parts, venue.Name)
+	}
+	if venue.Address != nil {
+		for _, part := range []string{venue.Address.StreetAddress, venue.Address.City, venue.Address.Region, venue.Address.ZipCode, venue.Address.Country} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+	}
+	if len(parts) == 0 && venue.Type == models.EventLocationOnline {
+		return "Online"
+	}
+	return strings.Join(parts, ", ")
+}
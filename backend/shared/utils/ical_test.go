@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"shared/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalendarEncode(t *testing.T) {
+	start := time.Date(2026, 3, 14, 9, 0, 0, 0, time.FixedZone("EST", -5*60*60))
+	cal := Calendar{
+		Name:     "Hack, the Planet",
+		Timezone: "America/New_York",
+		Events: []CalendarEvent{{
+			UID:         "abc@applicantatlas",
+			Summary:     "Opening; ceremony",
+			Description: "Line one\nLine two",
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Cancelled:   true,
+		}},
+	}
+
+	encoded := string(cal.Encode(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, strings.HasPrefix(encoded, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(encoded, "END:VCALENDAR\r\n"))
+	assert.Contains(t, encoded, "X-WR-CALNAME:Hack\\, the Planet\r\n")
+	assert.Contains(t, encoded, "DTSTAMP:20260101T000000Z\r\n")
+	assert.Contains(t, encoded, "DTSTART:20260314T140000Z\r\n")
+	assert.Contains(t, encoded, "DTEND:20260314T150000Z\r\n")
+	assert.Contains(t, encoded, "SUMMARY:Opening\\; ceremony\r\n")
+	assert.Contains(t, encoded, "DESCRIPTION:Line one\\nLine two\r\n")
+	assert.Contains(t, encoded, "STATUS:CANCELLED\r\n")
+}
+
+func TestCalendarEncodeFoldsLongLines(t *testing.T) {
+	cal := Calendar{Events: []CalendarEvent{{
+		UID:         "abc@applicantatlas",
+		Summary:     "Event",
+		Description: strings.Repeat("é", 100),
+		Start:       time.Now(),
+	}}}
+
+	for _, line := range strings.Split(string(cal.Encode(time.Now())), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	unfolded := strings.ReplaceAll(string(cal.Encode(time.Now())), "\r\n ", "")
+	assert.Contains(t, unfolded, "DESCRIPTION:"+strings.Repeat("é", 100)+"\r\n")
+}
+
+func TestNewEventCalendarEvent(t *testing.T) {
+	event := &models.Event{ID: primitive.NewObjectID(), Metadata: models.EventMetadata{Name: "Hackathon"}}
+	_, ok := NewEventCalendarEvent(event, "")
+	assert.False(t, ok)
+
+	event.Metadata.StartTime = time.Now()
+	event.Metadata.Website = "https://example.com"
+	event.Metadata.Venue = &models.EventVenue{
+		Type:       models.EventLocationHybrid,
+		Name:       "Main Hall",
+		Address:    &models.Address{City: "Springfield", Country: "USA"},
+		MeetingURL: "https://meet.example.com/secret",
+	}
+	calendarEvent, ok := NewEventCalendarEvent(event, "")
+	assert.True(t, ok)
+	assert.Equal(t, event.ID.Hex()+"@applicantatlas", calendarEvent.UID)
+	assert.Equal(t, "https://example.com", calendarEvent.URL)
+	assert.Equal(t, "Main Hall, Springfield, USA", calendarEvent.Location)
+	assert.NotContains(t, string(Calendar{Events: []CalendarEvent{calendarEvent}}.Encode(time.Now())), "meet.example.com")
+}
+
+func TestNewScheduleItemCalendarEvent(t *testing.T) {
+	start := time.Date(2026, 3, 14, 14, 0, 0, 0, time.UTC)
+	event := &models.Event{
+		Status: models.EventStatusCancelled,
+		Metadata: models.EventMetadata{
+			Name:  "Hackathon",
+			Venue: &models.EventVenue{Type: models.EventLocationInPerson, Name: "Main Hall"},
+		},
+	}
+	item := &models.ScheduleItem{
+		ID:          primitive.NewObjectID(),
+		Title:       "Keynote",
+		Description: "Opening talk",
+		Location:    "Room 101",
+		Speaker:     "Ada Lovelace",
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+	}
+
+	calendarEvent := NewScheduleItemCalendarEvent(item, event)
+	assert.Equal(t, item.ID.Hex()+"@applicantatlas", calendarEvent.UID)
+	assert.Equal(t, "Hackathon: Keynote", calendarEvent.Summary)
+	assert.Equal(t, "Speaker: Ada Lovelace\n\nOpening talk", calendarEvent.Description)
+	assert.Equal(t, "Room 101, Main Hall", calendarEvent.Location)
+	assert.True(t, calendarEvent.Cancelled, "sessions are cancelled with their event")
+
+	item.Description = ""
+	event.Metadata.Venue = nil
+	calendarEvent = NewScheduleItemCalendarEvent(item, event)
+	assert.Equal(t, "Speaker: Ada Lovelace", calendarEvent.Description)
+	assert.Equal(t, "Room 101", calendarEvent.Location)
+}
+
+func TestVenueLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		venue    models.EventVenue
+		expected string
+	}{
+		{"Full address", models.EventVenue{Type: models.EventLocationInPerson, Name: "Main Hall", Address: &models.Address{StreetAddress: "1 Campus Rd", City: "Springfield", Region: "IL", ZipCode: "62701", Country: "USA"}}, "Main Hall, 1 Campus Rd, Springfield, IL, 62701, USA"},
+		{"Missing parts are skipped", models.EventVenue{Type: models.EventLocationInPerson, Address: &models.Address{City: "Springfield", Country: "USA"}}, "Springfield, USA"},
+		{"Online", models.EventVenue{Type: models.EventLocationOnline, MeetingURL: "https://meet.example.com/secret"}, "Online"},
+		{"Online with a name", models.EventVenue{Type: models.EventLocationOnline, Name: "Discord"}, "Discord"},
+		{"In person without details", models.EventVenue{Type: models.EventLocationInPerson}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, VenueLocation(&tt.venue))
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"shared/config"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+/*
+JWT signing keys
+
+Access tokens are signed with RS256 or EdDSA keys from JWT_PRIVATE_KEYS (or JWT_PRIVATE_KEYS_FILE), a list of PEM private keys.
+The first key signs new tokens, every key in the list verifies tokens and is published at /.well-known/jwks.json.
+Each key's kid is its RFC 7638 thumbprint so it doesn't need to be configured.
+
+To rotate keys:
+ 1. Add the new key to the end of the list and deploy, other services pick it up from the JWKS (cached for up to 5 minutes).
+ 2. Move the new key to the start of the list and deploy, new tokens are signed with it.
+ 3. Once ACCESS_TOKEN_TTL has passed remove the old key and deploy, every token it signed has expired.
+*/
+
+// jwtSigningMethods are the algorithms we sign and accept access tokens with
+var jwtSigningMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+
+// ErrNoSigningKeys is returned when JWTs can't be signed because no keys are configured
+var ErrNoSigningKeys = errors.New("no JWT signing keys are configured")
+
+// jwtSigningKey is a private key that can sign access tokens
+type jwtSigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	Signer crypto.Signer
+}
+
+// jwtKeyRing holds every key access tokens can be verified with, active is the one new tokens are signed with
+type jwtKeyRing struct {
+	active *jwtSigningKey
+	keys   map[string]*jwtSigningKey
+	order  []string
+}
+
+var (
+	keyRing     *jwtKeyRing
+	keyRingErr  error
+	keyRingOnce sync.Once
+)
+
+// getKeyRing loads the signing keys the first time they're needed so services that never touch JWTs don't need them configured
+func getKeyRing() (*jwtKeyRing, error) {
+	keyRingOnce.Do(func() {
+		keyRing, keyRingErr = loadKeyRing()
+	})
+	return keyRing, keyRingErr
+}
+
+func loadKeyRing() (*jwtKeyRing, error) {
+	apiConfig, err := config.GetAPIConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	keysPEM := apiConfig.JWT_PRIVATE_KEYS
+	if keysPEM == "" {
+		keysPEM = apiConfig.JWT_PRIVATE_KEYS_FILE
+	}
+
+	if keysPEM != "" {
+		signers, err := parsePrivateKeysPEM([]byte(keysPEM))
+		if err != nil {
+			return nil, err
+		}
+		return newKeyRing(signers)
+	}
+
+	// Older deployments only have a shared secret, derive a stable Ed25519 key from it so every instance still agrees
+	if apiConfig.JWT_SECRET_TOKEN != "" && apiConfig.JWT_SECRET_TOKEN != "secret_please_change" {
+		log.Println("[WARNING] JWT_SECRET_TOKEN is deprecated, deriving a JWT signing key from it. Set JWT_PRIVATE_KEYS to use your own keys.")
+		seed := sha256.Sum256([]byte(apiConfig.JWT_SECRET_TOKEN))
+		return newKeyRing([]crypto.Signer{ed25519.NewKeyFromSeed(seed[:])})
+	}
+
+	if RunningInAWSLambda() {
+		return nil, ErrNoSigningKeys
+	}
+
+	log.Println("[WARNING] JWT_PRIVATE_KEYS is not set. Generating a random signing key, this will cause all existing JWT tokens to be invalid and will not work on lambda or multi-instance deployments.")
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyRing([]crypto.Signer{privateKey})
+}
+
+func newKeyRing(signers []crypto.Signer) (*jwtKeyRing, error) {
+	if len(signers) == 0 {
+		return nil, ErrNoSigningKeys
+	}
+
+	ring := &jwtKeyRing{keys: make(map[string]*jwtSigningKey)}
+	for _, signer := range signers {
+		var method jwt.SigningMethod
+		switch key := signer.Public().(type) {
+		case *rsa.PublicKey:
+			if key.N.BitLen() < 2048 {
+				return nil, errors.New("RSA JWT signing keys must be at least 2048 bits")
+			}
+			method = jwt.SigningMethodRS256
+		case ed25519.PublicKey:
+			method = jwt.SigningMethodEdDSA
+		default:
+			return nil, fmt.Errorf("unsupported JWT signing key type %T, use RSA or Ed25519", key)
+		}
+
+		kid, err := jwkThumbprint(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := ring.keys[kid]; exists {
+			continue
+		}
+
+		key := &jwtSigningKey{ID: kid, Method: method, Signer: signer}
+		ring.keys[kid] = key
+		ring.order = append(ring.order, kid)
+		if ring.active == nil {
+			ring.active = key
+		}
+	}
+
+	return ring, nil
+}
+
+// parsePrivateKeysPEM parses every PKCS#8 or PKCS#1 private key in the PEM data, in order
+func parsePrivateKeysPEM(data []byte) ([]crypto.Signer, error) {
+	var signers []crypto.Signer
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		default:
+			return nil, fmt.Errorf("unsupported PEM block %q in JWT signing keys", block.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT signing key: %w", err)
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported JWT signing key type %T", key)
+		}
+		signers = append(signers, signer)
+	}
+
+	if len(signers) == 0 {
+		return nil, errors.New("no PEM private keys found in JWT signing keys")
+	}
+	return signers, nil
+}
+
+// publicJWK converts a signing key into the JWK we publish
+func (k *jwtSigningKey) publicJWK() JSONWebKey {
+	jwk := JSONWebKey{KeyID: k.ID, Use: "sig", Algorithm: k.Method.Alg()}
+	switch key := k.Signer.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(key)
+	}
+	return jwk
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint of a public key, the members must be in lexicographic order
+func jwkThumbprint(publicKey crypto.PublicKey) (string, error) {
+	var members interface{}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		}
+	case ed25519.PublicKey:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{
+			Crv: "Ed25519",
+			Kty: "OKP",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+	default:
+		return "", fmt.Errorf("unsupported key type %T", publicKey)
+	}
+
+	encoded, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// PublicJWKS returns the public keys access tokens can be verified with, for serving at /.well-known/jwks.json
+func PublicJWKS() (JSONWebKeySet, error) {
+	ring, err := getKeyRing()
+	if err != nil {
+		return JSONWebKeySet{}, err
+	}
+
+	keySet := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ring.order))}
+	for _, kid := range ring.order {
+		keySet.Keys = append(keySet.Keys, ring.keys[kid].publicJWK())
+	}
+	return keySet, nil
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"shared/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func encodePrivateKeyPEM(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// withKeyRing swaps the package key ring for the duration of a test
+func withKeyRing(t *testing.T, ring *jwtKeyRing) {
+	getKeyRing() // Make sure the once has run so it doesn't overwrite our ring later
+	previous := keyRing
+	keyRing = ring
+	t.Cleanup(func() { keyRing = previous })
+}
+
+func TestParsePrivateKeysPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	data := append(encodePrivateKeyPEM(t, edKey), pkcs1...)
+
+	signers, err := parsePrivateKeysPEM(data)
+	assert.Nil(t, err)
+	assert.Len(t, signers, 2)
+	assert.IsType(t, ed25519.PrivateKey{}, signers[0])
+	assert.IsType(t, &rsa.PrivateKey{}, signers[1])
+
+	_, err = parsePrivateKeysPEM([]byte("not a key"))
+	assert.NotNil(t, err)
+}
+
+func TestNewKeyRingRejectsWeakRSA(t *testing.T) {
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.Nil(t, err)
+
+	_, err = newKeyRing([]crypto.Signer{weakKey})
+	assert.NotNil(t, err)
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	user := models.User{ID: primitive.NewObjectID(), Email: "test@example.com"}
+	sessionID := primitive.NewObjectID()
+
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	_, newKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.Nil(t, err)
+
+	// Step 1: the old key is still signing, the new key has been added
+	oldRing, err := newKeyRing([]crypto.Signer{oldKey, newKey})
+	assert.Nil(t, err)
+	withKeyRing(t, oldRing)
+
+	oldToken, err := GenerateJWT(&user, sessionID)
+	assert.Nil(t, err)
+
+	keySet, err := PublicJWKS()
+	assert.Nil(t, err)
+	assert.Len(t, keySet.Keys, 2)
+	assert.Equal(t, "RS256", keySet.Keys[0].Algorithm)
+	assert.Equal(t, "EdDSA", keySet.Keys[1].Algorithm)
+
+	// Published keys must round trip to the public keys we sign with
+	for _, jwk := range keySet.Keys {
+		publicKey, err := jwk.PublicKey()
+		assert.Nil(t, err)
+		thumbprint, err := jwkThumbprint(publicKey)
+		assert.Nil(t, err)
+		assert.Equal(t, jwk.KeyID, thumbprint)
+	}
+
+	// Step 2: the new key signs, tokens from the old key still verify
+	rotatedRing, err := newKeyRing([]crypto.Signer{newKey, oldKey})
+	assert.Nil(t, err)
+	withKeyRing(t, rotatedRing)
+
+	newToken, err := GenerateJWT(&user, sessionID)
+	assert.Nil(t, err)
+
+	_, _, err = VerifyJWTWithSession(oldToken)
+	assert.Nil(t, err)
+	_, _, err = VerifyJWTWithSession(newToken)
+	assert.Nil(t, err)
+
+	// Step 3: the old key is removed, its tokens stop verifying
+	finalRing, err := newKeyRing([]crypto.Signer{newKey})
+	assert.Nil(t, err)
+	withKeyRing(t, finalRing)
+
+	_, _, err = VerifyJWTWithSession(oldToken)
+	assert.NotNil(t, err)
+	_, _, err = VerifyJWTWithSession(newToken)
+	assert.Nil(t, err)
+}
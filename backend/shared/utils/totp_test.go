@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA1), truncated to 6 digits
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateTOTPCode(t *testing.T) {
+	cases := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range cases {
+		code, err := GenerateTOTPCode(rfcSecret, time.Unix(tc.unix, 0))
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expected, code)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := GenerateTOTPCode(rfcSecret, now)
+	previousCode, _ := GenerateTOTPCode(rfcSecret, now.Add(-30*time.Second))
+	oldCode, _ := GenerateTOTPCode(rfcSecret, now.Add(-5*time.Minute))
+
+	step, ok := ValidateTOTPCode(rfcSecret, code, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	_, ok = ValidateTOTPCode(rfcSecret, "050 471", now, 0)
+	assert.True(t, ok, "spaces should be ignored")
+
+	_, ok = ValidateTOTPCode(rfcSecret, previousCode, now, 0)
+	assert.True(t, ok, "codes from the previous step should be accepted for clock drift")
+
+	_, ok = ValidateTOTPCode(rfcSecret, oldCode, now, 0)
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTPCode(rfcSecret, code, now, step)
+	assert.False(t, ok, "a code can't be reused once its step has been used")
+
+	_, ok = ValidateTOTPCode(rfcSecret, "12345", now, 0)
+	assert.False(t, ok)
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.Nil(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := GenerateTOTPCode(secret, time.Now())
+	assert.Nil(t, err)
+	assert.Len(t, code, 6)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("ApplicantAtlas", "test@example.com", "SECRET")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/ApplicantAtlas:test@example.com?"))
+	assert.Contains(t, uri, "secret=SECRET")
+	assert.Contains(t, uri, "issuer=ApplicantAtlas")
+}
@@ -1,9 +1,10 @@
 // AuthService.ts
-import { AxiosResponse } from 'axios';
+import axios, { AxiosResponse } from 'axios';
 import { jwtDecode } from 'jwt-decode';
 import posthog from 'posthog-js';
 
 import { User } from '@/types/models/User';
+import { API_URL } from '@/config/constants';
 
 import api from './AxiosInterceptor';
 import { SendEvent } from './AnalyticsService';
@@ -11,9 +12,13 @@ import { SendEvent } from './AnalyticsService';
 const register = async (u: User): Promise<User> => {
   return new Promise(async (resolve, reject) => {
     try {
-      const response = await api.post<{ token: string }>(`/auth/register`, u);
+      const response = await api.post<{ token: string; refreshToken: string }>(
+        `/auth/register`,
+        u,
+      );
       const tok = response.data.token;
       localStorage.setItem('token', tok);
+      localStorage.setItem('refreshToken', response.data.refreshToken);
 
       const decoded: User = jwtDecode<User>(tok);
       localStorage.setItem('user', JSON.stringify(decoded));
@@ -37,9 +42,13 @@ const register = async (u: User): Promise<User> => {
 const login = (u: User): Promise<User> => {
   return new Promise(async (resolve, reject) => {
     try {
-      const response = await api.post<{ token: string }>(`/auth/login`, u);
+      const response = await api.post<{ token: string; refreshToken: string }>(
+        `/auth/login`,
+        u,
+      );
       const tok = response.data.token;
       localStorage.setItem('token', tok);
+      localStorage.setItem('refreshToken', response.data.refreshToken);
 
       const decoded: User = jwtDecode<User>(tok);
       localStorage.setItem('user', JSON.stringify(decoded));
@@ -55,10 +64,22 @@ const login = (u: User): Promise<User> => {
   });
 };
 
-const logout = (): void => {
+const logout = (allSessions: boolean = false): void => {
+  const tok = localStorage.getItem('token');
+
   localStorage.removeItem('user');
   localStorage.removeItem('token');
+  localStorage.removeItem('refreshToken');
   posthog.reset();
+
+  if (tok) {
+    // Revoke the session server side, this skips the api interceptors since the token may already be expired
+    axios
+      .post(`${API_URL}/auth/logout${allSessions ? '?all=true' : ''}`, null, {
+        headers: { Authorization: `Bearer ${tok}` },
+      })
+      .catch(() => undefined);
+  }
 };
 
 // Delete self
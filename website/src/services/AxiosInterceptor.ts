@@ -24,10 +24,56 @@ api.interceptors.request.use(
   },
 );
 
+// Exchanges the stored refresh token for a new access token, shared between concurrent requests
+let refreshPromise: Promise<string | null> | null = null;
+const refreshAccessToken = (): Promise<string | null> => {
+  const refreshToken = localStorage.getItem('refreshToken');
+  if (!refreshToken) {
+    return Promise.resolve(null);
+  }
+
+  if (!refreshPromise) {
+    refreshPromise = axios
+      .post<{ token: string; refreshToken: string }>(
+        `${API_URL}/auth/refresh`,
+        { refreshToken },
+      )
+      .then((response) => {
+        localStorage.setItem('token', response.data.token);
+        localStorage.setItem('refreshToken', response.data.refreshToken);
+        return response.data.token;
+      })
+      .catch(() => null)
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+
+  return refreshPromise;
+};
+
 // Response interceptor for API calls
 api.interceptors.response.use(
   (response) => response,
-  (error) => {
+  async (error) => {
+    // Our access tokens are short lived, so try to refresh it once before logging the user out
+    const originalRequest = error.config;
+    if (
+      error.response &&
+      error.response.status == 401 &&
+      error.response.data &&
+      error.response.data.error == 'Invalid or expired token' &&
+      originalRequest &&
+      !originalRequest._retried
+    ) {
+      const newToken = await refreshAccessToken();
+      if (newToken) {
+        originalRequest._retried = true;
+        originalRequest.headers['Authorization'] = `Bearer ${newToken}`;
+        return api(originalRequest);
+      }
+    }
+
     if (!error.response) {
       eventEmitter.emit(
         'apiError',